@@ -0,0 +1,143 @@
+package application
+
+import (
+	"fmt"
+	"lab7/domain"
+	"slices"
+)
+
+// Role - роль пользователя
+type Role string
+
+const (
+	// RoleCustomer - клиент магазина; работает только со своими заказами
+	RoleCustomer Role = "customer"
+	// RoleSupport - служба поддержки; просматривает и правит любые заказы
+	RoleSupport Role = "support"
+	// RoleFinance - финансовый отдел; просматривает заказы и выполняет возвраты
+	RoleFinance Role = "finance"
+	// RoleAdmin - администратор; разрешено всё
+	RoleAdmin Role = "admin"
+)
+
+// Principal - пользователь или сервис, от имени которого выполняется запрос
+type Principal struct {
+	// ID - идентификатор; для клиента совпадает с CustomerID его заказов
+	ID    string
+	Roles []Role
+}
+
+// HasRole проверяет, есть ли у пользователя роль role
+func (p Principal) HasRole(role Role) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Permission - действие над заказами
+type Permission string
+
+const (
+	// PermissionReadOrder - просмотр заказа
+	PermissionReadOrder Permission = "order.read"
+	// PermissionListOrders - поиск заказов
+	PermissionListOrders Permission = "order.list"
+	// PermissionWriteOrder - создание и изменение заказа
+	PermissionWriteOrder Permission = "order.write"
+	// PermissionPayOrder - оплата заказа
+	PermissionPayOrder Permission = "order.pay"
+	// PermissionRefundOrder - возврат средств за заказ
+	PermissionRefundOrder Permission = "order.refund"
+)
+
+// Scope - на какие заказы распространяется разрешение
+type Scope int
+
+const (
+	// ScopeOwn - только заказы, принадлежащие пользователю
+	ScopeOwn Scope = iota + 1
+	// ScopeAny - любые заказы
+	ScopeAny
+)
+
+// AccessDeniedError - отказ в доступе. Оборачивает ErrForbidden.
+type AccessDeniedError struct {
+	PrincipalID string
+	Permission  Permission
+	OrderID     string // пусто для операций без конкретного заказа
+	Reason      string
+}
+
+// Error возвращает текст отказа
+func (e *AccessDeniedError) Error() string {
+	target := "orders"
+	if e.OrderID != "" {
+		target = "order " + e.OrderID
+	}
+	return fmt.Sprintf("%s: %s may not %s %s: %s", ErrForbidden, e.PrincipalID, e.Permission, target, e.Reason)
+}
+
+// Unwrap позволяет проверять отказ через errors.Is(err, ErrForbidden)
+func (e *AccessDeniedError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Policy - политика доступа: разрешения ролей и их область
+type Policy struct {
+	grants map[Role]map[Permission]Scope
+}
+
+// NewPolicy создаёт пустую политику, в которой всё запрещено
+func NewPolicy() *Policy {
+	return &Policy{grants: make(map[Role]map[Permission]Scope)}
+}
+
+// DefaultPolicy возвращает политику по умолчанию: клиент просматривает,
+// изменяет и оплачивает свои заказы; поддержка просматривает и изменяет
+// любые заказы; финансы просматривают заказы и выполняют возвраты;
+// администратору разрешено всё
+func DefaultPolicy() *Policy {
+	return NewPolicy().
+		Grant(RoleCustomer, ScopeOwn, PermissionReadOrder, PermissionListOrders, PermissionWriteOrder, PermissionPayOrder).
+		Grant(RoleSupport, ScopeAny, PermissionReadOrder, PermissionListOrders, PermissionWriteOrder).
+		Grant(RoleFinance, ScopeAny, PermissionReadOrder, PermissionListOrders, PermissionRefundOrder).
+		Grant(RoleAdmin, ScopeAny, PermissionReadOrder, PermissionListOrders, PermissionWriteOrder, PermissionPayOrder, PermissionRefundOrder)
+}
+
+// Grant разрешает роли действия permissions в области scope
+func (p *Policy) Grant(role Role, scope Scope, permissions ...Permission) *Policy {
+	if p.grants[role] == nil {
+		p.grants[role] = make(map[Permission]Scope)
+	}
+	for _, permission := range permissions {
+		p.grants[role][permission] = scope
+	}
+	return p
+}
+
+// Scope возвращает наибольшую область действия permission по всем ролям
+// пользователя; 0 - действие запрещено
+func (p *Policy) Scope(principal Principal, permission Permission) Scope {
+	var scope Scope
+	for _, role := range principal.Roles {
+		scope = max(scope, p.grants[role][permission])
+	}
+	return scope
+}
+
+// Authorize проверяет, может ли пользователь выполнить действие над
+// заказом order. Для разрешений ScopeOwn заказ должен принадлежать
+// пользователю.
+func (p *Policy) Authorize(principal Principal, permission Permission, order *domain.Order) error {
+	denied := &AccessDeniedError{PrincipalID: principal.ID, Permission: permission, OrderID: order.ID()}
+	switch p.Scope(principal, permission) {
+	case ScopeAny:
+		return nil
+	case ScopeOwn:
+		if principal.ID != "" && order.CustomerID() == principal.ID {
+			return nil
+		}
+		denied.Reason = "order belongs to another customer"
+	default:
+		denied.Reason = "no role grants this permission"
+	}
+	return denied
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"lab7/domain"
+)
+
+// AddOrderLineCommand - данные для добавления строки в заказ
+type AddOrderLineCommand struct {
+	OrderID   string
+	ProductID string
+	// UnitPrice и Currency задают цену, только если use-case работает без
+	// каталога; при подключённом каталоге цена берётся из него
+	UnitPrice int64 // цена за единицу в минимальных единицах валюты
+	Currency  string
+	Quantity  int
+}
+
+// AddOrderLineUseCase - use-case добавления строки в заказ
+type AddOrderLineUseCase struct {
+	orderRepo OrderRepository
+	catalog   ProductCatalog
+}
+
+// AddOrderLineOption - необязательная зависимость use-case добавления строки
+type AddOrderLineOption func(*AddOrderLineUseCase)
+
+// WithCatalog включает определение цены и названия строки по каталогу
+func WithCatalog(catalog ProductCatalog) AddOrderLineOption {
+	return func(uc *AddOrderLineUseCase) {
+		uc.catalog = catalog
+	}
+}
+
+// NewAddOrderLineUseCase создаёт новый use-case
+func NewAddOrderLineUseCase(orderRepo OrderRepository, opts ...AddOrderLineOption) *AddOrderLineUseCase {
+	uc := &AddOrderLineUseCase{orderRepo: orderRepo}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute добавляет строку в заказ и возвращает обновлённый заказ
+func (uc *AddOrderLineUseCase) Execute(ctx context.Context, cmd AddOrderLineCommand) (OrderView, error) {
+	order, err := uc.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return OrderView{}, err
+	}
+
+	line, err := uc.newLine(ctx, cmd)
+	if err != nil {
+		return OrderView{}, err
+	}
+
+	if err := order.AddLine(line); err != nil {
+		return OrderView{}, err
+	}
+
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return OrderView{}, err
+	}
+
+	return NewOrderView(order), nil
+}
+
+// newLine создаёт строку заказа по каталогу или по цене из команды
+func (uc *AddOrderLineUseCase) newLine(ctx context.Context, cmd AddOrderLineCommand) (domain.OrderLine, error) {
+	if uc.catalog != nil {
+		if cmd.ProductID == "" {
+			return domain.OrderLine{}, domain.ErrEmptyProductID
+		}
+		product, err := uc.catalog.GetByID(ctx, cmd.ProductID)
+		if err != nil {
+			return domain.OrderLine{}, err
+		}
+		return domain.NewOrderLineFromProduct(product, cmd.Quantity)
+	}
+
+	price, err := domain.NewMoney(cmd.UnitPrice, cmd.Currency)
+	if err != nil {
+		return domain.OrderLine{}, err
+	}
+	return domain.NewOrderLine(cmd.ProductID, price, cmd.Quantity)
+}
@@ -0,0 +1,247 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// BillingOutcome - исход списания по подписке
+type BillingOutcome string
+
+const (
+	// BillingPaid - период оплачен
+	BillingPaid BillingOutcome = "paid"
+	// BillingRetryScheduled - списание не прошло, назначен повтор
+	BillingRetryScheduled BillingOutcome = "retry_scheduled"
+	// BillingSuspended - повторы исчерпаны, подписка приостановлена
+	BillingSuspended BillingOutcome = "suspended"
+	// BillingDeferred - шлюз временно недоступен; попытка не засчитывается
+	// и повторяется при следующем проходе
+	BillingDeferred BillingOutcome = "deferred"
+	// BillingUnsettled - по заказу периода уже есть списание шлюза, не
+	// отражённое в заказе; повторно не списывается до сверки
+	BillingUnsettled BillingOutcome = "unsettled"
+)
+
+// DefaultMaxDeferrals - число отложенных подряд попыток, после которого
+// сбой шлюза засчитывается как неудачная попытка
+const DefaultMaxDeferrals = 3
+
+// SubscriptionBilling - результат списания по одной подписке
+type SubscriptionBilling struct {
+	SubscriptionID string
+	OrderID        string
+	Outcome        BillingOutcome
+	Err            error // причина неудачи; nil для BillingPaid
+}
+
+// BillSubscriptionsResult - результат одного прохода по подпискам
+type BillSubscriptionsResult struct {
+	Billings []SubscriptionBilling
+}
+
+// Count возвращает число подписок с исходом outcome
+func (r BillSubscriptionsResult) Count(outcome BillingOutcome) int {
+	n := 0
+	for _, b := range r.Billings {
+		if b.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// BillSubscriptionsUseCase - use-case списаний по подпискам. За каждый
+// период подписки выставляется заказ, который оплачивается через
+// PayOrderExecutor.
+type BillSubscriptionsUseCase struct {
+	subscriptions SubscriptionRepository
+	orderRepo     OrderRepository
+	payOrder      PayOrderExecutor
+	transactions  TransactionLog
+	schedule      domain.RetrySchedule
+	maxDeferrals  int
+	now           func() time.Time
+}
+
+// BillSubscriptionsOption - необязательная настройка use-case
+type BillSubscriptionsOption func(*BillSubscriptionsUseCase)
+
+// WithRetrySchedule задаёт задержки повторов после неудачного списания;
+// по умолчанию domain.DefaultRetrySchedule
+func WithRetrySchedule(schedule domain.RetrySchedule) BillSubscriptionsOption {
+	return func(uc *BillSubscriptionsUseCase) {
+		uc.schedule = schedule
+	}
+}
+
+// WithMaxDeferrals задаёт, сколько раз подряд попытку можно отложить из-за
+// временного сбоя шлюза; следующий сбой засчитывается как неудачная
+// попытка. По умолчанию DefaultMaxDeferrals.
+func WithMaxDeferrals(n int) BillSubscriptionsOption {
+	return func(uc *BillSubscriptionsUseCase) {
+		uc.maxDeferrals = max(n, 0)
+	}
+}
+
+// BillingTransactions включает проверку выписки шлюза перед списанием:
+// заказ периода, по которому уже есть списание (например, оплата прошла,
+// а заказ не сохранён), повторно не оплачивается
+func BillingTransactions(transactions TransactionLog) BillSubscriptionsOption {
+	return func(uc *BillSubscriptionsUseCase) {
+		uc.transactions = transactions
+	}
+}
+
+// BillingClock задаёт источник времени для расписания списаний
+func BillingClock(now func() time.Time) BillSubscriptionsOption {
+	return func(uc *BillSubscriptionsUseCase) {
+		uc.now = now
+	}
+}
+
+// NewBillSubscriptionsUseCase создаёт новый use-case
+func NewBillSubscriptionsUseCase(subscriptions SubscriptionRepository, orderRepo OrderRepository, payOrder PayOrderExecutor, opts ...BillSubscriptionsOption) *BillSubscriptionsUseCase {
+	uc := &BillSubscriptionsUseCase{
+		subscriptions: subscriptions,
+		orderRepo:     orderRepo,
+		payOrder:      payOrder,
+		schedule:      domain.DefaultRetrySchedule,
+		maxDeferrals:  DefaultMaxDeferrals,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute списывает оплату по всем подпискам, срок которых наступил.
+// Ошибки хранилища по одной подписке не останавливают обработку
+// остальных и возвращаются вместе; отказы в оплате возвращаются в
+// результате, а не ошибкой.
+func (uc *BillSubscriptionsUseCase) Execute(ctx context.Context) (BillSubscriptionsResult, error) {
+	now := uc.now()
+	due, err := uc.subscriptions.FindDue(ctx, now)
+	if err != nil {
+		return BillSubscriptionsResult{}, err
+	}
+
+	var result BillSubscriptionsResult
+	var errs []error
+	for _, subscription := range due {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		billing, err := uc.bill(ctx, subscription, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subscription %s: %w", subscription.ID(), err))
+			continue
+		}
+		result.Billings = append(result.Billings, billing)
+	}
+	return result, errors.Join(errs...)
+}
+
+// bill выставляет и оплачивает заказ за текущий период подписки
+func (uc *BillSubscriptionsUseCase) bill(ctx context.Context, subscription *domain.Subscription, now time.Time) (SubscriptionBilling, error) {
+	billing := SubscriptionBilling{SubscriptionID: subscription.ID(), OrderID: subscription.CycleOrderID()}
+
+	order, err := uc.cycleOrder(ctx, subscription)
+	if err != nil {
+		return billing, err
+	}
+
+	// Заказ мог быть оплачен в прошлом проходе, который не успел сохранить
+	// подписку; повторно он не оплачивается
+	if !order.IsPaid() {
+		// Списание без сохранённой оплаты сначала разбирает сверка платежей;
+		// подписка не сохраняется, чтобы период остался тем же
+		if err := uc.checkSettled(ctx, order); errors.Is(err, ErrUnsettledCharge) {
+			billing.Outcome, billing.Err = BillingUnsettled, err
+			return billing, nil
+		} else if err != nil {
+			return billing, err
+		}
+		_, err = uc.payOrder.Execute(ctx, billing.OrderID)
+	}
+	switch {
+	case err == nil:
+		if err := subscription.RecordPayment(); err != nil {
+			return billing, err
+		}
+		billing.Outcome = BillingPaid
+	case errors.Is(err, ErrGatewayUnavailable) && subscription.Deferrals() < uc.maxDeferrals:
+		if err := subscription.RecordDeferral(); err != nil {
+			return billing, err
+		}
+		billing.Outcome, billing.Err = BillingDeferred, err
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return billing, err
+	default:
+		if err := subscription.RecordFailure(now, uc.schedule); err != nil {
+			return billing, err
+		}
+		billing.Outcome, billing.Err = BillingRetryScheduled, err
+		if subscription.Status() == domain.SubscriptionStatusSuspended {
+			billing.Outcome = BillingSuspended
+		}
+	}
+
+	if err := uc.subscriptions.Save(ctx, subscription); err != nil {
+		return billing, err
+	}
+	return billing, nil
+}
+
+// checkSettled проверяет по выписке шлюза, что за неоплаченный заказ
+// периода ничего не списано; иначе возвращает ErrUnsettledCharge
+func (uc *BillSubscriptionsUseCase) checkSettled(ctx context.Context, order *domain.Order) error {
+	if uc.transactions == nil {
+		return nil
+	}
+	transactions, err := uc.transactions.Transactions(ctx)
+	if err != nil {
+		return err
+	}
+	var txs []Transaction
+	for _, tx := range transactions {
+		if tx.OrderID == order.ID() {
+			txs = append(txs, tx)
+		}
+	}
+	total, err := order.Total()
+	if err != nil {
+		return err
+	}
+	if charged := netCharged(txs, total.Currency()); charged > 0 {
+		return fmt.Errorf("%w: order %s: %d %s charged", ErrUnsettledCharge, order.ID(), charged, total.Currency())
+	}
+	return nil
+}
+
+// cycleOrder загружает заказ за текущий период или выставляет новый
+func (uc *BillSubscriptionsUseCase) cycleOrder(ctx context.Context, subscription *domain.Subscription) (*domain.Order, error) {
+	orderID := subscription.CycleOrderID()
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err == nil || !errors.Is(err, ErrOrderNotFound) {
+		return order, err
+	}
+
+	line, err := subscription.Plan().OrderLine()
+	if err != nil {
+		return nil, err
+	}
+	order = domain.NewOrderForCustomer(orderID, subscription.CustomerID())
+	if err := order.AddLine(line); err != nil {
+		return nil, err
+	}
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
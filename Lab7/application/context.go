@@ -0,0 +1,74 @@
+package application
+
+import "context"
+
+// requestIDKey - ключ идентификатора запроса в контексте
+type requestIDKey struct{}
+
+// WithRequestID возвращает контекст с идентификатором запроса
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext возвращает идентификатор запроса или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// tenantKey - ключ магазина в контексте
+type tenantKey struct{}
+
+// WithTenant возвращает контекст с идентификатором магазина, в рамках
+// которого выполняется запрос
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext возвращает идентификатор магазина. Если он не указан,
+// возвращает ErrTenantRequired.
+func TenantFromContext(ctx context.Context) (string, error) {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	if tenantID == "" {
+		return "", ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
+// actorKey - ключ автора изменений в контексте
+type actorKey struct{}
+
+// WithActor возвращает контекст с автором изменений: пользователем или
+// сервисом, от имени которого выполняется запрос
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает автора изменений. Если автор не указан,
+// им считается пользователь из контекста; без пользователя - пустая строка.
+func ActorFromContext(ctx context.Context) string {
+	if actor, _ := ctx.Value(actorKey{}).(string); actor != "" {
+		return actor
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.ID
+}
+
+// principalKey - ключ пользователя в контексте
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст с пользователем, от имени которого
+// выполняется запрос
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает пользователя из контекста. Если он не
+// указан, возвращает ErrUnauthenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, error) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	if !ok {
+		return Principal{}, ErrUnauthenticated
+	}
+	return principal, nil
+}
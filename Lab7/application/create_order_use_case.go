@@ -0,0 +1,81 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// CreateOrderCommand - параметры создания заказа
+type CreateOrderCommand struct {
+	OrderID    string
+	CustomerID string // необязателен; пусто - заказ без владельца
+}
+
+// CreateOrderUseCase - use-case создания нового заказа
+type CreateOrderUseCase struct {
+	orderRepo OrderRepository
+	customers CustomerRepository
+	payWithin time.Duration
+}
+
+// CreateOrderOption - необязательная зависимость use-case создания заказа
+type CreateOrderOption func(*CreateOrderUseCase)
+
+// RequireCustomers включает проверку, что клиент заказа существует
+func RequireCustomers(customers CustomerRepository) CreateOrderOption {
+	return func(uc *CreateOrderUseCase) {
+		uc.customers = customers
+	}
+}
+
+// PaymentDeadline задаёт срок оплаты новых заказов: заказ, не оплаченный
+// в течение d после создания, считается просроченным
+func PaymentDeadline(d time.Duration) CreateOrderOption {
+	return func(uc *CreateOrderUseCase) {
+		uc.payWithin = d
+	}
+}
+
+// NewCreateOrderUseCase создаёт новый use-case
+func NewCreateOrderUseCase(orderRepo OrderRepository, opts ...CreateOrderOption) *CreateOrderUseCase {
+	uc := &CreateOrderUseCase{orderRepo: orderRepo}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute создаёт пустой заказ с указанным идентификатором
+func (uc *CreateOrderUseCase) Execute(ctx context.Context, cmd CreateOrderCommand) (OrderView, error) {
+	if cmd.OrderID == "" {
+		return OrderView{}, errors.New("order ID cannot be empty")
+	}
+
+	// Идентификатор заказа должен быть уникальным
+	_, err := uc.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err == nil {
+		return OrderView{}, fmt.Errorf("%w: %s", ErrOrderAlreadyExists, cmd.OrderID)
+	}
+	if !errors.Is(err, ErrOrderNotFound) {
+		return OrderView{}, err
+	}
+
+	if cmd.CustomerID != "" && uc.customers != nil {
+		if _, err := uc.customers.GetByID(ctx, cmd.CustomerID); err != nil {
+			return OrderView{}, err
+		}
+	}
+
+	order := domain.NewOrderForCustomer(cmd.OrderID, cmd.CustomerID)
+	if uc.payWithin > 0 {
+		order.SetPaymentDeadline(order.CreatedAt().Add(uc.payWithin))
+	}
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return OrderView{}, err
+	}
+
+	return NewOrderView(order), nil
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"lab7/domain"
+)
+
+// Коды ошибок для логов и метрик. Код описывает тип ошибки и, в отличие
+// от текста, не содержит данных конкретного запроса.
+const (
+	ErrorCodeNone               = "none"
+	ErrorCodeNotFound           = "order_not_found"
+	ErrorCodeAlreadyExists      = "order_already_exists"
+	ErrorCodeNoCustomer         = "customer_not_found"
+	ErrorCodeCustomerBlocked    = "customer_blocked"
+	ErrorCodeSubscriptionClosed = "subscription_inactive"
+	ErrorCodeNoProduct          = "product_not_found"
+	ErrorCodeNoGiftCard         = "gift_card_not_found"
+	ErrorCodeNoGiftCards        = "gift_cards_not_accepted"
+	ErrorCodeNoSubscription     = "subscription_not_found"
+	ErrorCodeNoTenant           = "tenant_required"
+	ErrorCodeUnknownTenant      = "unknown_tenant"
+	ErrorCodeUnauthenticated    = "unauthenticated"
+	ErrorCodeForbidden          = "forbidden"
+	ErrorCodeGiftCardExpired    = "gift_card_expired"
+	ErrorCodeNoBalance          = "insufficient_balance"
+	ErrorCodeProductInactive    = "product_inactive"
+	ErrorCodeNoShipping         = "shipping_unavailable"
+	ErrorCodeNoDelivery         = "delivery_not_set"
+	ErrorCodeNotQuoted          = "shipping_not_quoted"
+	ErrorCodeSplitMismatch      = "split_mismatch"
+	ErrorCodeInstallments       = "installments_scheduled"
+	ErrorCodeNoInstallments     = "installments_not_scheduled"
+	ErrorCodeInvalidInput       = "invalid_input"
+	ErrorCodeInvalidQuery       = "invalid_query"
+	ErrorCodeCurrencyMismatch   = "currency_mismatch"
+	ErrorCodeEmptyOrder         = "empty_order"
+	ErrorCodeAlreadyPaid        = "already_paid"
+	ErrorCodeNotModifiable      = "not_modifiable"
+	ErrorCodeNotPaid            = "not_paid"
+	ErrorCodeRefunded           = "already_refunded"
+	ErrorCodeExpired            = "order_expired"
+	ErrorCodeNotOverdue         = "not_overdue"
+	ErrorCodePaymentFailed      = "payment_failed"
+	ErrorCodeRefundFailed       = "refund_failed"
+	ErrorCodeGatewayDown        = "gateway_unavailable"
+	ErrorCodeDeclined           = "payment_declined"
+	ErrorCodeUnsettled          = "unsettled_charge"
+	ErrorCodeOutOfStock         = "out_of_stock"
+	ErrorCodeNoReservation      = "reservation_not_found"
+	ErrorCodeCanceled           = "canceled"
+	ErrorCodeInternal           = "internal"
+)
+
+// errorCodes - соответствие ошибок кодам в порядке проверки
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrOrderNotFound, ErrorCodeNotFound},
+	{ErrOrderAlreadyExists, ErrorCodeAlreadyExists},
+	{ErrCustomerNotFound, ErrorCodeNoCustomer},
+	{ErrProductNotFound, ErrorCodeNoProduct},
+	{ErrGiftCardNotFound, ErrorCodeNoGiftCard},
+	{ErrGiftCardsNotAccepted, ErrorCodeNoGiftCards},
+	{ErrSubscriptionNotFound, ErrorCodeNoSubscription},
+	{ErrTenantRequired, ErrorCodeNoTenant},
+	{ErrUnknownTenant, ErrorCodeUnknownTenant},
+	{ErrUnauthenticated, ErrorCodeUnauthenticated},
+	{ErrForbidden, ErrorCodeForbidden},
+	{ErrInvalidQuery, ErrorCodeInvalidQuery},
+	// Причина отказа шлюза важнее общей ошибки списания или возврата,
+	// которой use-case оборачивают ошибки шлюза
+	{ErrGatewayUnavailable, ErrorCodeGatewayDown},
+	{ErrPaymentDeclined, ErrorCodeDeclined},
+	{ErrUnsettledCharge, ErrorCodeUnsettled},
+	{ErrPaymentFailed, ErrorCodePaymentFailed},
+	{ErrRefundFailed, ErrorCodeRefundFailed},
+	{ErrInsufficientStock, ErrorCodeOutOfStock},
+	{ErrReservationNotFound, ErrorCodeNoReservation},
+	{domain.ErrCurrencyMismatch, ErrorCodeCurrencyMismatch},
+	{domain.ErrNegativeAmount, ErrorCodeInvalidInput},
+	{domain.ErrEmptyCurrency, ErrorCodeInvalidInput},
+	{domain.ErrEmptyProductID, ErrorCodeInvalidInput},
+	{domain.ErrNonPositiveQuantity, ErrorCodeInvalidInput},
+	{domain.ErrEmptyProductName, ErrorCodeInvalidInput},
+	{domain.ErrProductInactive, ErrorCodeProductInactive},
+	{domain.ErrUnknownOrderStatus, ErrorCodeInvalidInput},
+	{domain.ErrNegativeWeight, ErrorCodeInvalidInput},
+	{domain.ErrUnknownVATRate, ErrorCodeInvalidInput},
+	{domain.ErrInvalidAddress, ErrorCodeInvalidInput},
+	{domain.ErrUnknownDeliveryMethod, ErrorCodeInvalidInput},
+	{domain.ErrShippingUnavailable, ErrorCodeNoShipping},
+	{domain.ErrInvalidPaymentMethod, ErrorCodeInvalidInput},
+	{domain.ErrUnknownPaymentMethod, ErrorCodeInvalidInput},
+	{domain.ErrSplitMismatch, ErrorCodeSplitMismatch},
+	{domain.ErrInvalidInstallmentPlan, ErrorCodeInvalidInput},
+	{domain.ErrInstallmentsScheduled, ErrorCodeInstallments},
+	{domain.ErrInstallmentsNotScheduled, ErrorCodeNoInstallments},
+	{domain.ErrEmptyGiftCardID, ErrorCodeInvalidInput},
+	{domain.ErrGiftCardExpired, ErrorCodeGiftCardExpired},
+	{domain.ErrInsufficientBalance, ErrorCodeNoBalance},
+	{domain.ErrDeliveryNotSet, ErrorCodeNoDelivery},
+	{domain.ErrShippingNotQuoted, ErrorCodeNotQuoted},
+	{domain.ErrEmptySubscriptionID, ErrorCodeInvalidInput},
+	{domain.ErrUnknownBillingInterval, ErrorCodeInvalidInput},
+	{domain.ErrUnknownSubscriptionStatus, ErrorCodeInvalidInput},
+	{domain.ErrSubscriptionCancelled, ErrorCodeSubscriptionClosed},
+	{domain.ErrSubscriptionSuspended, ErrorCodeSubscriptionClosed},
+	{domain.ErrSubscriptionNotSuspended, ErrorCodeNotModifiable},
+	{domain.ErrEmptyCustomerID, ErrorCodeInvalidInput},
+	{domain.ErrInvalidContact, ErrorCodeInvalidInput},
+	{domain.ErrUnknownCustomerStatus, ErrorCodeInvalidInput},
+	{domain.ErrCustomerBlocked, ErrorCodeCustomerBlocked},
+	{domain.ErrEmptyOrder, ErrorCodeEmptyOrder},
+	{domain.ErrOrderHasNoLines, ErrorCodeEmptyOrder},
+	{domain.ErrOrderAlreadyPaid, ErrorCodeAlreadyPaid},
+	{domain.ErrOrderNotModifiable, ErrorCodeNotModifiable},
+	{domain.ErrOrderNotPaid, ErrorCodeNotPaid},
+	{domain.ErrOrderRefunded, ErrorCodeRefunded},
+	{domain.ErrOrderExpired, ErrorCodeExpired},
+	{domain.ErrOrderNotOverdue, ErrorCodeNotOverdue},
+	{context.Canceled, ErrorCodeCanceled},
+	{context.DeadlineExceeded, ErrorCodeCanceled},
+}
+
+// ErrorCode возвращает код ошибки; для nil - ErrorCodeNone
+func ErrorCode(err error) string {
+	if err == nil {
+		return ErrorCodeNone
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ErrorCodeInternal
+}
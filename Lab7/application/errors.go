@@ -0,0 +1,44 @@
+package application
+
+import "errors"
+
+// Ошибки слоя приложения
+var (
+	// ErrOrderNotFound - заказ не найден в хранилище
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderAlreadyExists - заказ с таким идентификатором уже существует
+	ErrOrderAlreadyExists = errors.New("order already exists")
+	// ErrCustomerNotFound - клиент не найден в хранилище
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrGiftCardNotFound - подарочная карта не найдена
+	ErrGiftCardNotFound = errors.New("gift card not found")
+	// ErrGiftCardsNotAccepted - оплата подарочными картами не подключена
+	ErrGiftCardsNotAccepted = errors.New("gift cards are not accepted")
+	// ErrSubscriptionNotFound - подписка не найдена в хранилище
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrProductNotFound - товара нет в каталоге
+	ErrProductNotFound = errors.New("product not found")
+	// ErrPaymentFailed - платёжный шлюз отклонил списание
+	ErrPaymentFailed = errors.New("payment failed")
+	// ErrRefundFailed - платёжный шлюз отклонил возврат
+	ErrRefundFailed = errors.New("refund failed")
+	// ErrGatewayUnavailable - временный сбой шлюза; операцию можно повторить
+	ErrGatewayUnavailable = errors.New("payment gateway temporarily unavailable")
+	// ErrPaymentDeclined - окончательный отказ шлюза; повтор не поможет
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrUnsettledCharge - по заказу уже есть списание шлюза, не отражённое
+	// в заказе; заказ не оплачивается повторно до сверки
+	ErrUnsettledCharge = errors.New("order has an unsettled charge")
+	// ErrInsufficientStock - товара на складе меньше, чем требуется заказу
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrReservationNotFound - резерв не найден или истёк
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrTenantRequired - в контексте запроса не указан магазин
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrUnknownTenant - магазин не зарегистрирован
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrUnauthenticated - в контексте запроса не указан пользователь
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden - пользователю не разрешена операция (см. AccessDeniedError)
+	ErrForbidden = errors.New("access denied")
+)
@@ -0,0 +1,85 @@
+package application
+
+import "context"
+
+// PayOrderExecutor - интерфейс use-case оплаты заказа.
+// Его реализуют PayOrderUseCase и декораторы над ним.
+type PayOrderExecutor interface {
+	// Execute оплачивает заказ целиком способом по умолчанию; равносилен
+	// ExecuteCommand с одним OrderID
+	Execute(ctx context.Context, orderID string) (PayOrderResult, error)
+	// ExecuteCommand оплачивает заказ частями и подарочной картой
+	ExecuteCommand(ctx context.Context, cmd PayOrderCommand) (PayOrderResult, error)
+}
+
+// RefundOrderExecutor - интерфейс use-case возврата средств.
+// Его реализуют RefundOrderUseCase и декораторы над ним.
+type RefundOrderExecutor interface {
+	Execute(ctx context.Context, orderID string) (RefundOrderResult, error)
+}
+
+// CreateOrderExecutor - интерфейс use-case создания заказа
+type CreateOrderExecutor interface {
+	Execute(ctx context.Context, cmd CreateOrderCommand) (OrderView, error)
+}
+
+// AddOrderLineExecutor - интерфейс use-case добавления строки в заказ
+type AddOrderLineExecutor interface {
+	Execute(ctx context.Context, cmd AddOrderLineCommand) (OrderView, error)
+}
+
+// SetDeliveryExecutor - интерфейс use-case выбора доставки
+type SetDeliveryExecutor interface {
+	Execute(ctx context.Context, cmd SetDeliveryCommand) (OrderView, error)
+}
+
+// ScheduleInstallmentsExecutor - интерфейс use-case оформления рассрочки
+type ScheduleInstallmentsExecutor interface {
+	Execute(ctx context.Context, cmd ScheduleInstallmentsCommand) (OrderView, error)
+}
+
+// PayInstallmentExecutor - интерфейс use-case оплаты платежа рассрочки
+type PayInstallmentExecutor interface {
+	Execute(ctx context.Context, cmd PayInstallmentCommand) (PayInstallmentResult, error)
+}
+
+// GetOrderExecutor - интерфейс use-case просмотра заказа
+type GetOrderExecutor interface {
+	Execute(ctx context.Context, orderID string) (OrderView, error)
+}
+
+// ListOrdersExecutor - интерфейс use-case поиска заказов
+type ListOrdersExecutor interface {
+	Execute(ctx context.Context, req ListOrdersRequest) (ListOrdersResult, error)
+}
+
+// GetReceiptExecutor - интерфейс use-case построения чека
+type GetReceiptExecutor interface {
+	Execute(ctx context.Context, orderID string) (Receipt, error)
+}
+
+// PayOrdersBatchExecutor - интерфейс use-case пакетной оплаты
+type PayOrdersBatchExecutor interface {
+	Execute(ctx context.Context, orderIDs []string) (PayOrdersBatchResult, error)
+}
+
+// ExpireOrdersExecutor - интерфейс use-case отмены неоплаченных в срок заказов
+type ExpireOrdersExecutor interface {
+	Execute(ctx context.Context) (ExpireOrdersResult, error)
+}
+
+// ReconcilePaymentsExecutor - интерфейс use-case сверки платежей
+type ReconcilePaymentsExecutor interface {
+	Execute(ctx context.Context) (ReconcilePaymentsResult, error)
+}
+
+// BillSubscriptionsExecutor - интерфейс use-case списаний по подпискам
+type BillSubscriptionsExecutor interface {
+	Execute(ctx context.Context) (BillSubscriptionsResult, error)
+}
+
+// ReportOverdueInstallmentsExecutor - интерфейс use-case отчёта о
+// просроченных платежах рассрочки
+type ReportOverdueInstallmentsExecutor interface {
+	Execute(ctx context.Context) ([]OverdueInstallment, error)
+}
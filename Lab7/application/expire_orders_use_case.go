@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// ExpireOrdersResult - результат одного прохода по просроченным заказам
+type ExpireOrdersResult struct {
+	Expired []string // идентификаторы заказов, переведённых в EXPIRED
+}
+
+// ExpireOrdersUseCase - use-case перевода неоплаченных заказов с
+// истёкшим сроком оплаты в статус EXPIRED
+type ExpireOrdersUseCase struct {
+	orderRepo OrderRepository
+	inventory InventoryService
+	now       func() time.Time
+}
+
+// ExpireOrdersOption - необязательная зависимость use-case
+type ExpireOrdersOption func(*ExpireOrdersUseCase)
+
+// ReleaseStock включает снятие складских резервов просроченных заказов
+func ReleaseStock(inventory InventoryService) ExpireOrdersOption {
+	return func(uc *ExpireOrdersUseCase) {
+		uc.inventory = inventory
+	}
+}
+
+// ExpireClock задаёт источник времени для проверки сроков оплаты
+func ExpireClock(now func() time.Time) ExpireOrdersOption {
+	return func(uc *ExpireOrdersUseCase) {
+		uc.now = now
+	}
+}
+
+// NewExpireOrdersUseCase создаёт новый use-case
+func NewExpireOrdersUseCase(orderRepo OrderRepository, opts ...ExpireOrdersOption) *ExpireOrdersUseCase {
+	uc := &ExpireOrdersUseCase{orderRepo: orderRepo, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute находит заказы в статусе PENDING с истёкшим сроком оплаты,
+// переводит их в EXPIRED и снимает их резервы. Ошибка по одному заказу
+// не останавливает обработку остальных; все ошибки возвращаются вместе.
+func (uc *ExpireOrdersUseCase) Execute(ctx context.Context) (ExpireOrdersResult, error) {
+	now := uc.now()
+	overdue, err := uc.findOverdue(ctx, now)
+	if err != nil {
+		return ExpireOrdersResult{}, err
+	}
+
+	var result ExpireOrdersResult
+	var errs []error
+	for _, orderID := range overdue {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		// Заказ считается просроченным, даже если снять резерв не удалось
+		expired, err := uc.expire(ctx, orderID, now)
+		if expired {
+			result.Expired = append(result.Expired, orderID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", orderID, err))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// findOverdue собирает идентификаторы просроченных заказов по всем
+// страницам до того, как заказы начнут менять статус
+func (uc *ExpireOrdersUseCase) findOverdue(ctx context.Context, now time.Time) ([]string, error) {
+	query := OrderQuery{
+		Statuses:  []domain.OrderStatus{domain.OrderStatusPending},
+		CreatedTo: now,
+		SortBy:    SortByCreatedAt,
+		Limit:     MaxOrderQueryLimit,
+	}
+	var overdue []string
+	for {
+		page, err := uc.orderRepo.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range page.Orders {
+			if order.IsOverdue(now) {
+				overdue = append(overdue, order.ID())
+			}
+		}
+		if page.NextCursor == "" {
+			return overdue, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// expire атомарно переводит заказ в EXPIRED. Статус проверяется в момент
+// сохранения: если заказ успели оплатить или продлить после поиска, он
+// пропускается и его резерв не снимается.
+func (uc *ExpireOrdersUseCase) expire(ctx context.Context, orderID string, now time.Time) (bool, error) {
+	err := uc.orderRepo.Update(ctx, orderID, func(order *domain.Order) error {
+		return order.Expire(now)
+	})
+	switch {
+	case errors.Is(err, domain.ErrOrderNotOverdue),
+		errors.Is(err, domain.ErrOrderAlreadyPaid),
+		errors.Is(err, domain.ErrOrderRefunded),
+		errors.Is(err, domain.ErrOrderExpired):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	if uc.inventory != nil {
+		if err := uc.inventory.Release(ctx, orderID); err != nil {
+			return true, fmt.Errorf("release stock: %w", err)
+		}
+	}
+	return true, nil
+}
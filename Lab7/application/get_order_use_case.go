@@ -0,0 +1,22 @@
+package application
+
+import "context"
+
+// GetOrderUseCase - use-case просмотра заказа
+type GetOrderUseCase struct {
+	orderRepo OrderRepository
+}
+
+// NewGetOrderUseCase создаёт новый use-case
+func NewGetOrderUseCase(orderRepo OrderRepository) *GetOrderUseCase {
+	return &GetOrderUseCase{orderRepo: orderRepo}
+}
+
+// Execute возвращает заказ по идентификатору
+func (uc *GetOrderUseCase) Execute(ctx context.Context, orderID string) (OrderView, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return OrderView{}, err
+	}
+	return NewOrderView(order), nil
+}
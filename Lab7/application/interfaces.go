@@ -1,18 +1,130 @@
 package application
 
-import "lab7/domain"
+import (
+	"context"
+	"lab7/domain"
+	"time"
+)
 
 // OrderRepository - интерфейс для работы с хранилищем заказов
 type OrderRepository interface {
 	// GetByID загружает заказ по идентификатору
-	GetByID(orderID string) (*domain.Order, error)
+	GetByID(ctx context.Context, orderID string) (*domain.Order, error)
 
 	// Save сохраняет заказ
-	Save(order *domain.Order) error
+	Save(ctx context.Context, order *domain.Order) error
+
+	// Find возвращает страницу заказов, удовлетворяющих запросу
+	Find(ctx context.Context, query OrderQuery) (OrderPage, error)
+
+	// Update атомарно изменяет заказ: загружает его, вызывает fn и сохраняет
+	// результат, если fn не вернула ошибку. Параллельные Update одного
+	// заказа выполняются по очереди, и ни одно сохранение, сделанное между
+	// загрузкой и записью, не теряется.
+	Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error
+}
+
+// CustomerRepository - интерфейс для работы с хранилищем клиентов
+type CustomerRepository interface {
+	// GetByID загружает клиента по идентификатору
+	GetByID(ctx context.Context, customerID string) (*domain.Customer, error)
+
+	// Save сохраняет клиента
+	Save(ctx context.Context, customer *domain.Customer) error
+}
+
+// ProductCatalog - интерфейс каталога товаров, источника цен для строк заказа
+type ProductCatalog interface {
+	// GetByID возвращает товар по идентификатору
+	GetByID(ctx context.Context, productID string) (*domain.Product, error)
+}
+
+// GiftCardRepository - интерфейс для работы с хранилищем подарочных карт
+type GiftCardRepository interface {
+	// GetByID загружает карту по идентификатору
+	GetByID(ctx context.Context, cardID string) (*domain.GiftCard, error)
+
+	// Save сохраняет карту
+	Save(ctx context.Context, card *domain.GiftCard) error
+
+	// Update атомарно изменяет карту: загружает её, вызывает fn и сохраняет
+	// результат, если fn не вернула ошибку. Параллельные Update одной карты
+	// выполняются по очереди, поэтому проверки баланса внутри fn надёжны.
+	Update(ctx context.Context, cardID string, fn func(card *domain.GiftCard) error) error
+}
+
+// SubscriptionRepository - интерфейс для работы с хранилищем подписок
+type SubscriptionRepository interface {
+	// GetByID загружает подписку по идентификатору
+	GetByID(ctx context.Context, subscriptionID string) (*domain.Subscription, error)
+
+	// Save сохраняет подписку
+	Save(ctx context.Context, subscription *domain.Subscription) error
+
+	// FindDue возвращает подписки, по которым к моменту now пора списывать
+	// оплату (Subscription.IsDue), в порядке идентификаторов
+	FindDue(ctx context.Context, now time.Time) ([]*domain.Subscription, error)
 }
 
-// PaymentGateway - интерфейс для проведения платежей
+// PaymentGateway - интерфейс для проведения платежей. Заказ может
+// оплачиваться несколькими вызовами Charge - по одному на каждый способ
+// оплаты; возврат выполняется тем же способом, что и списание.
 type PaymentGateway interface {
-	// Charge выполняет списание средств
-	Charge(orderID string, money domain.Money) error
+	// Charge выполняет списание средств способом method
+	Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error
+
+	// Refund возвращает ранее списанные средства способом method
+	Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error
+}
+
+// TransactionKind - вид операции платёжного шлюза
+type TransactionKind string
+
+const (
+	// TransactionCharge - списание
+	TransactionCharge TransactionKind = "charge"
+	// TransactionRefund - возврат
+	TransactionRefund TransactionKind = "refund"
+)
+
+// Transaction - операция, проведённая платёжным шлюзом
+type Transaction struct {
+	Kind    TransactionKind
+	OrderID string
+	Method  domain.PaymentMethod
+	Amount  domain.Money
+	At      time.Time // время проведения по часам шлюза
+}
+
+// TransactionLog - интерфейс выписки платёжного шлюза, по которой
+// сверяются платежи
+type TransactionLog interface {
+	// Transactions возвращает успешные списания и возвраты в порядке
+	// проведения
+	Transactions(ctx context.Context) ([]Transaction, error)
+}
+
+// StockItem - количество товара для резервирования
+type StockItem struct {
+	ProductID string
+	Quantity  int
+}
+
+// InventoryService - интерфейс складского учёта. Резерв создаётся на
+// заказ целиком и действует ограниченное время; по истечении срока
+// зарезервированный товар снова становится доступным.
+type InventoryService interface {
+	// Reserve резервирует товары заказа: либо все позиции, либо ни одной.
+	// Повторный вызов для того же заказа заменяет прежний резерв.
+	Reserve(ctx context.Context, orderID string, items []StockItem) error
+
+	// Commit списывает зарезервированные товары со склада
+	Commit(ctx context.Context, orderID string) error
+
+	// Release снимает резерв; отсутствие резерва не считается ошибкой
+	Release(ctx context.Context, orderID string) error
+
+	// Restock возвращает на склад товары, списанные Commit, если заказ
+	// после списания так и не был оплачен
+	Restock(ctx context.Context, items []StockItem) error
 }
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// ListOrdersRequest - параметры просмотра списка заказов.
+// Поля передаются примитивами, чтобы клиенты не зависели от домена.
+type ListOrdersRequest struct {
+	Statuses    []string
+	Currency    string
+	ProductID   string
+	CustomerID  string
+	MinTotal    *int64
+	MaxTotal    *int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	SortBy      string
+	Descending  bool
+	Limit       int
+	Cursor      string
+}
+
+// ListOrdersResult - страница списка заказов
+type ListOrdersResult struct {
+	Orders     []OrderView `json:"orders"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// ListOrdersUseCase - use-case получения списка заказов
+type ListOrdersUseCase struct {
+	orderRepo OrderRepository
+}
+
+// NewListOrdersUseCase создаёт новый use-case
+func NewListOrdersUseCase(orderRepo OrderRepository) *ListOrdersUseCase {
+	return &ListOrdersUseCase{orderRepo: orderRepo}
+}
+
+// Execute возвращает страницу заказов, удовлетворяющих запросу
+func (uc *ListOrdersUseCase) Execute(ctx context.Context, req ListOrdersRequest) (ListOrdersResult, error) {
+	query := OrderQuery{
+		Currency:    req.Currency,
+		ProductID:   req.ProductID,
+		CustomerID:  req.CustomerID,
+		MinTotal:    req.MinTotal,
+		MaxTotal:    req.MaxTotal,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		SortBy:      OrderSortField(req.SortBy),
+		Descending:  req.Descending,
+		Limit:       req.Limit,
+		Cursor:      req.Cursor,
+	}
+	for _, s := range req.Statuses {
+		status, err := domain.ParseOrderStatus(s)
+		if err != nil {
+			return ListOrdersResult{}, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		query.Statuses = append(query.Statuses, status)
+	}
+
+	page, err := uc.orderRepo.Find(ctx, query)
+	if err != nil {
+		return ListOrdersResult{}, err
+	}
+
+	result := ListOrdersResult{
+		Orders:     make([]OrderView, 0, len(page.Orders)),
+		NextCursor: page.NextCursor,
+	}
+	for _, order := range page.Orders {
+		result.Orders = append(result.Orders, NewOrderView(order))
+	}
+	return result, nil
+}
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// ErrInvalidQuery - некорректные параметры запроса заказов
+var ErrInvalidQuery = errors.New("invalid order query")
+
+const (
+	// DefaultOrderQueryLimit - размер страницы по умолчанию
+	DefaultOrderQueryLimit = 50
+	// MaxOrderQueryLimit - максимальный размер страницы
+	MaxOrderQueryLimit = 1000
+)
+
+// OrderSortField - поле сортировки результатов запроса
+type OrderSortField string
+
+const (
+	// SortByID - сортировка по идентификатору заказа
+	SortByID OrderSortField = "id"
+	// SortByCreatedAt - сортировка по времени создания
+	SortByCreatedAt OrderSortField = "created_at"
+	// SortByTotal - сортировка по итоговой сумме
+	SortByTotal OrderSortField = "total"
+)
+
+// OrderQuery - фильтры, сортировка и пагинация для поиска заказов.
+// Пустые поля не ограничивают выборку.
+type OrderQuery struct {
+	Statuses   []domain.OrderStatus // любой из перечисленных статусов
+	Currency   string               // валюта итоговой суммы
+	ProductID  string               // заказ содержит строку с этим продуктом
+	CustomerID string               // заказ принадлежит клиенту
+
+	MinTotal *int64 // итоговая сумма не меньше, в минимальных единицах
+	MaxTotal *int64 // итоговая сумма не больше, в минимальных единицах
+
+	CreatedFrom time.Time // создан не раньше (включительно)
+	CreatedTo   time.Time // создан раньше (не включительно)
+
+	SortBy     OrderSortField // по умолчанию SortByID
+	Descending bool
+
+	Limit  int    // размер страницы, по умолчанию DefaultOrderQueryLimit
+	Cursor string // курсор из OrderPage.NextCursor предыдущей страницы
+}
+
+// OrderPage - страница результатов запроса заказов
+type OrderPage struct {
+	Orders []*domain.Order
+	// NextCursor пуст, если следующей страницы нет
+	NextCursor string
+}
+
+// Normalize проверяет запрос и подставляет значения по умолчанию
+func (q OrderQuery) Normalize() (OrderQuery, error) {
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByID
+	case SortByID, SortByCreatedAt, SortByTotal:
+	default:
+		return q, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, q.SortBy)
+	}
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultOrderQueryLimit
+	case q.Limit < 0 || q.Limit > MaxOrderQueryLimit:
+		return q, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxOrderQueryLimit)
+	}
+
+	if q.MinTotal != nil && q.MaxTotal != nil && *q.MinTotal > *q.MaxTotal {
+		return q, fmt.Errorf("%w: min total is greater than max total", ErrInvalidQuery)
+	}
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && !q.CreatedFrom.Before(q.CreatedTo) {
+		return q, fmt.Errorf("%w: created-from must be before created-to", ErrInvalidQuery)
+	}
+	return q, nil
+}
+
+// Matches проверяет, удовлетворяет ли заказ фильтрам запроса
+func (q OrderQuery) Matches(order *domain.Order) bool {
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			if order.Status() == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if q.CustomerID != "" && q.CustomerID != order.CustomerID() {
+		return false
+	}
+
+	total, currency, hasTotal := OrderTotalKey(order)
+	if q.Currency != "" && q.Currency != currency {
+		return false
+	}
+	if q.MinTotal != nil && (!hasTotal || total < *q.MinTotal) {
+		return false
+	}
+	if q.MaxTotal != nil && (!hasTotal || total > *q.MaxTotal) {
+		return false
+	}
+
+	if q.ProductID != "" {
+		found := false
+		for _, line := range order.Lines() {
+			if line.ProductID() == q.ProductID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	createdAt := order.CreatedAt()
+	if !q.CreatedFrom.IsZero() && createdAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && !createdAt.Before(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// OrderTotalKey возвращает итоговую сумму и валюту заказа для фильтрации.
+// Для пустых заказов и заказов в разных валютах hasTotal равен false.
+func OrderTotalKey(order *domain.Order) (amount int64, currency string, hasTotal bool) {
+	total, err := order.Total()
+	if err != nil {
+		return 0, "", false
+	}
+	return total.Amount(), total.Currency(), true
+}
+
+// OrderSortKey возвращает числовой ключ сортировки заказа по полю field.
+// При равенстве ключей заказы упорядочиваются по идентификатору.
+func OrderSortKey(order *domain.Order, field OrderSortField) int64 {
+	switch field {
+	case SortByCreatedAt:
+		return order.CreatedAt().UnixNano()
+	case SortByTotal:
+		amount, _, _ := OrderTotalKey(order)
+		return amount
+	default:
+		return 0
+	}
+}
+
+// OrderCursor - позиция последнего заказа на странице
+type OrderCursor struct {
+	SortBy     OrderSortField `json:"s"`
+	Descending bool           `json:"d,omitempty"`
+	Key        int64          `json:"k,omitempty"`
+	ID         string         `json:"id"`
+}
+
+// EncodeCursor кодирует курсор в непрозрачную строку
+func EncodeCursor(c OrderCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор запроса; для пустого курсора ok равен false
+func DecodeCursor(q OrderQuery) (cursor OrderCursor, ok bool, err error) {
+	if q.Cursor == "" {
+		return OrderCursor{}, false, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil {
+		return OrderCursor{}, false, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	// Курсор действителен только для той же сортировки
+	if cursor.SortBy != q.SortBy || cursor.Descending != q.Descending {
+		return OrderCursor{}, false, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidQuery)
+	}
+	return cursor, true, nil
+}
@@ -0,0 +1,146 @@
+package application
+
+import (
+	"lab7/domain"
+	"time"
+)
+
+// OrderLineView - представление строки заказа для внешних клиентов
+type OrderLineView struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name,omitempty"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	Total     int64  `json:"total"`
+	VATRate   string `json:"vat_rate"`
+	VAT       int64  `json:"vat"` // НДС, включённый в Total
+	Currency  string `json:"currency"`
+}
+
+// DeliveryView - представление доставки заказа
+type DeliveryView struct {
+	Method     string `json:"method"`
+	Country    string `json:"country"`
+	Region     string `json:"region,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Street     string `json:"street"`
+}
+
+// PaymentView - представление части оплаты заказа
+type PaymentView struct {
+	Method   string `json:"method"` // вид оплаты и последние символы реквизита
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Refunded bool   `json:"refunded,omitempty"` // часть возвращена, возврат заказа не завершён
+}
+
+// InstallmentView - представление платежа рассрочки
+type InstallmentView struct {
+	Number   int        `json:"number"`
+	DueAt    time.Time  `json:"due_at"`
+	Amount   int64      `json:"amount"`
+	Currency string     `json:"currency"`
+	PaidAt   *time.Time `json:"paid_at,omitempty"` // момент оплаты, если платёж внесён
+}
+
+// OrderView - представление заказа для внешних клиентов.
+// Use-case возвращают его вместо доменного объекта, чтобы клиенты
+// (CLI, HTTP и т.п.) не могли обойти инварианты агрегата.
+type OrderView struct {
+	ID           string            `json:"id"`
+	CustomerID   string            `json:"customer_id,omitempty"`
+	Status       string            `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	PayBy        *time.Time        `json:"pay_by,omitempty"` // срок оплаты, если задан
+	Lines        []OrderLineView   `json:"lines"`
+	Delivery     *DeliveryView     `json:"delivery,omitempty"`
+	Payments     []PaymentView     `json:"payments,omitempty"`
+	Installments []InstallmentView `json:"installments,omitempty"` // график рассрочки, если задан
+	Subtotal     int64             `json:"subtotal"`
+	Shipping     int64             `json:"shipping"` // стоимость доставки, если она рассчитана
+	Total        int64             `json:"total"`
+	Currency     string            `json:"currency,omitempty"`
+}
+
+// TotalString возвращает итоговую сумму в формате Money.String
+func (v OrderView) TotalString() string {
+	if v.Currency == "" {
+		return "-"
+	}
+	total, _ := domain.NewMoney(v.Total, v.Currency)
+	return total.String()
+}
+
+// NewOrderView строит представление заказа
+func NewOrderView(order *domain.Order) OrderView {
+	view := OrderView{
+		ID:         order.ID(),
+		CustomerID: order.CustomerID(),
+		Status:     order.Status().String(),
+		CreatedAt:  order.CreatedAt(),
+		Lines:      make([]OrderLineView, 0, len(order.Lines())),
+	}
+
+	if payBy := order.PayBy(); !payBy.IsZero() {
+		view.PayBy = &payBy
+	}
+
+	for _, line := range order.Lines() {
+		view.Lines = append(view.Lines, OrderLineView{
+			ProductID: line.ProductID(),
+			Name:      line.Name(),
+			Quantity:  line.Quantity(),
+			UnitPrice: line.Price().Amount(),
+			Total:     line.Total().Amount(),
+			VATRate:   line.VATRate().String(),
+			VAT:       line.VAT().Amount(),
+			Currency:  line.Price().Currency(),
+		})
+	}
+
+	for _, leg := range order.Payments() {
+		view.Payments = append(view.Payments, PaymentView{
+			Method:   leg.Method.String(),
+			Amount:   leg.Amount.Amount(),
+			Currency: leg.Amount.Currency(),
+			Refunded: leg.Refunded,
+		})
+	}
+
+	for _, installment := range order.Installments() {
+		iv := InstallmentView{
+			Number:   installment.Number,
+			DueAt:    installment.DueAt,
+			Amount:   installment.Amount.Amount(),
+			Currency: installment.Amount.Currency(),
+		}
+		if installment.IsPaid() {
+			iv.PaidAt = &installment.PaidAt
+		}
+		view.Installments = append(view.Installments, iv)
+	}
+
+	if delivery := order.Delivery(); !delivery.IsZero() {
+		address := delivery.Address()
+		view.Delivery = &DeliveryView{
+			Method:     delivery.Method().String(),
+			Country:    address.Country(),
+			Region:     address.Region(),
+			City:       address.City(),
+			PostalCode: address.PostalCode(),
+			Street:     address.Street(),
+		}
+	}
+
+	// Для пустого заказа или заказа в разных валютах итог не определён
+	if total, err := order.Total(); err == nil {
+		subtotal, _ := order.Subtotal()
+		view.Subtotal = subtotal.Amount()
+		view.Shipping = order.Shipping().Amount()
+		view.Total = total.Amount()
+		view.Currency = total.Currency()
+	}
+
+	return view
+}
@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"lab7/domain"
+	"time"
+)
+
+// OverdueInstallment - платёж рассрочки, не внесённый в срок
+type OverdueInstallment struct {
+	OrderID    string
+	CustomerID string
+	Number     int
+	DueAt      time.Time
+	Amount     int64
+	Currency   string
+}
+
+// ReportOverdueInstallmentsUseCase - use-case отчёта о просроченных
+// платежах рассрочки
+type ReportOverdueInstallmentsUseCase struct {
+	orderRepo OrderRepository
+	now       func() time.Time
+}
+
+// ReportOverdueOption - необязательная настройка use-case
+type ReportOverdueOption func(*ReportOverdueInstallmentsUseCase)
+
+// OverdueClock задаёт источник времени для проверки сроков платежей
+func OverdueClock(now func() time.Time) ReportOverdueOption {
+	return func(uc *ReportOverdueInstallmentsUseCase) {
+		uc.now = now
+	}
+}
+
+// NewReportOverdueInstallmentsUseCase создаёт новый use-case
+func NewReportOverdueInstallmentsUseCase(orderRepo OrderRepository, opts ...ReportOverdueOption) *ReportOverdueInstallmentsUseCase {
+	uc := &ReportOverdueInstallmentsUseCase{orderRepo: orderRepo, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute возвращает все невнесённые платежи с истёкшим сроком по
+// заказам в статусах PENDING и PARTIALLY_PAID, упорядоченные по дате
+// создания заказа и номеру платежа
+func (uc *ReportOverdueInstallmentsUseCase) Execute(ctx context.Context) ([]OverdueInstallment, error) {
+	now := uc.now()
+	query := OrderQuery{
+		Statuses: []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusPartiallyPaid},
+		SortBy:   SortByCreatedAt,
+		Limit:    MaxOrderQueryLimit,
+	}
+	var overdue []OverdueInstallment
+	for {
+		page, err := uc.orderRepo.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range page.Orders {
+			for _, installment := range order.OverdueInstallments(now) {
+				overdue = append(overdue, OverdueInstallment{
+					OrderID:    order.ID(),
+					CustomerID: order.CustomerID(),
+					Number:     installment.Number,
+					DueAt:      installment.DueAt,
+					Amount:     installment.Amount.Amount(),
+					Currency:   installment.Amount.Currency(),
+				})
+			}
+		}
+		if page.NextCursor == "" {
+			return overdue, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// PayInstallmentCommand - параметры оплаты очередного платежа рассрочки
+type PayInstallmentCommand struct {
+	OrderID   string
+	Method    string // CARD, WALLET или BANK_TRANSFER; пусто - способ по умолчанию
+	Reference string // реквизит способа оплаты
+}
+
+// PayInstallmentResult - результат оплаты платежа рассрочки
+type PayInstallmentResult struct {
+	Number    int    // номер внесённого платежа
+	Amount    int64  // сумма платежа в минимальных единицах; при ошибке - если платёж определён
+	Currency  string // валюта суммы
+	Status    string // статус заказа после оплаты
+	Remaining int    // число невнесённых платежей
+}
+
+// PayInstallmentUseCase - use-case оплаты очередного платежа по графику
+// рассрочки. Каждый платёж списывается через PaymentGateway отдельно.
+type PayInstallmentUseCase struct {
+	orderRepo      OrderRepository
+	paymentGateway PaymentGateway
+	now            func() time.Time
+}
+
+// PayInstallmentOption - необязательная настройка use-case
+type PayInstallmentOption func(*PayInstallmentUseCase)
+
+// InstallmentClock задаёт источник времени для отметки оплаты платежей
+func InstallmentClock(now func() time.Time) PayInstallmentOption {
+	return func(uc *PayInstallmentUseCase) {
+		uc.now = now
+	}
+}
+
+// NewPayInstallmentUseCase создаёт новый use-case
+func NewPayInstallmentUseCase(orderRepo OrderRepository, paymentGateway PaymentGateway, opts ...PayInstallmentOption) *PayInstallmentUseCase {
+	uc := &PayInstallmentUseCase{orderRepo: orderRepo, paymentGateway: paymentGateway, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute вносит очередной платёж по графику. Заказ сохраняется только
+// после успешного списания, поэтому отклонённый платёж можно повторить.
+func (uc *PayInstallmentUseCase) Execute(ctx context.Context, cmd PayInstallmentCommand) (PayInstallmentResult, error) {
+	var method domain.PaymentMethod
+	if cmd.Method != "" {
+		var err error
+		if method, err = domain.ParsePaymentMethod(cmd.Method, cmd.Reference); err != nil {
+			return PayInstallmentResult{}, err
+		}
+		if method.Kind() == domain.PaymentMethodGiftCard || method.Kind() == domain.PaymentMethodCashOnDelivery {
+			return PayInstallmentResult{}, fmt.Errorf("%w: installments cannot be paid by %s", domain.ErrInvalidPaymentMethod, method.Kind())
+		}
+	}
+
+	order, err := uc.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return PayInstallmentResult{}, err
+	}
+
+	// Первый платёж подчиняется сроку оплаты заказа, как и оплата целиком
+	now := uc.now()
+	if order.IsOverdue(now) {
+		order.Expire(now)
+		if err := uc.orderRepo.Save(ctx, order); err != nil {
+			return PayInstallmentResult{}, err
+		}
+		return PayInstallmentResult{}, fmt.Errorf("%w: order %s was due at %s", domain.ErrOrderExpired, cmd.OrderID, order.PayBy().Format(time.RFC3339))
+	}
+
+	installment, err := order.PayInstallment(method, now)
+	if err != nil {
+		return PayInstallmentResult{}, err
+	}
+	leg := domain.PaymentLeg{Method: method, Amount: installment.Amount}
+	attempted := PayInstallmentResult{
+		Number:   installment.Number,
+		Amount:   installment.Amount.Amount(),
+		Currency: installment.Amount.Currency(),
+	}
+	if err := (tender{gateway: uc.paymentGateway, now: uc.now}).charge(ctx, cmd.OrderID, leg); err != nil {
+		return attempted, err
+	}
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return attempted, err
+	}
+
+	result := PayInstallmentResult{
+		Number:   installment.Number,
+		Amount:   installment.Amount.Amount(),
+		Currency: installment.Amount.Currency(),
+		Status:   order.Status().String(),
+	}
+	for _, i := range order.Installments() {
+		if !i.IsPaid() {
+			result.Remaining++
+		}
+	}
+	return result, nil
+}
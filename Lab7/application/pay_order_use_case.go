@@ -1,33 +1,116 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"lab7/domain"
+	"time"
 )
 
+// PaymentLegCommand - часть оплаты заказа одним способом
+type PaymentLegCommand struct {
+	Method    string // CARD, WALLET, BANK_TRANSFER, CASH_ON_DELIVERY или GIFT_CARD
+	Reference string // токен карты, идентификатор кошелька, счёта или подарочной карты
+	Amount    int64  // сумма в минимальных единицах валюты заказа
+}
+
+// PayOrderCommand - параметры оплаты заказа
+type PayOrderCommand struct {
+	OrderID string
+	// Legs - части оплаты, в сумме равные итогу заказа; пусто - оплата
+	// целиком способом по умолчанию
+	Legs []PaymentLegCommand
+	// GiftCardID - подарочная карта, с которой списывается сколько возможно
+	// до остальных частей; Legs в этом случае покрывают только остаток
+	GiftCardID string
+}
+
 // PayOrderResult - результат выполнения use-case оплаты заказа
 type PayOrderResult struct {
-	Success bool
-	Message string
+	Success  bool
+	Message  string
+	Amount   int64  // сумма оплаты в минимальных единицах; при ошибке - если уже рассчитана
+	Currency string // валюта суммы
 }
 
 // PayOrderUseCase - use-case для оплаты заказа
 type PayOrderUseCase struct {
 	orderRepo      OrderRepository
 	paymentGateway PaymentGateway
+	inventory      InventoryService
+	customers      CustomerRepository
+	shipping       domain.ShippingPolicy
+	giftCards      GiftCardRepository
+	now            func() time.Time
+}
+
+// PayOrderOption - необязательная зависимость use-case оплаты
+type PayOrderOption func(*PayOrderUseCase)
+
+// WithInventory включает резервирование товаров перед списанием средств
+func WithInventory(inventory InventoryService) PayOrderOption {
+	return func(uc *PayOrderUseCase) {
+		uc.inventory = inventory
+	}
+}
+
+// WithCustomers включает проверку статуса клиента перед оплатой
+func WithCustomers(customers CustomerRepository) PayOrderOption {
+	return func(uc *PayOrderUseCase) {
+		uc.customers = customers
+	}
+}
+
+// WithShipping включает расчёт доставки перед оплатой: стоимость доставки
+// пересчитывается по актуальному составу заказа и входит в списываемую сумму
+func WithShipping(policy domain.ShippingPolicy) PayOrderOption {
+	return func(uc *PayOrderUseCase) {
+		uc.shipping = policy
+	}
+}
+
+// WithGiftCards включает оплату подарочными картами
+func WithGiftCards(giftCards GiftCardRepository) PayOrderOption {
+	return func(uc *PayOrderUseCase) {
+		uc.giftCards = giftCards
+	}
+}
+
+// WithClock задаёт источник времени, например для проверки срока
+// действия подарочных карт и срока оплаты заказа
+func WithClock(now func() time.Time) PayOrderOption {
+	return func(uc *PayOrderUseCase) {
+		uc.now = now
+	}
 }
 
 // NewPayOrderUseCase создаёт новый use-case
-func NewPayOrderUseCase(orderRepo OrderRepository, paymentGateway PaymentGateway) *PayOrderUseCase {
-	return &PayOrderUseCase{
+func NewPayOrderUseCase(orderRepo OrderRepository, paymentGateway PaymentGateway, opts ...PayOrderOption) *PayOrderUseCase {
+	uc := &PayOrderUseCase{
 		orderRepo:      orderRepo,
 		paymentGateway: paymentGateway,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(uc)
 	}
+	return uc
+}
+
+// Execute выполняет оплату заказа целиком способом по умолчанию
+func (uc *PayOrderUseCase) Execute(ctx context.Context, orderID string) (PayOrderResult, error) {
+	return uc.ExecuteCommand(ctx, PayOrderCommand{OrderID: orderID})
 }
 
-// Execute выполняет оплату заказа
-func (uc *PayOrderUseCase) Execute(orderID string) (PayOrderResult, error) {
+// ExecuteCommand выполняет оплату заказа одним или несколькими способами.
+// Части списываются по очереди; если часть не прошла, уже списанные
+// части возвращаются.
+func (uc *PayOrderUseCase) ExecuteCommand(ctx context.Context, cmd PayOrderCommand) (PayOrderResult, error) {
+	orderID := cmd.OrderID
+
 	// 1. Загружаем заказ через OrderRepository
-	order, err := uc.orderRepo.GetByID(orderID)
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return PayOrderResult{
 			Success: false,
@@ -35,8 +118,58 @@ func (uc *PayOrderUseCase) Execute(orderID string) (PayOrderResult, error) {
 		}, err
 	}
 
-	// 2. Выполняем доменную операцию оплаты
-	err = order.Pay()
+	// 2. Заблокированный клиент не может оплачивать заказы
+	if uc.customers != nil && order.CustomerID() != "" {
+		customer, err := uc.customers.GetByID(ctx, order.CustomerID())
+		if err == nil {
+			err = customer.CanPay()
+		}
+		if err != nil {
+			return PayOrderResult{
+				Success: false,
+				Message: fmt.Sprintf("customer cannot pay: %v", err),
+			}, err
+		}
+	}
+
+	// 3. Просроченный заказ не оплачивается, даже если его ещё не
+	// обработал OrderSweeper. Статус меняется атомарно, чтобы не затереть
+	// оплату, проведённую параллельно.
+	if now := uc.now(); order.IsOverdue(now) {
+		err := uc.orderRepo.Update(ctx, orderID, func(order *domain.Order) error {
+			if err := order.Expire(now); !errors.Is(err, domain.ErrOrderExpired) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return PayOrderResult{
+				Success: false,
+				Message: fmt.Sprintf("failed to expire order: %v", err),
+			}, err
+		}
+		err = fmt.Errorf("%w: order %s was due at %s", domain.ErrOrderExpired, orderID, order.PayBy().Format(time.RFC3339))
+		return PayOrderResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to pay order: %v", err),
+		}, err
+	}
+
+	// 4. Рассчитываем доставку, если она нужна заказу
+	if uc.shipping != nil && !order.Delivery().IsZero() && order.Status() == domain.OrderStatusPending {
+		if err := order.QuoteShipping(uc.shipping); err != nil {
+			return PayOrderResult{
+				Success: false,
+				Message: fmt.Sprintf("failed to quote shipping: %v", err),
+			}, err
+		}
+	}
+
+	// 5. Выполняем доменную операцию оплаты
+	legs, err := uc.paymentLegs(ctx, order, cmd)
+	if err == nil {
+		err = order.PayWith(legs, uc.now())
+	}
 	if err != nil {
 		return PayOrderResult{
 			Success: false,
@@ -44,7 +177,7 @@ func (uc *PayOrderUseCase) Execute(orderID string) (PayOrderResult, error) {
 		}, err
 	}
 
-	// 3. Рассчитываем сумму для оплаты
+	// 6. Рассчитываем сумму для оплаты
 	total, err := order.Total()
 	if err != nil {
 		return PayOrderResult{
@@ -53,27 +186,183 @@ func (uc *PayOrderUseCase) Execute(orderID string) (PayOrderResult, error) {
 		}, err
 	}
 
-	// 4. Вызываем платёж через PaymentGateway
-	err = uc.paymentGateway.Charge(orderID, total)
+	// 7. Резервируем товары; если до списания со склада что-то пойдёт не так,
+	// резерв снимается
+	if uc.inventory != nil {
+		err = uc.inventory.Reserve(ctx, orderID, stockItems(order))
+		if err != nil {
+			return PayOrderResult{
+				Success:  false,
+				Message:  fmt.Sprintf("failed to reserve stock: %v", err),
+				Amount:   total.Amount(),
+				Currency: total.Currency(),
+			}, err
+		}
+		defer func() {
+			if err != nil {
+				// Резерв снимается и при отменённом контексте запроса
+				uc.inventory.Release(context.WithoutCancel(ctx), orderID)
+			}
+		}()
+	}
+
+	// 8. Списываем каждую часть оплаты: подарочные карты - через
+	// GiftCardRepository, остальное - через PaymentGateway
+	err = uc.tender().chargeAll(ctx, orderID, order.Payments())
 	if err != nil {
 		return PayOrderResult{
-			Success: false,
-			Message: fmt.Sprintf("payment failed: %v", err),
+			Success:  false,
+			Message:  err.Error(),
+			Amount:   total.Amount(),
+			Currency: total.Currency(),
 		}, err
 	}
 
-	// 5. Сохраняем заказ
-	err = uc.orderRepo.Save(order)
+	// 9. Списываем товары со склада; если резерв успел истечь, деньги возвращаются
+	if uc.inventory != nil {
+		err = uc.inventory.Commit(ctx, orderID)
+		if err != nil {
+			if refundErr := uc.tender().refundAll(context.WithoutCancel(ctx), orderID, order.Payments()); refundErr != nil {
+				err = fmt.Errorf("%w (refund failed: %w)", err, refundErr)
+			}
+			return PayOrderResult{
+				Success:  false,
+				Message:  fmt.Sprintf("failed to commit stock: %v", err),
+				Amount:   total.Amount(),
+				Currency: total.Currency(),
+			}, err
+		}
+	}
+
+	// 10. Сохраняем заказ; если сохранить не удалось, оплата отменяется:
+	// части возвращаются, а товары - на склад
+	err = uc.orderRepo.Save(ctx, order)
 	if err != nil {
+		if undoErr := uc.undoPayment(context.WithoutCancel(ctx), order); undoErr != nil {
+			err = fmt.Errorf("%w (%w)", err, undoErr)
+		}
 		return PayOrderResult{
-			Success: false,
-			Message: fmt.Sprintf("failed to save order: %v", err),
+			Success:  false,
+			Message:  fmt.Sprintf("failed to save order: %v", err),
+			Amount:   total.Amount(),
+			Currency: total.Currency(),
 		}, err
 	}
 
-	// 6. Возвращаем результат оплаты
+	// 11. Возвращаем результат оплаты
 	return PayOrderResult{
-		Success: true,
-		Message: fmt.Sprintf("order %s paid successfully for %s", orderID, total.String()),
+		Success:  true,
+		Message:  fmt.Sprintf("order %s paid successfully for %s", orderID, total.String()),
+		Amount:   total.Amount(),
+		Currency: total.Currency(),
 	}, nil
 }
+
+// undoPayment отменяет списания и складскую операцию оплаты, которую не
+// удалось сохранить
+func (uc *PayOrderUseCase) undoPayment(ctx context.Context, order *domain.Order) error {
+	var errs []error
+	if err := uc.tender().refundAll(ctx, order.ID(), order.Payments()); err != nil {
+		errs = append(errs, fmt.Errorf("refund failed: %w", err))
+	}
+	if uc.inventory != nil {
+		if err := uc.inventory.Restock(ctx, stockItems(order)); err != nil {
+			errs = append(errs, fmt.Errorf("restock failed: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tender возвращает исполнителя частей оплаты
+func (uc *PayOrderUseCase) tender() tender {
+	return tender{gateway: uc.paymentGateway, giftCards: uc.giftCards, now: uc.now}
+}
+
+// paymentLegs переводит части оплаты из команды в доменные объекты в
+// валюте заказа. Подарочная карта идёт первой частью; если частей в
+// команде нет, остаток оплачивается способом по умолчанию. Для команды
+// без частей и карты возвращает nil.
+func (uc *PayOrderUseCase) paymentLegs(ctx context.Context, order *domain.Order, cmd PayOrderCommand) ([]domain.PaymentLeg, error) {
+	if len(cmd.Legs) == 0 && cmd.GiftCardID == "" {
+		return nil, nil
+	}
+	total, err := order.Total()
+	if err != nil {
+		return nil, err
+	}
+
+	var legs []domain.PaymentLeg
+	remainder := total.Amount()
+	if cmd.GiftCardID != "" {
+		leg, err := uc.giftCardLeg(ctx, cmd.GiftCardID, total)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+		remainder -= leg.Amount.Amount()
+	}
+	if len(cmd.Legs) == 0 {
+		if remainder > 0 {
+			rest, _ := domain.NewMoney(remainder, total.Currency())
+			legs = append(legs, domain.PaymentLeg{Amount: rest})
+		}
+		return legs, nil
+	}
+
+	for _, c := range cmd.Legs {
+		method, err := domain.ParsePaymentMethod(c.Method, c.Reference)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := domain.NewMoney(c.Amount, total.Currency())
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, domain.PaymentLeg{Method: method, Amount: amount})
+	}
+	return legs, nil
+}
+
+// giftCardLeg определяет часть оплаты подарочной картой: весь итог или
+// весь остаток на карте, если его не хватает
+func (uc *PayOrderUseCase) giftCardLeg(ctx context.Context, cardID string, total domain.Money) (domain.PaymentLeg, error) {
+	method, err := domain.NewGiftCardPayment(cardID)
+	if err != nil {
+		return domain.PaymentLeg{}, err
+	}
+	if uc.giftCards == nil {
+		return domain.PaymentLeg{}, ErrGiftCardsNotAccepted
+	}
+	card, err := uc.giftCards.GetByID(ctx, cardID)
+	if err != nil {
+		return domain.PaymentLeg{}, err
+	}
+	if card.IsExpired(uc.now()) {
+		return domain.PaymentLeg{}, fmt.Errorf("%w: card %s", domain.ErrGiftCardExpired, cardID)
+	}
+	if card.Balance().Currency() != total.Currency() {
+		return domain.PaymentLeg{}, fmt.Errorf("%w: %s and %s", domain.ErrCurrencyMismatch, card.Balance().Currency(), total.Currency())
+	}
+
+	amount := min(card.Balance().Amount(), total.Amount())
+	if amount == 0 {
+		return domain.PaymentLeg{}, fmt.Errorf("%w: card %s is empty", domain.ErrInsufficientBalance, cardID)
+	}
+	draw, _ := domain.NewMoney(amount, total.Currency())
+	return domain.PaymentLeg{Method: method, Amount: draw}, nil
+}
+
+// stockItems суммирует количество товаров по строкам заказа
+func stockItems(order *domain.Order) []StockItem {
+	var items []StockItem
+	index := make(map[string]int)
+	for _, line := range order.Lines() {
+		if i, ok := index[line.ProductID()]; ok {
+			items[i].Quantity += line.Quantity()
+			continue
+		}
+		index[line.ProductID()] = len(items)
+		items = append(items, StockItem{ProductID: line.ProductID(), Quantity: line.Quantity()})
+	}
+	return items
+}
@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"lab7/domain"
+	"sync"
+)
+
+// DefaultBatchWorkers - число одновременных оплат пакета по умолчанию
+const DefaultBatchWorkers = 8
+
+// BatchOutcome - исход оплаты одного заказа из пакета
+type BatchOutcome string
+
+const (
+	// BatchPaid - заказ оплачен
+	BatchPaid BatchOutcome = "paid"
+	// BatchAlreadyPaid - заказ был оплачен раньше, списания не было
+	BatchAlreadyPaid BatchOutcome = "already_paid"
+	// BatchDeclined - списание отклонено платёжным шлюзом
+	BatchDeclined BatchOutcome = "declined"
+	// BatchRetryable - временный сбой шлюза; заказ можно оплатить повторно
+	BatchRetryable BatchOutcome = "retryable"
+	// BatchFailed - заказ не оплачен по другой причине
+	BatchFailed BatchOutcome = "failed"
+	// BatchCanceled - обработка прервана отменой контекста
+	BatchCanceled BatchOutcome = "canceled"
+)
+
+// batchOutcomes - исходы в порядке вывода в отчёте
+var batchOutcomes = []BatchOutcome{BatchPaid, BatchAlreadyPaid, BatchDeclined, BatchRetryable, BatchFailed, BatchCanceled}
+
+// BatchPayment - результат оплаты одного заказа из пакета
+type BatchPayment struct {
+	OrderID  string
+	Outcome  BatchOutcome
+	Amount   int64  // списанная сумма для BatchPaid
+	Currency string // валюта суммы
+	Err      error  // причина неудачи; nil для BatchPaid
+}
+
+// PayOrdersBatchResult - отчёт об оплате пакета заказов
+type PayOrdersBatchResult struct {
+	// Payments - результаты в порядке первого появления заказа в пакете
+	Payments []BatchPayment
+	// Duplicates - число повторных идентификаторов, пропущенных без оплаты
+	Duplicates int
+	// Counts - число заказов по исходам
+	Counts map[BatchOutcome]int
+	// Charged - списанная сумма по валютам в минимальных единицах
+	Charged map[string]int64
+}
+
+// Outcomes возвращает исходы, встретившиеся в пакете, в порядке отчёта
+func (r PayOrdersBatchResult) Outcomes() []BatchOutcome {
+	var outcomes []BatchOutcome
+	for _, outcome := range batchOutcomes {
+		if r.Counts[outcome] > 0 {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// PayOrdersBatchUseCase - use-case оплаты пакета заказов. Заказы
+// оплачиваются параллельно через PayOrderExecutor, но не больше заданного
+// числа одновременно.
+type PayOrdersBatchUseCase struct {
+	payOrder PayOrderExecutor
+	workers  int
+}
+
+// PayOrdersBatchOption - необязательная настройка use-case
+type PayOrdersBatchOption func(*PayOrdersBatchUseCase)
+
+// BatchWorkers задаёт наибольшее число одновременных оплат; по умолчанию
+// DefaultBatchWorkers
+func BatchWorkers(n int) PayOrdersBatchOption {
+	return func(uc *PayOrdersBatchUseCase) {
+		if n > 0 {
+			uc.workers = n
+		}
+	}
+}
+
+// NewPayOrdersBatchUseCase создаёт новый use-case
+func NewPayOrdersBatchUseCase(payOrder PayOrderExecutor, opts ...PayOrdersBatchOption) *PayOrdersBatchUseCase {
+	uc := &PayOrdersBatchUseCase{payOrder: payOrder, workers: DefaultBatchWorkers}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute оплачивает заказы orderIDs. Повторные идентификаторы
+// отбрасываются, поэтому каждый заказ оплачивается не больше одного раза.
+// Отказы по отдельным заказам возвращаются в отчёте; ошибкой возвращается
+// только отмена контекста, после которой новые заказы не начинаются и
+// получают исход BatchCanceled.
+func (uc *PayOrdersBatchUseCase) Execute(ctx context.Context, orderIDs []string) (PayOrdersBatchResult, error) {
+	result := PayOrdersBatchResult{
+		Counts:  make(map[BatchOutcome]int),
+		Charged: make(map[string]int64),
+	}
+	seen := make(map[string]bool, len(orderIDs))
+	for _, orderID := range orderIDs {
+		if seen[orderID] {
+			result.Duplicates++
+			continue
+		}
+		seen[orderID] = true
+		result.Payments = append(result.Payments, BatchPayment{OrderID: orderID})
+	}
+
+	// Каждый исполнитель пишет только в свои элементы Payments, поэтому
+	// синхронизация нужна лишь для раздачи индексов
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(uc.workers, len(result.Payments)) {
+		wg.Go(func() {
+			for i := range jobs {
+				uc.pay(ctx, &result.Payments[i])
+			}
+		})
+	}
+	for i := range result.Payments {
+		if ctx.Err() != nil {
+			result.Payments[i].Outcome, result.Payments[i].Err = BatchCanceled, ctx.Err()
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			result.Payments[i].Outcome, result.Payments[i].Err = BatchCanceled, ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, payment := range result.Payments {
+		result.Counts[payment.Outcome]++
+		if payment.Outcome == BatchPaid {
+			result.Charged[payment.Currency] += payment.Amount
+		}
+	}
+	return result, ctx.Err()
+}
+
+// pay оплачивает один заказ и записывает исход
+func (uc *PayOrdersBatchUseCase) pay(ctx context.Context, payment *BatchPayment) {
+	if err := ctx.Err(); err != nil {
+		payment.Outcome, payment.Err = BatchCanceled, err
+		return
+	}
+	result, err := uc.payOrder.Execute(ctx, payment.OrderID)
+	payment.Err = err
+	switch {
+	case err == nil:
+		payment.Outcome, payment.Amount, payment.Currency = BatchPaid, result.Amount, result.Currency
+	case errors.Is(err, domain.ErrOrderAlreadyPaid):
+		payment.Outcome = BatchAlreadyPaid
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		payment.Outcome = BatchCanceled
+	case errors.Is(err, ErrGatewayUnavailable):
+		payment.Outcome = BatchRetryable
+	case errors.Is(err, ErrPaymentFailed):
+		payment.Outcome = BatchDeclined
+	default:
+		payment.Outcome = BatchFailed
+	}
+}
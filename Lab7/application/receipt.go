@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// ReceiptLine - строка чека
+type ReceiptLine struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name,omitempty"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	Total     int64  `json:"total"`
+	VATRate   string `json:"vat_rate"`
+	VAT       int64  `json:"vat"` // НДС, включённый в Total
+}
+
+// ReceiptTax - сумма НДС по одной ставке
+type ReceiptTax struct {
+	Rate   string `json:"rate"`
+	Base   int64  `json:"base"`   // стоимость строк с этой ставкой, включая налог
+	Amount int64  `json:"amount"` // выделенный налог
+}
+
+// ReceiptPayment - часть оплаты в чеке
+type ReceiptPayment struct {
+	Method    string `json:"method"`    // вид оплаты; пусто - способ по умолчанию
+	Reference string `json:"reference"` // вид оплаты и последние символы реквизита
+	Amount    int64  `json:"amount"`
+}
+
+// Receipt - чек по оплаченному заказу. Все суммы указаны в минимальных
+// единицах валюты Currency.
+type Receipt struct {
+	OrderID    string           `json:"order_id"`
+	CustomerID string           `json:"customer_id,omitempty"`
+	Status     string           `json:"status"`
+	IssuedAt   time.Time        `json:"issued_at"`
+	Currency   string           `json:"currency"`
+	Lines      []ReceiptLine    `json:"lines"`
+	Subtotal   int64            `json:"subtotal"`
+	Shipping   int64            `json:"shipping"`
+	Total      int64            `json:"total"`
+	Taxes      []ReceiptTax     `json:"taxes"`
+	Payments   []ReceiptPayment `json:"payments"`
+}
+
+// FormatAmount форматирует сумму чека в формате Money.String
+func (r Receipt) FormatAmount(amount int64) string {
+	money, err := domain.NewMoney(amount, r.Currency)
+	if err != nil {
+		return fmt.Sprintf("%d", amount)
+	}
+	return money.String()
+}
+
+// newReceipt строит чек по заказу. Для заказов без единого итога
+// (пустых или в разных валютах) возвращает ошибку.
+func newReceipt(order *domain.Order, issuedAt time.Time) (Receipt, error) {
+	total, err := order.Total()
+	if err != nil {
+		return Receipt{}, err
+	}
+	subtotal, _ := order.Subtotal()
+
+	receipt := Receipt{
+		OrderID:    order.ID(),
+		CustomerID: order.CustomerID(),
+		Status:     order.Status().String(),
+		IssuedAt:   issuedAt,
+		Currency:   total.Currency(),
+		Lines:      make([]ReceiptLine, 0, len(order.Lines())),
+		Subtotal:   subtotal.Amount(),
+		Shipping:   order.Shipping().Amount(),
+		Total:      total.Amount(),
+		Taxes:      []ReceiptTax{},
+		Payments:   []ReceiptPayment{},
+	}
+
+	// Налоги суммируются по ставкам в порядке первого появления в заказе
+	taxIndex := make(map[domain.VATRate]int)
+	for _, line := range order.Lines() {
+		lineTotal, vat := line.Total().Amount(), line.VAT().Amount()
+		receipt.Lines = append(receipt.Lines, ReceiptLine{
+			ProductID: line.ProductID(),
+			Name:      line.Name(),
+			Quantity:  line.Quantity(),
+			UnitPrice: line.Price().Amount(),
+			Total:     lineTotal,
+			VATRate:   line.VATRate().String(),
+			VAT:       vat,
+		})
+
+		i, ok := taxIndex[line.VATRate()]
+		if !ok {
+			i = len(receipt.Taxes)
+			taxIndex[line.VATRate()] = i
+			receipt.Taxes = append(receipt.Taxes, ReceiptTax{Rate: line.VATRate().String()})
+		}
+		receipt.Taxes[i].Base += lineTotal
+		receipt.Taxes[i].Amount += vat
+	}
+
+	for _, leg := range order.Payments() {
+		receipt.Payments = append(receipt.Payments, ReceiptPayment{
+			Method:    leg.Method.Kind().String(),
+			Reference: leg.Method.String(),
+			Amount:    leg.Amount.Amount(),
+		})
+	}
+	return receipt, nil
+}
+
+// GetReceiptUseCase - use-case получения чека по заказу
+type GetReceiptUseCase struct {
+	orderRepo OrderRepository
+	now       func() time.Time
+}
+
+// GetReceiptOption - необязательная настройка use-case получения чека
+type GetReceiptOption func(*GetReceiptUseCase)
+
+// ReceiptClock задаёт источник времени выдачи чека
+func ReceiptClock(now func() time.Time) GetReceiptOption {
+	return func(uc *GetReceiptUseCase) {
+		uc.now = now
+	}
+}
+
+// NewGetReceiptUseCase создаёт новый use-case
+func NewGetReceiptUseCase(orderRepo OrderRepository, opts ...GetReceiptOption) *GetReceiptUseCase {
+	uc := &GetReceiptUseCase{orderRepo: orderRepo, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute возвращает чек по заказу. Чек выдаётся только по оплаченным
+// заказам, в том числе после возврата средств.
+func (uc *GetReceiptUseCase) Execute(ctx context.Context, orderID string) (Receipt, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if status := order.Status(); status != domain.OrderStatusPaid && status != domain.OrderStatusRefunded {
+		return Receipt{}, fmt.Errorf("%w: order %s", domain.ErrOrderNotPaid, orderID)
+	}
+	return newReceipt(order, uc.now())
+}
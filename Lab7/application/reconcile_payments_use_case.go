@@ -0,0 +1,406 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// DefaultSettleDelay - время, в течение которого операция шлюза считается
+// незавершённой: оплата могла списать деньги, но ещё не сохранить заказ
+const DefaultSettleDelay = time.Minute
+
+// DiscrepancyKind - вид расхождения между заказом и выпиской шлюза
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyPaidNotCharged - заказ оплачен, но шлюз по нему ничего не списал
+	DiscrepancyPaidNotCharged DiscrepancyKind = "paid_not_charged"
+	// DiscrepancyChargedPending - шлюз списал деньги за неоплаченный заказ
+	DiscrepancyChargedPending DiscrepancyKind = "charged_but_pending"
+	// DiscrepancyAmountMismatch - списания шлюза не совпадают с оплатой заказа
+	DiscrepancyAmountMismatch DiscrepancyKind = "amount_mismatch"
+	// DiscrepancyDuplicateCharge - часть оплаты списана повторно
+	DiscrepancyDuplicateCharge DiscrepancyKind = "duplicate_charge"
+	// DiscrepancyUnknownOrder - шлюз списал деньги за заказ, которого нет в хранилище
+	DiscrepancyUnknownOrder DiscrepancyKind = "unknown_order"
+)
+
+// Discrepancy - расхождение по одному заказу
+type Discrepancy struct {
+	Kind     DiscrepancyKind
+	OrderID  string
+	Status   domain.OrderStatus // пусто для DiscrepancyUnknownOrder
+	Expected int64              // сколько по данным заказа должен был списать шлюз
+	Charged  int64              // сколько шлюз списал за вычетом возвратов
+	Currency string             // валюта сумм
+	// Transactions - операции шлюза по заказу; для DiscrepancyDuplicateCharge -
+	// только лишнее списание
+	Transactions []Transaction
+	Repaired     bool  // расхождение исправлено (см. AutoRepair)
+	RepairErr    error // причина, по которой исправить не удалось
+}
+
+// ReconcilePaymentsResult - результат сверки
+type ReconcilePaymentsResult struct {
+	Orders        int      // число сверенных заказов
+	Unsettled     []string // заказы с незавершёнными операциями; сверяются в следующий раз
+	Discrepancies []Discrepancy
+}
+
+// Count возвращает число расхождений вида kind
+func (r ReconcilePaymentsResult) Count(kind DiscrepancyKind) int {
+	n := 0
+	for _, d := range r.Discrepancies {
+		if d.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// ReconcilePaymentsUseCase - use-case сверки оплат заказов с выпиской
+// платёжного шлюза. Сверяются оплаченные и частично оплаченные заказы и
+// все заказы, по которым в выписке есть операции. Части оплаты
+// подарочными картами проходят мимо шлюза и в сверке не участвуют.
+type ReconcilePaymentsUseCase struct {
+	orderRepo    OrderRepository
+	transactions TransactionLog
+	gateway      PaymentGateway
+	settleDelay  time.Duration
+	now          func() time.Time
+}
+
+// ReconcileOption - необязательная настройка use-case сверки
+type ReconcileOption func(*ReconcilePaymentsUseCase)
+
+// AutoRepair включает исправление безопасных расхождений: повторное
+// списание возвращается через gateway, а неоплаченный заказ, за который
+// шлюз списал ровно его итог, переводится в PAID. Остальные расхождения
+// только попадают в отчёт.
+func AutoRepair(gateway PaymentGateway) ReconcileOption {
+	return func(uc *ReconcilePaymentsUseCase) {
+		uc.gateway = gateway
+	}
+}
+
+// SettleDelay задаёт, сколько ждать завершения операции шлюза, прежде чем
+// сверять её заказ; по умолчанию DefaultSettleDelay
+func SettleDelay(d time.Duration) ReconcileOption {
+	return func(uc *ReconcilePaymentsUseCase) {
+		uc.settleDelay = d
+	}
+}
+
+// ReconcileClock задаёт источник времени для SettleDelay
+func ReconcileClock(now func() time.Time) ReconcileOption {
+	return func(uc *ReconcilePaymentsUseCase) {
+		uc.now = now
+	}
+}
+
+// NewReconcilePaymentsUseCase создаёт новый use-case
+func NewReconcilePaymentsUseCase(orderRepo OrderRepository, transactions TransactionLog, opts ...ReconcileOption) *ReconcilePaymentsUseCase {
+	uc := &ReconcilePaymentsUseCase{
+		orderRepo:    orderRepo,
+		transactions: transactions,
+		settleDelay:  DefaultSettleDelay,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute сверяет заказы с выпиской шлюза. Заказ с операциями моложе
+// SettleDelay откладывается: его оплата может быть ещё в процессе. Ошибки
+// хранилища по одному заказу не останавливают сверку остальных и
+// возвращаются вместе.
+func (uc *ReconcilePaymentsUseCase) Execute(ctx context.Context) (ReconcilePaymentsResult, error) {
+	now := uc.now()
+	transactions, err := uc.transactions.Transactions(ctx)
+	if err != nil {
+		return ReconcilePaymentsResult{}, err
+	}
+
+	var orderIDs []string
+	byOrder := make(map[string][]Transaction)
+	for _, tx := range transactions {
+		if _, ok := byOrder[tx.OrderID]; !ok {
+			orderIDs = append(orderIDs, tx.OrderID)
+		}
+		byOrder[tx.OrderID] = append(byOrder[tx.OrderID], tx)
+	}
+	paid, err := uc.findPaid(ctx)
+	if err != nil {
+		return ReconcilePaymentsResult{}, err
+	}
+	for _, orderID := range paid {
+		if _, ok := byOrder[orderID]; !ok {
+			orderIDs = append(orderIDs, orderID)
+			byOrder[orderID] = nil
+		}
+	}
+
+	var result ReconcilePaymentsResult
+	var errs []error
+	for _, orderID := range orderIDs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		txs := byOrder[orderID]
+		if uc.unsettled(txs, now) {
+			result.Unsettled = append(result.Unsettled, orderID)
+			continue
+		}
+		discrepancies, err := uc.reconcile(ctx, orderID, txs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", orderID, err))
+			continue
+		}
+		result.Orders++
+		result.Discrepancies = append(result.Discrepancies, discrepancies...)
+	}
+	return result, errors.Join(errs...)
+}
+
+// findPaid собирает идентификаторы оплаченных и частично оплаченных
+// заказов по всем страницам
+func (uc *ReconcilePaymentsUseCase) findPaid(ctx context.Context) ([]string, error) {
+	query := OrderQuery{
+		Statuses: []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusPartiallyPaid},
+		SortBy:   SortByCreatedAt,
+		Limit:    MaxOrderQueryLimit,
+	}
+	var paid []string
+	for {
+		page, err := uc.orderRepo.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range page.Orders {
+			paid = append(paid, order.ID())
+		}
+		if page.NextCursor == "" {
+			return paid, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// unsettled сообщает, что у заказа есть операции моложе SettleDelay
+func (uc *ReconcilePaymentsUseCase) unsettled(txs []Transaction, now time.Time) bool {
+	settled := now.Add(-uc.settleDelay)
+	for _, tx := range txs {
+		if tx.At.After(settled) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcile сверяет один заказ с его операциями. Заказ перечитывается,
+// чтобы исправления опирались на его текущее состояние.
+func (uc *ReconcilePaymentsUseCase) reconcile(ctx context.Context, orderID string, txs []Transaction) ([]Discrepancy, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if errors.Is(err, ErrOrderNotFound) && len(txs) > 0 {
+		d := Discrepancy{Kind: DiscrepancyUnknownOrder, OrderID: orderID, Currency: txs[0].Amount.Currency(), Transactions: txs}
+		d.Charged = netCharged(txs, d.Currency)
+		if len(activeCharges(txs)) == 0 && d.Charged == 0 {
+			return nil, nil
+		}
+		return []Discrepancy{d}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expected := expectedCharges(order)
+	active := activeCharges(txs)
+	extra, missing := matchCharges(active, expected)
+
+	d := Discrepancy{OrderID: orderID, Status: order.Status(), Transactions: txs}
+	switch {
+	case len(expected) > 0:
+		d.Currency = expected[0].Amount.Currency()
+	case len(txs) > 0:
+		d.Currency = txs[0].Amount.Currency()
+	}
+	for _, leg := range expected {
+		d.Expected += leg.Amount.Amount()
+	}
+	d.Charged = netCharged(txs, d.Currency)
+
+	switch {
+	case len(extra) == 0 && len(missing) == 0 && d.Charged == d.Expected:
+		return nil, nil
+	case len(expected) == 0 && (order.Status() == domain.OrderStatusPending || order.Status() == domain.OrderStatusExpired):
+		d.Kind = DiscrepancyChargedPending
+		if uc.gateway != nil && order.Status() == domain.OrderStatusPending && d.Charged == sumCharges(active) {
+			d.Repaired, d.RepairErr = uc.markPaid(ctx, orderID, active)
+		}
+		return []Discrepancy{d}, nil
+	case len(expected) > 0 && len(active) == 0:
+		d.Kind = DiscrepancyPaidNotCharged
+		return []Discrepancy{d}, nil
+	}
+
+	// Лишнее списание на сумму одной из частей оплаты - повтор этой части
+	var duplicates, unexpected []Transaction
+	for _, tx := range extra {
+		if containsAmount(expected, tx.Amount) {
+			duplicates = append(duplicates, tx)
+		} else {
+			unexpected = append(unexpected, tx)
+		}
+	}
+	mismatch := len(unexpected) > 0 || len(missing) > 0 || d.Charged != d.Expected+sumCharges(duplicates)
+
+	var discrepancies []Discrepancy
+	for _, tx := range duplicates {
+		dup := d
+		dup.Kind = DiscrepancyDuplicateCharge
+		dup.Transactions = []Transaction{tx}
+		// Возврат безопасен, только если других расхождений по заказу нет
+		if uc.gateway != nil && !mismatch {
+			dup.Repaired, dup.RepairErr = uc.refund(ctx, tx)
+		}
+		discrepancies = append(discrepancies, dup)
+	}
+	if mismatch {
+		d.Kind = DiscrepancyAmountMismatch
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies, nil
+}
+
+// markPaid атомарно переводит заказ в PAID с частями оплаты, списанными
+// шлюзом. Заказ с истёкшим сроком оплаты или изменённый после чтения
+// (например, оплаченный параллельно) не переводится.
+func (uc *ReconcilePaymentsUseCase) markPaid(ctx context.Context, orderID string, charges []Transaction) (bool, error) {
+	legs := make([]domain.PaymentLeg, len(charges))
+	for i, tx := range charges {
+		legs[i] = domain.PaymentLeg{Method: tx.Method, Amount: tx.Amount}
+	}
+	err := uc.orderRepo.Update(ctx, orderID, func(order *domain.Order) error {
+		return order.PayWith(legs, uc.now())
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refund возвращает повторное списание
+func (uc *ReconcilePaymentsUseCase) refund(ctx context.Context, tx Transaction) (bool, error) {
+	if err := uc.gateway.Refund(ctx, tx.OrderID, tx.Method, tx.Amount); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrRefundFailed, err)
+	}
+	return true, nil
+}
+
+// expectedCharges возвращает части оплаты заказа, которые должен был
+// списать шлюз: для оплаченных и частично оплаченных заказов - все части,
+// кроме подарочных карт. Оплаченный заказ без сведений о частях считается
+// оплаченным целиком способом по умолчанию.
+func expectedCharges(order *domain.Order) []domain.PaymentLeg {
+	switch order.Status() {
+	case domain.OrderStatusPaid, domain.OrderStatusPartiallyPaid:
+	default:
+		return nil
+	}
+	payments := order.Payments()
+	if len(payments) == 0 {
+		total, err := order.Total()
+		if err != nil {
+			return nil
+		}
+		return []domain.PaymentLeg{{Amount: total}}
+	}
+	var legs []domain.PaymentLeg
+	for _, leg := range payments {
+		if leg.Method.Kind() != domain.PaymentMethodGiftCard {
+			legs = append(legs, leg)
+		}
+	}
+	return legs
+}
+
+// activeCharges возвращает списания, по которым не было возврата. Возврат
+// погашает последнее списание того же способа на ту же сумму.
+func activeCharges(txs []Transaction) []Transaction {
+	var charges []Transaction
+	for _, tx := range txs {
+		if tx.Kind == TransactionCharge {
+			charges = append(charges, tx)
+			continue
+		}
+		for i := len(charges) - 1; i >= 0; i-- {
+			if charges[i].Method == tx.Method && charges[i].Amount.Equals(tx.Amount) {
+				charges = append(charges[:i], charges[i+1:]...)
+				break
+			}
+		}
+	}
+	return charges
+}
+
+// matchCharges сопоставляет списания с ожидаемыми частями оплаты по
+// сумме и возвращает лишние списания и части, для которых списания нет
+func matchCharges(charges []Transaction, expected []domain.PaymentLeg) ([]Transaction, []domain.PaymentLeg) {
+	missing := append([]domain.PaymentLeg(nil), expected...)
+	var extra []Transaction
+	for _, tx := range charges {
+		matched := false
+		for i, leg := range missing {
+			if leg.Amount.Equals(tx.Amount) {
+				missing = append(missing[:i], missing[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			extra = append(extra, tx)
+		}
+	}
+	return extra, missing
+}
+
+// netCharged возвращает сумму списаний за вычетом возвратов в валюте currency
+func netCharged(txs []Transaction, currency string) int64 {
+	var net int64
+	for _, tx := range txs {
+		if tx.Amount.Currency() != currency {
+			continue
+		}
+		if tx.Kind == TransactionRefund {
+			net -= tx.Amount.Amount()
+		} else {
+			net += tx.Amount.Amount()
+		}
+	}
+	return net
+}
+
+// sumCharges возвращает сумму списаний
+func sumCharges(charges []Transaction) int64 {
+	var sum int64
+	for _, tx := range charges {
+		sum += tx.Amount.Amount()
+	}
+	return sum
+}
+
+// containsAmount сообщает, есть ли среди частей оплаты часть на сумму amount
+func containsAmount(legs []domain.PaymentLeg, amount domain.Money) bool {
+	for _, leg := range legs {
+		if leg.Amount.Equals(amount) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// RefundOrderResult - результат выполнения use-case возврата средств
+type RefundOrderResult struct {
+	Success  bool
+	Message  string
+	Amount   int64  // сумма возврата в минимальных единицах; при ошибке - если уже рассчитана
+	Currency string // валюта суммы
+}
+
+// RefundOrderUseCase - use-case возврата средств за оплаченный заказ
+type RefundOrderUseCase struct {
+	orderRepo      OrderRepository
+	paymentGateway PaymentGateway
+	giftCards      GiftCardRepository
+}
+
+// RefundOrderOption - необязательная зависимость use-case возврата
+type RefundOrderOption func(*RefundOrderUseCase)
+
+// RestoreGiftCards включает возврат оплаченных подарочной картой сумм на карту
+func RestoreGiftCards(giftCards GiftCardRepository) RefundOrderOption {
+	return func(uc *RefundOrderUseCase) {
+		uc.giftCards = giftCards
+	}
+}
+
+// NewRefundOrderUseCase создаёт новый use-case
+func NewRefundOrderUseCase(orderRepo OrderRepository, paymentGateway PaymentGateway, opts ...RefundOrderOption) *RefundOrderUseCase {
+	uc := &RefundOrderUseCase{
+		orderRepo:      orderRepo,
+		paymentGateway: paymentGateway,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// tender возвращает исполнителя частей оплаты
+func (uc *RefundOrderUseCase) tender() tender {
+	return tender{gateway: uc.paymentGateway, giftCards: uc.giftCards, now: time.Now}
+}
+
+// Execute выполняет возврат средств за заказ
+func (uc *RefundOrderUseCase) Execute(ctx context.Context, orderID string) (RefundOrderResult, error) {
+	// 1. Загружаем заказ через OrderRepository
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return RefundOrderResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to load order: %v", err),
+		}, err
+	}
+
+	// 2. Проверяем, что заказ можно вернуть, и определяем сумму
+	amount, err := order.RefundableAmount()
+	if err != nil {
+		return RefundOrderResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to refund order: %v", err),
+		}, err
+	}
+
+	// 3. Возвращаем средства через PaymentGateway теми же способами, что
+	// и при оплате, в обратном порядке. Возвращённые части отмечаются в
+	// заказе; на первой неудаче возврат останавливается и отметки
+	// сохраняются, чтобы повтор не вернул эти части второй раз.
+	if err := uc.refundPayments(ctx, order, amount); err != nil {
+		err = fmt.Errorf("%w: %w", ErrRefundFailed, err)
+		if saveErr := uc.orderRepo.Save(ctx, order); saveErr != nil {
+			err = fmt.Errorf("%w (failed to save refunded legs: %w)", err, saveErr)
+		}
+		return RefundOrderResult{
+			Success:  false,
+			Message:  err.Error(),
+			Amount:   amount.Amount(),
+			Currency: amount.Currency(),
+		}, err
+	}
+	if _, err := order.Refund(); err != nil {
+		return RefundOrderResult{
+			Success:  false,
+			Message:  fmt.Sprintf("failed to refund order: %v", err),
+			Amount:   amount.Amount(),
+			Currency: amount.Currency(),
+		}, err
+	}
+
+	// 4. Сохраняем заказ
+	err = uc.orderRepo.Save(ctx, order)
+	if err != nil {
+		return RefundOrderResult{
+			Success:  false,
+			Message:  fmt.Sprintf("failed to save order: %v", err),
+			Amount:   amount.Amount(),
+			Currency: amount.Currency(),
+		}, err
+	}
+
+	return RefundOrderResult{
+		Success:  true,
+		Message:  fmt.Sprintf("order %s refunded for %s", orderID, amount.String()),
+		Amount:   amount.Amount(),
+		Currency: amount.Currency(),
+	}, nil
+}
+
+// refundPayments возвращает ещё не возвращённые части оплаты в обратном
+// порядке и отмечает их в заказе. Заказ без сведений о частях
+// возвращается одним возвратом способом по умолчанию.
+func (uc *RefundOrderUseCase) refundPayments(ctx context.Context, order *domain.Order, amount domain.Money) error {
+	legs := order.Payments()
+	if len(legs) == 0 {
+		return uc.tender().refund(ctx, order.ID(), domain.PaymentLeg{Amount: amount})
+	}
+	for i := len(legs) - 1; i >= 0; i-- {
+		if legs[i].Refunded {
+			continue
+		}
+		if err := uc.tender().refund(ctx, order.ID(), legs[i]); err != nil {
+			return fmt.Errorf("%s %s: %w", legs[i].Method, legs[i].Amount, err)
+		}
+		if err := order.MarkPaymentRefunded(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
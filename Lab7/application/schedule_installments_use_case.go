@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// ScheduleInstallmentsCommand - параметры графика рассрочки
+type ScheduleInstallmentsCommand struct {
+	OrderID  string
+	Count    int       // число платежей
+	FirstDue time.Time // срок первого платежа
+	Interval string    // WEEKLY, MONTHLY или YEARLY; пусто - MONTHLY
+}
+
+// ScheduleInstallmentsUseCase - use-case назначения графика рассрочки.
+// Итог заказа делится на Count платежей без потерь, остаток деления
+// приходится на последний платёж.
+type ScheduleInstallmentsUseCase struct {
+	orderRepo OrderRepository
+}
+
+// NewScheduleInstallmentsUseCase создаёт новый use-case
+func NewScheduleInstallmentsUseCase(orderRepo OrderRepository) *ScheduleInstallmentsUseCase {
+	return &ScheduleInstallmentsUseCase{orderRepo: orderRepo}
+}
+
+// Execute назначает заказу график рассрочки и возвращает обновлённый заказ
+func (uc *ScheduleInstallmentsUseCase) Execute(ctx context.Context, cmd ScheduleInstallmentsCommand) (OrderView, error) {
+	interval := domain.BillingMonthly
+	if cmd.Interval != "" {
+		var err error
+		if interval, err = domain.ParseBillingInterval(cmd.Interval); err != nil {
+			return OrderView{}, err
+		}
+	}
+	if cmd.Count < 2 || cmd.Count > domain.MaxInstallments {
+		return OrderView{}, fmt.Errorf("%w: expected 2 to %d payments, got %d", domain.ErrInvalidInstallmentPlan, domain.MaxInstallments, cmd.Count)
+	}
+	dueDates := make([]time.Time, cmd.Count)
+	for i := range dueDates {
+		dueDates[i] = interval.After(cmd.FirstDue, i)
+	}
+
+	order, err := uc.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return OrderView{}, err
+	}
+	if err := order.ScheduleInstallments(dueDates); err != nil {
+		return OrderView{}, err
+	}
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return OrderView{}, err
+	}
+	return NewOrderView(order), nil
+}
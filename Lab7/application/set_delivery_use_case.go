@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"lab7/domain"
+)
+
+// SetDeliveryCommand - адрес и способ доставки заказа
+type SetDeliveryCommand struct {
+	OrderID    string
+	Method     string // COURIER, POST или PICKUP
+	Country    string // двухбуквенный код страны
+	Region     string
+	City       string
+	PostalCode string
+	Street     string
+}
+
+// SetDeliveryUseCase - use-case выбора доставки заказа
+type SetDeliveryUseCase struct {
+	orderRepo OrderRepository
+	shipping  domain.ShippingPolicy
+}
+
+// SetDeliveryOption - необязательная зависимость use-case выбора доставки
+type SetDeliveryOption func(*SetDeliveryUseCase)
+
+// QuoteWith включает расчёт стоимости доставки сразу после выбора адреса
+func QuoteWith(policy domain.ShippingPolicy) SetDeliveryOption {
+	return func(uc *SetDeliveryUseCase) {
+		uc.shipping = policy
+	}
+}
+
+// NewSetDeliveryUseCase создаёт новый use-case
+func NewSetDeliveryUseCase(orderRepo OrderRepository, opts ...SetDeliveryOption) *SetDeliveryUseCase {
+	uc := &SetDeliveryUseCase{orderRepo: orderRepo}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute задаёт доставку заказа и возвращает обновлённый заказ
+func (uc *SetDeliveryUseCase) Execute(ctx context.Context, cmd SetDeliveryCommand) (OrderView, error) {
+	method, err := domain.ParseDeliveryMethod(cmd.Method)
+	if err != nil {
+		return OrderView{}, err
+	}
+	address, err := domain.NewAddress(cmd.Country, cmd.Region, cmd.City, cmd.PostalCode, cmd.Street)
+	if err != nil {
+		return OrderView{}, err
+	}
+	delivery, err := domain.NewDelivery(address, method)
+	if err != nil {
+		return OrderView{}, err
+	}
+
+	order, err := uc.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return OrderView{}, err
+	}
+	if err := order.SetDelivery(delivery); err != nil {
+		return OrderView{}, err
+	}
+	if uc.shipping != nil {
+		if err := order.QuoteShipping(uc.shipping); err != nil {
+			return OrderView{}, err
+		}
+	}
+
+	if err := uc.orderRepo.Save(ctx, order); err != nil {
+		return OrderView{}, err
+	}
+	return NewOrderView(order), nil
+}
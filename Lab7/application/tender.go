@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"time"
+)
+
+// tender проводит части оплаты заказа: подарочные карты списываются через
+// GiftCardRepository, остальные способы - через PaymentGateway
+type tender struct {
+	gateway   PaymentGateway
+	giftCards GiftCardRepository
+	now       func() time.Time
+}
+
+// chargeAll списывает части оплаты по очереди; если часть не прошла,
+// уже списанные части возвращаются
+func (t tender) chargeAll(ctx context.Context, orderID string, legs []domain.PaymentLeg) error {
+	for i, leg := range legs {
+		if err := t.charge(ctx, orderID, leg); err != nil {
+			// Откат выполняется и при отменённом контексте запроса
+			if rollbackErr := t.refundAll(context.WithoutCancel(ctx), orderID, legs[:i]); rollbackErr != nil {
+				err = fmt.Errorf("%w (rollback failed: %w)", err, rollbackErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// refundAll возвращает части оплаты в обратном порядке. Ошибка одной
+// части не останавливает возврат остальных.
+func (t tender) refundAll(ctx context.Context, orderID string, legs []domain.PaymentLeg) error {
+	var errs []error
+	for i := len(legs) - 1; i >= 0; i-- {
+		if err := t.refund(ctx, orderID, legs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", legs[i].Method, legs[i].Amount, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// charge списывает одну часть оплаты
+func (t tender) charge(ctx context.Context, orderID string, leg domain.PaymentLeg) error {
+	if leg.Method.Kind() == domain.PaymentMethodGiftCard {
+		return t.updateGiftCard(ctx, leg.Method.Reference(), func(card *domain.GiftCard) error {
+			return card.Redeem(leg.Amount, t.now())
+		})
+	}
+	if err := t.gateway.Charge(ctx, orderID, leg.Method, leg.Amount); err != nil {
+		return fmt.Errorf("%w: %w", ErrPaymentFailed, err)
+	}
+	return nil
+}
+
+// refund возвращает одну часть оплаты
+func (t tender) refund(ctx context.Context, orderID string, leg domain.PaymentLeg) error {
+	if leg.Method.Kind() == domain.PaymentMethodGiftCard {
+		return t.updateGiftCard(ctx, leg.Method.Reference(), func(card *domain.GiftCard) error {
+			return card.Credit(leg.Amount)
+		})
+	}
+	return t.gateway.Refund(ctx, orderID, leg.Method, leg.Amount)
+}
+
+// updateGiftCard атомарно изменяет подарочную карту
+func (t tender) updateGiftCard(ctx context.Context, cardID string, fn func(card *domain.GiftCard) error) error {
+	if t.giftCards == nil {
+		return ErrGiftCardsNotAccepted
+	}
+	return t.giftCards.Update(ctx, cardID, fn)
+}
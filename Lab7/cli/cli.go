@@ -0,0 +1,396 @@
+// Package cli реализует утилиту командной строки ordersctl.
+// Утилита работает только через use-case слоя приложения и никогда
+// не обращается к доменным объектам напрямую.
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lab7/application"
+	"lab7/infrastructure"
+	"lab7/infrastructure/logging"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usageText = `Usage: ordersctl [global flags] <command> [arguments]
+
+Commands:
+  create   <order-id> [-customer ID]          create an empty order
+  add-line <order-id> -product ID -price N -currency CUR [-qty N]
+                                              add a line (price in minor units;
+                                              with -catalog the price is taken
+                                              from the catalog)
+  pay      <order-id>                         pay an order
+  show     <order-id>                         show an order
+  list     [-status S1,S2] [-currency CUR] [-product ID] [-customer ID]
+           [-min-total N] [-max-total N] [-created-from T] [-created-to T]
+           [-sort id|created_at|total] [-desc] [-limit N] [-cursor C]
+                                              list orders page by page
+  refund   <order-id>                         refund a paid order
+
+Global flags:
+`
+
+// errUsage - ошибка разбора аргументов командной строки
+var errUsage = errors.New("usage error")
+
+// config - глобальные настройки запуска
+type config struct {
+	dataPath      string
+	catalogPath   string
+	gateway       string
+	declineReason string
+	output        string
+	logLevel      string
+}
+
+// app - собранные зависимости для выполнения команды
+type app struct {
+	out       printer
+	createUC  *application.CreateOrderUseCase
+	addLineUC *application.AddOrderLineUseCase
+	payUC     application.PayOrderExecutor
+	getUC     *application.GetOrderUseCase
+	listUC    *application.ListOrdersUseCase
+	refundUC  application.RefundOrderExecutor
+}
+
+// Run выполняет ordersctl с аргументами args и возвращает код завершения
+func Run(args []string, stdout, stderr io.Writer) int {
+	ctx := application.WithRequestID(context.Background(), newRequestID())
+	err := run(ctx, args, stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ordersctl: %v\n", err)
+	}
+	return ExitCode(err)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var cfg config
+	fs := flag.NewFlagSet("ordersctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.dataPath, "data", "orders.json", "path to the orders file")
+	fs.StringVar(&cfg.catalogPath, "catalog", "", "path to the product catalog file; if set, line prices come from it")
+	fs.StringVar(&cfg.gateway, "gateway", "approve", "payment gateway: approve or decline")
+	fs.StringVar(&cfg.declineReason, "decline-reason", "payment declined", "error returned by the decline gateway")
+	fs.StringVar(&cfg.output, "output", "table", "output format: table or json")
+	fs.StringVar(&cfg.logLevel, "log-level", "off", "log to stderr at level: debug, info, warn, error or off")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usageText)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("%w: no command given", errUsage)
+	}
+
+	a, err := newApp(cfg, stdout, stderr)
+	if err != nil {
+		return err
+	}
+
+	command, rest := fs.Arg(0), fs.Args()[1:]
+	switch command {
+	case "create":
+		return a.create(ctx, rest)
+	case "add-line":
+		return a.addLine(ctx, rest)
+	case "pay":
+		return a.pay(ctx, rest)
+	case "show":
+		return a.show(ctx, rest)
+	case "list":
+		return a.list(ctx, rest)
+	case "refund":
+		return a.refund(ctx, rest)
+	default:
+		fs.Usage()
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+}
+
+// newApp собирает инфраструктуру и use-case по настройкам
+func newApp(cfg config, stdout, stderr io.Writer) (*app, error) {
+	out, err := newPrinter(cfg.output, stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := newLogger(cfg.logLevel, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	fileRepo, err := infrastructure.NewFileOrderRepository(cfg.dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lineOpts []application.AddOrderLineOption
+	if cfg.catalogPath != "" {
+		catalog, err := infrastructure.LoadProductCatalog(cfg.catalogPath)
+		if err != nil {
+			return nil, err
+		}
+		lineOpts = append(lineOpts, application.WithCatalog(catalog))
+	}
+
+	fakeGateway := infrastructure.NewFakePaymentGateway()
+	switch cfg.gateway {
+	case "approve":
+	case "decline":
+		fakeGateway.SetShouldFail(true, cfg.declineReason)
+	default:
+		return nil, fmt.Errorf("%w: unknown gateway %q", errUsage, cfg.gateway)
+	}
+
+	repo := logging.NewOrderRepository(fileRepo, logger)
+	gateway := logging.NewPaymentGateway(fakeGateway, logger)
+
+	return &app{
+		out:       out,
+		createUC:  application.NewCreateOrderUseCase(repo),
+		addLineUC: application.NewAddOrderLineUseCase(repo, lineOpts...),
+		payUC:     logging.NewPayOrderUseCase(application.NewPayOrderUseCase(repo, gateway), logger),
+		getUC:     application.NewGetOrderUseCase(repo),
+		listUC:    application.NewListOrdersUseCase(repo),
+		refundUC:  logging.NewRefundOrderUseCase(application.NewRefundOrderUseCase(repo, gateway), logger),
+	}, nil
+}
+
+// newLogger создаёт JSON-логгер в stderr со скрытием чувствительных данных
+func newLogger(level string, stderr io.Writer) (*slog.Logger, error) {
+	if level == "off" {
+		return slog.New(slog.DiscardHandler), nil
+	}
+
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("%w: unknown log level %q", errUsage, level)
+	}
+	handler := slog.NewJSONHandler(stderr, &slog.HandlerOptions{Level: lvl})
+	return slog.New(logging.NewRedactingHandler(handler)), nil
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func (a *app) create(ctx context.Context, args []string) error {
+	var cmd application.CreateOrderCommand
+	orderID, err := parseOrderArgs("create", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&cmd.CustomerID, "customer", "", "customer ID")
+	})
+	if err != nil {
+		return err
+	}
+	cmd.OrderID = orderID
+
+	view, err := a.createUC.Execute(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return a.out.order(view)
+}
+
+func (a *app) addLine(ctx context.Context, args []string) error {
+	cmd := application.AddOrderLineCommand{Quantity: 1}
+	orderID, err := parseOrderArgs("add-line", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&cmd.ProductID, "product", "", "product ID")
+		fs.Int64Var(&cmd.UnitPrice, "price", 0, "unit price in minor units")
+		fs.StringVar(&cmd.Currency, "currency", "", "currency code")
+		fs.IntVar(&cmd.Quantity, "qty", 1, "quantity")
+	})
+	if err != nil {
+		return err
+	}
+	cmd.OrderID = orderID
+
+	view, err := a.addLineUC.Execute(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return a.out.order(view)
+}
+
+func (a *app) pay(ctx context.Context, args []string) error {
+	orderID, err := parseOrderArgs("pay", args, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.payUC.Execute(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return a.printResult(ctx, orderID, result.Success, result.Message)
+}
+
+func (a *app) refund(ctx context.Context, args []string) error {
+	orderID, err := parseOrderArgs("refund", args, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.refundUC.Execute(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return a.printResult(ctx, orderID, result.Success, result.Message)
+}
+
+func (a *app) show(ctx context.Context, args []string) error {
+	orderID, err := parseOrderArgs("show", args, nil)
+	if err != nil {
+		return err
+	}
+
+	view, err := a.getUC.Execute(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return a.out.order(view)
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	var (
+		req                    application.ListOrdersRequest
+		statuses               string
+		minTotal, maxTotal     optionalInt64
+		createdFrom, createdTo timeFlag
+	)
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&statuses, "status", "", "comma-separated statuses")
+	fs.StringVar(&req.Currency, "currency", "", "total currency")
+	fs.StringVar(&req.ProductID, "product", "", "orders containing this product")
+	fs.StringVar(&req.CustomerID, "customer", "", "orders of this customer")
+	fs.Var(&minTotal, "min-total", "minimal total in minor units")
+	fs.Var(&maxTotal, "max-total", "maximal total in minor units")
+	fs.Var(&createdFrom, "created-from", "created at or after (RFC 3339)")
+	fs.Var(&createdTo, "created-to", "created before (RFC 3339)")
+	fs.StringVar(&req.SortBy, "sort", "id", "sort by: id, created_at or total")
+	fs.BoolVar(&req.Descending, "desc", false, "sort in descending order")
+	fs.IntVar(&req.Limit, "limit", application.DefaultOrderQueryLimit, "page size")
+	fs.StringVar(&req.Cursor, "cursor", "", "cursor of the next page")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: list: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: list: unexpected arguments %v", errUsage, fs.Args())
+	}
+
+	if statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			req.Statuses = append(req.Statuses, strings.ToUpper(strings.TrimSpace(status)))
+		}
+	}
+	req.MinTotal, req.MaxTotal = minTotal.value, maxTotal.value
+	req.CreatedFrom, req.CreatedTo = createdFrom.value, createdTo.value
+
+	result, err := a.listUC.Execute(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.out.orders(result)
+}
+
+// printResult печатает результат оплаты или возврата вместе с заказом
+func (a *app) printResult(ctx context.Context, orderID string, success bool, message string) error {
+	view, err := a.getUC.Execute(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return a.out.result(success, message, view)
+}
+
+// parseOrderArgs разбирает аргументы вида "<order-id> [flags]" или
+// "[flags] <order-id>"; defineFlags может добавить флаги команды
+func parseOrderArgs(command string, args []string, defineFlags func(fs *flag.FlagSet)) (string, error) {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if defineFlags != nil {
+		defineFlags(fs)
+	}
+
+	var orderID string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		orderID, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", errUsage, command, err)
+	}
+	switch {
+	case orderID == "" && fs.NArg() == 1:
+		orderID = fs.Arg(0)
+	case fs.NArg() > 0:
+		return "", fmt.Errorf("%w: %s: unexpected arguments %v", errUsage, command, fs.Args())
+	}
+	if orderID == "" {
+		return "", fmt.Errorf("%w: %s: order ID is required", errUsage, command)
+	}
+	return orderID, nil
+}
+
+// optionalInt64 - флаг с целым значением, которое может быть не задано
+type optionalInt64 struct {
+	value *int64
+}
+
+func (f *optionalInt64) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*f.value, 10)
+}
+
+func (f *optionalInt64) Set(s string) error {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	f.value = &v
+	return nil
+}
+
+// timeFlag - флаг со временем в формате RFC 3339
+type timeFlag struct {
+	value time.Time
+}
+
+func (f *timeFlag) String() string {
+	if f.value.IsZero() {
+		return ""
+	}
+	return f.value.Format(time.RFC3339)
+}
+
+func (f *timeFlag) Set(s string) error {
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	f.value = v
+	return nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"lab7/application"
+)
+
+// Коды завершения ordersctl
+const (
+	// ExitOK - команда выполнена успешно
+	ExitOK = 0
+	// ExitFailure - непредвиденная ошибка (ввод-вывод, повреждённый файл и т.п.)
+	ExitFailure = 1
+	// ExitUsage - неверные аргументы командной строки
+	ExitUsage = 2
+	// ExitNotFound - заказ, товар или клиент не найден
+	ExitNotFound = 3
+	// ExitConflict - заказ с таким идентификатором уже существует
+	ExitConflict = 4
+	// ExitInvalidInput - некорректные данные (сумма, валюта, количество)
+	ExitInvalidInput = 5
+	// ExitRuleViolation - операция нарушает бизнес-правила заказа
+	ExitRuleViolation = 6
+	// ExitPaymentFailed - платёжный шлюз отклонил операцию
+	ExitPaymentFailed = 7
+	// ExitAccessDenied - пользователь не указан или операция ему не разрешена
+	ExitAccessDenied = 8
+)
+
+// exitCodes - коды завершения по кодам ошибок application.ErrorCode.
+// Коды, которых нет в таблице, завершаются с ExitFailure.
+var exitCodes = map[string]int{
+	application.ErrorCodeNotFound:       ExitNotFound,
+	application.ErrorCodeNoCustomer:     ExitNotFound,
+	application.ErrorCodeNoProduct:      ExitNotFound,
+	application.ErrorCodeNoGiftCard:     ExitNotFound,
+	application.ErrorCodeNoSubscription: ExitNotFound,
+	application.ErrorCodeUnknownTenant:  ExitNotFound,
+
+	application.ErrorCodeUnauthenticated: ExitAccessDenied,
+	application.ErrorCodeForbidden:       ExitAccessDenied,
+
+	application.ErrorCodeAlreadyExists: ExitConflict,
+
+	application.ErrorCodePaymentFailed: ExitPaymentFailed,
+	application.ErrorCodeRefundFailed:  ExitPaymentFailed,
+	application.ErrorCodeGatewayDown:   ExitPaymentFailed,
+	application.ErrorCodeDeclined:      ExitPaymentFailed,
+
+	application.ErrorCodeInvalidInput:     ExitInvalidInput,
+	application.ErrorCodeInvalidQuery:     ExitInvalidInput,
+	application.ErrorCodeNoTenant:         ExitInvalidInput,
+	application.ErrorCodeCurrencyMismatch: ExitInvalidInput,
+	application.ErrorCodeSplitMismatch:    ExitInvalidInput,
+
+	application.ErrorCodeEmptyOrder:         ExitRuleViolation,
+	application.ErrorCodeAlreadyPaid:        ExitRuleViolation,
+	application.ErrorCodeNotModifiable:      ExitRuleViolation,
+	application.ErrorCodeNotPaid:            ExitRuleViolation,
+	application.ErrorCodeRefunded:           ExitRuleViolation,
+	application.ErrorCodeExpired:            ExitRuleViolation,
+	application.ErrorCodeNotOverdue:         ExitRuleViolation,
+	application.ErrorCodeInstallments:       ExitRuleViolation,
+	application.ErrorCodeNoInstallments:     ExitRuleViolation,
+	application.ErrorCodeProductInactive:    ExitRuleViolation,
+	application.ErrorCodeCustomerBlocked:    ExitRuleViolation,
+	application.ErrorCodeSubscriptionClosed: ExitRuleViolation,
+	application.ErrorCodeNoShipping:         ExitRuleViolation,
+	application.ErrorCodeNoDelivery:         ExitRuleViolation,
+	application.ErrorCodeNotQuoted:          ExitRuleViolation,
+	application.ErrorCodeNoGiftCards:        ExitRuleViolation,
+	application.ErrorCodeGiftCardExpired:    ExitRuleViolation,
+	application.ErrorCodeNoBalance:          ExitRuleViolation,
+	application.ErrorCodeOutOfStock:         ExitRuleViolation,
+	application.ErrorCodeUnsettled:          ExitRuleViolation,
+}
+
+// ExitCode возвращает код завершения, соответствующий типу ошибки. Тип
+// определяется через application.ErrorCode, поэтому новая ошибка с кодом
+// из таблицы exitCodes сразу получает свой код завершения.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errUsage):
+		return ExitUsage
+	}
+	if code, ok := exitCodes[application.ErrorCode(err)]; ok {
+		return code
+	}
+	return ExitFailure
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"lab7/application"
+	"text/tabwriter"
+	"time"
+)
+
+// printer - формат вывода результатов команд
+type printer interface {
+	order(view application.OrderView) error
+	orders(result application.ListOrdersResult) error
+	result(success bool, message string, view application.OrderView) error
+}
+
+// newPrinter создаёт printer для формата table или json
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return tablePrinter{w: w}, nil
+	case "json":
+		return jsonPrinter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown output format %q", errUsage, format)
+	}
+}
+
+// jsonPrinter - вывод в формате JSON
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p jsonPrinter) order(view application.OrderView) error {
+	return p.encode(view)
+}
+
+func (p jsonPrinter) orders(result application.ListOrdersResult) error {
+	return p.encode(result)
+}
+
+func (p jsonPrinter) result(success bool, message string, view application.OrderView) error {
+	return p.encode(struct {
+		Success bool                  `json:"success"`
+		Message string                `json:"message"`
+		Order   application.OrderView `json:"order"`
+	}{success, message, view})
+}
+
+func (p jsonPrinter) encode(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// tablePrinter - вывод в виде выровненной таблицы
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p tablePrinter) order(view application.OrderView) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Order:\t%s\n", view.ID)
+	if view.CustomerID != "" {
+		fmt.Fprintf(tw, "Customer:\t%s\n", view.CustomerID)
+	}
+	fmt.Fprintf(tw, "Status:\t%s\n", view.Status)
+	if view.Delivery != nil {
+		d := view.Delivery
+		fmt.Fprintf(tw, "Delivery:\t%s, %s %s, %s\n", d.Method, d.Country, d.City, d.Street)
+		fmt.Fprintf(tw, "Shipping:\t%s %s\n", formatMinor(view.Shipping), view.Currency)
+	}
+	fmt.Fprintf(tw, "Total:\t%s\n", view.TotalString())
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(view.Lines) == 0 {
+		_, err := fmt.Fprintln(p.w, "\nNo lines")
+		return err
+	}
+
+	fmt.Fprintln(p.w)
+	tw = tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRODUCT\tNAME\tQTY\tUNIT PRICE\tTOTAL\tCURRENCY")
+	for _, line := range view.Lines {
+		name := line.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			line.ProductID, name, line.Quantity,
+			formatMinor(line.UnitPrice), formatMinor(line.Total), line.Currency)
+	}
+	return tw.Flush()
+}
+
+func (p tablePrinter) orders(result application.ListOrdersResult) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tCREATED\tLINES\tTOTAL")
+	for _, view := range result.Orders {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n",
+			view.ID, view.Status, view.CreatedAt.Format(time.RFC3339), len(view.Lines), view.TotalString())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if result.NextCursor != "" {
+		_, err := fmt.Fprintf(p.w, "\nNext page: -cursor %s\n", result.NextCursor)
+		return err
+	}
+	return nil
+}
+
+func (p tablePrinter) result(success bool, message string, view application.OrderView) error {
+	fmt.Fprintf(p.w, "%s\n\n", message)
+	return p.order(view)
+}
+
+// formatMinor форматирует сумму в минимальных единицах как "150.00"
+func formatMinor(amount int64) string {
+	return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
@@ -0,0 +1,17 @@
+// Команда ordersctl - утилита командной строки для работы с заказами.
+//
+// Пример:
+//
+//	ordersctl -data orders.json create order-1
+//	ordersctl -data orders.json add-line order-1 -product laptop -price 15000 -currency RUB
+//	ordersctl -data orders.json -output json pay order-1
+package main
+
+import (
+	"lab7/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ContactInfo - контактные данные клиента (Value Object)
+type ContactInfo struct {
+	name  string
+	email string
+	phone string
+}
+
+// NewContactInfo создаёт контактные данные. Имя обязательно, кроме него
+// нужен хотя бы один способ связи: email или телефон.
+func NewContactInfo(name, email, phone string) (ContactInfo, error) {
+	name, email, phone = strings.TrimSpace(name), strings.TrimSpace(email), strings.TrimSpace(phone)
+	if name == "" {
+		return ContactInfo{}, fmt.Errorf("%w: name is required", ErrInvalidContact)
+	}
+	if email == "" && phone == "" {
+		return ContactInfo{}, fmt.Errorf("%w: email or phone is required", ErrInvalidContact)
+	}
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return ContactInfo{}, fmt.Errorf("%w: invalid email %q", ErrInvalidContact, email)
+		}
+	}
+	return ContactInfo{name: name, email: email, phone: phone}, nil
+}
+
+// Name возвращает имя клиента
+func (c ContactInfo) Name() string {
+	return c.name
+}
+
+// Email возвращает адрес электронной почты
+func (c ContactInfo) Email() string {
+	return c.email
+}
+
+// Phone возвращает номер телефона
+func (c ContactInfo) Phone() string {
+	return c.phone
+}
+
+// Customer - агрегат клиента
+type Customer struct {
+	id      string
+	contact ContactInfo
+	status  CustomerStatus
+}
+
+// CustomerSnapshot - полное состояние клиента для сохранения и восстановления
+type CustomerSnapshot struct {
+	ID      string
+	Contact ContactInfo
+	Status  CustomerStatus
+}
+
+// NewCustomer создаёт активного клиента
+func NewCustomer(id string, contact ContactInfo) (*Customer, error) {
+	if id == "" {
+		return nil, ErrEmptyCustomerID
+	}
+	return &Customer{
+		id:      id,
+		contact: contact,
+		status:  CustomerStatusActive,
+	}, nil
+}
+
+// RestoreCustomer восстанавливает клиента из снимка состояния
+func RestoreCustomer(snapshot CustomerSnapshot) *Customer {
+	return &Customer{
+		id:      snapshot.ID,
+		contact: snapshot.Contact,
+		status:  snapshot.Status,
+	}
+}
+
+// Snapshot возвращает снимок состояния клиента
+func (c *Customer) Snapshot() CustomerSnapshot {
+	return CustomerSnapshot{
+		ID:      c.id,
+		Contact: c.contact,
+		Status:  c.status,
+	}
+}
+
+// ID возвращает идентификатор клиента
+func (c *Customer) ID() string {
+	return c.id
+}
+
+// Contact возвращает контактные данные клиента
+func (c *Customer) Contact() ContactInfo {
+	return c.contact
+}
+
+// Status возвращает статус клиента
+func (c *Customer) Status() CustomerStatus {
+	return c.status
+}
+
+// IsBlocked проверяет, заблокирован ли клиент
+func (c *Customer) IsBlocked() bool {
+	return c.status == CustomerStatusBlocked
+}
+
+// UpdateContact заменяет контактные данные клиента
+func (c *Customer) UpdateContact(contact ContactInfo) {
+	c.contact = contact
+}
+
+// Block блокирует клиента
+func (c *Customer) Block() {
+	c.status = CustomerStatusBlocked
+}
+
+// Activate снимает блокировку клиента
+func (c *Customer) Activate() {
+	c.status = CustomerStatusActive
+}
+
+// CanPay проверяет, может ли клиент оплачивать заказы
+func (c *Customer) CanPay() error {
+	// Инвариант: заблокированный клиент не может оплачивать заказы
+	if c.IsBlocked() {
+		return fmt.Errorf("%w: %s", ErrCustomerBlocked, c.id)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "fmt"
+
+// CustomerStatus - статус клиента
+type CustomerStatus string
+
+const (
+	// CustomerStatusActive - клиент может оформлять и оплачивать заказы
+	CustomerStatusActive CustomerStatus = "ACTIVE"
+	// CustomerStatusBlocked - клиент заблокирован, оплата запрещена
+	CustomerStatusBlocked CustomerStatus = "BLOCKED"
+)
+
+// String возвращает строковое представление статуса
+func (s CustomerStatus) String() string {
+	return string(s)
+}
+
+// ParseCustomerStatus разбирает строковое представление статуса
+func ParseCustomerStatus(s string) (CustomerStatus, error) {
+	switch status := CustomerStatus(s); status {
+	case CustomerStatusActive, CustomerStatusBlocked:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownCustomerStatus, s)
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeliveryMethod - способ доставки заказа
+type DeliveryMethod string
+
+const (
+	// DeliveryMethodCourier - курьерская доставка до двери
+	DeliveryMethodCourier DeliveryMethod = "COURIER"
+	// DeliveryMethodPost - доставка почтой
+	DeliveryMethodPost DeliveryMethod = "POST"
+	// DeliveryMethodPickup - самовывоз из пункта выдачи
+	DeliveryMethodPickup DeliveryMethod = "PICKUP"
+)
+
+// String возвращает строковое представление способа доставки
+func (m DeliveryMethod) String() string {
+	return string(m)
+}
+
+// ParseDeliveryMethod разбирает строковое представление способа доставки
+func ParseDeliveryMethod(s string) (DeliveryMethod, error) {
+	switch method := DeliveryMethod(s); method {
+	case DeliveryMethodCourier, DeliveryMethodPost, DeliveryMethodPickup:
+		return method, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDeliveryMethod, s)
+	}
+}
+
+// Address - адрес доставки (Value Object). Страна хранится как
+// двухбуквенный код в верхнем регистре, регион - как есть.
+type Address struct {
+	country    string
+	region     string
+	city       string
+	postalCode string
+	street     string
+}
+
+// NewAddress создаёт адрес доставки. Страна, город и улица обязательны.
+func NewAddress(country, region, city, postalCode, street string) (Address, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	region, city = strings.TrimSpace(region), strings.TrimSpace(city)
+	postalCode, street = strings.TrimSpace(postalCode), strings.TrimSpace(street)
+	if len(country) != 2 {
+		return Address{}, fmt.Errorf("%w: country must be a two-letter code, got %q", ErrInvalidAddress, country)
+	}
+	if city == "" {
+		return Address{}, fmt.Errorf("%w: city is required", ErrInvalidAddress)
+	}
+	if street == "" {
+		return Address{}, fmt.Errorf("%w: street is required", ErrInvalidAddress)
+	}
+	return Address{
+		country:    country,
+		region:     region,
+		city:       city,
+		postalCode: postalCode,
+		street:     street,
+	}, nil
+}
+
+// Country возвращает код страны
+func (a Address) Country() string {
+	return a.country
+}
+
+// Region возвращает регион; может быть пустым
+func (a Address) Region() string {
+	return a.region
+}
+
+// City возвращает город
+func (a Address) City() string {
+	return a.city
+}
+
+// PostalCode возвращает почтовый индекс; может быть пустым
+func (a Address) PostalCode() string {
+	return a.postalCode
+}
+
+// Street возвращает улицу, дом и квартиру
+func (a Address) Street() string {
+	return a.street
+}
+
+// IsZero проверяет, что адрес не задан
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Delivery - адрес и способ доставки заказа (Value Object)
+type Delivery struct {
+	address Address
+	method  DeliveryMethod
+}
+
+// NewDelivery создаёт параметры доставки. Для самовывоза адрес - это
+// адрес пункта выдачи.
+func NewDelivery(address Address, method DeliveryMethod) (Delivery, error) {
+	if address.IsZero() {
+		return Delivery{}, fmt.Errorf("%w: address is required", ErrInvalidAddress)
+	}
+	if _, err := ParseDeliveryMethod(string(method)); err != nil {
+		return Delivery{}, err
+	}
+	return Delivery{address: address, method: method}, nil
+}
+
+// Address возвращает адрес доставки
+func (d Delivery) Address() Address {
+	return d.address
+}
+
+// Method возвращает способ доставки
+func (d Delivery) Method() DeliveryMethod {
+	return d.method
+}
+
+// IsZero проверяет, что доставка не задана
+func (d Delivery) IsZero() bool {
+	return d == Delivery{}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import "errors"
+
+// Ошибки доменного слоя. Сообщения совпадают с прежними текстами,
+// а вызывающий код может различать их через errors.Is.
+var (
+	// ErrNegativeAmount - отрицательная денежная сумма
+	ErrNegativeAmount = errors.New("amount cannot be negative")
+	// ErrEmptyCurrency - не указана валюта
+	ErrEmptyCurrency = errors.New("currency cannot be empty")
+	// ErrCurrencyMismatch - операция над суммами в разных валютах
+	ErrCurrencyMismatch = errors.New("cannot add different currencies")
+
+	// ErrEmptyProductID - не указан идентификатор продукта
+	ErrEmptyProductID = errors.New("productID cannot be empty")
+	// ErrNonPositiveQuantity - количество товара должно быть положительным
+	ErrNonPositiveQuantity = errors.New("quantity must be positive")
+	// ErrEmptyProductName - не указано название товара
+	ErrEmptyProductName = errors.New("product name cannot be empty")
+	// ErrNegativeWeight - отрицательный вес товара
+	ErrNegativeWeight = errors.New("weight cannot be negative")
+	// ErrUnknownVATRate - неизвестная ставка НДС
+	ErrUnknownVATRate = errors.New("unknown VAT rate")
+	// ErrProductInactive - товар снят с продажи
+	ErrProductInactive = errors.New("product is not available for sale")
+
+	// ErrUnknownOrderStatus - неизвестный статус заказа
+	ErrUnknownOrderStatus = errors.New("unknown order status")
+	// ErrOrderHasNoLines - в заказе нет строк
+	ErrOrderHasNoLines = errors.New("order has no lines")
+	// ErrEmptyOrder - попытка оплатить пустой заказ
+	ErrEmptyOrder = errors.New("cannot pay empty order")
+	// ErrOrderAlreadyPaid - попытка повторной оплаты
+	ErrOrderAlreadyPaid = errors.New("order is already paid")
+	// ErrOrderNotModifiable - попытка изменить оплаченный заказ
+	ErrOrderNotModifiable = errors.New("cannot modify paid order")
+	// ErrOrderNotPaid - попытка вернуть средства за неоплаченный заказ
+	ErrOrderNotPaid = errors.New("order is not paid")
+	// ErrOrderRefunded - операция над заказом, по которому уже сделан возврат
+	ErrOrderRefunded = errors.New("order is already refunded")
+	// ErrOrderExpired - срок оплаты заказа истёк
+	ErrOrderExpired = errors.New("order payment deadline has passed")
+	// ErrOrderNotOverdue - попытка признать просроченным заказ, срок оплаты которого не истёк
+	ErrOrderNotOverdue = errors.New("order is not overdue")
+
+	// ErrInvalidInstallmentPlan - некорректный график рассрочки
+	ErrInvalidInstallmentPlan = errors.New("invalid installment plan")
+	// ErrInstallmentsNotScheduled - для заказа не задан график рассрочки
+	ErrInstallmentsNotScheduled = errors.New("installments are not scheduled")
+	// ErrInstallmentsScheduled - заказ с графиком рассрочки оплачивается по графику
+	ErrInstallmentsScheduled = errors.New("order is paid by installments")
+
+	// ErrInvalidAddress - неполный адрес доставки
+	ErrInvalidAddress = errors.New("invalid delivery address")
+	// ErrUnknownDeliveryMethod - неизвестный способ доставки
+	ErrUnknownDeliveryMethod = errors.New("unknown delivery method")
+	// ErrDeliveryNotSet - для заказа не задана доставка
+	ErrDeliveryNotSet = errors.New("delivery is not set")
+	// ErrShippingNotQuoted - стоимость доставки не рассчитана
+	ErrShippingNotQuoted = errors.New("shipping cost is not quoted")
+	// ErrShippingUnavailable - доставка по адресу невозможна
+	ErrShippingUnavailable = errors.New("shipping is not available")
+
+	// ErrInvalidPaymentMethod - неверные реквизиты способа оплаты
+	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+	// ErrUnknownPaymentMethod - неизвестный вид оплаты
+	ErrUnknownPaymentMethod = errors.New("unknown payment method")
+	// ErrSplitMismatch - части оплаты не равны итоговой сумме заказа
+	ErrSplitMismatch = errors.New("payment split does not match order total")
+
+	// ErrEmptyGiftCardID - пустой идентификатор подарочной карты
+	ErrEmptyGiftCardID = errors.New("gift card ID cannot be empty")
+	// ErrGiftCardExpired - срок действия подарочной карты истёк
+	ErrGiftCardExpired = errors.New("gift card expired")
+	// ErrInsufficientBalance - на подарочной карте недостаточно средств
+	ErrInsufficientBalance = errors.New("insufficient gift card balance")
+
+	// ErrEmptySubscriptionID - пустой идентификатор подписки
+	ErrEmptySubscriptionID = errors.New("subscription ID cannot be empty")
+	// ErrUnknownBillingInterval - неизвестный период списаний
+	ErrUnknownBillingInterval = errors.New("unknown billing interval")
+	// ErrUnknownSubscriptionStatus - неизвестный статус подписки
+	ErrUnknownSubscriptionStatus = errors.New("unknown subscription status")
+	// ErrSubscriptionCancelled - операция над отменённой подпиской
+	ErrSubscriptionCancelled = errors.New("subscription is cancelled")
+	// ErrSubscriptionSuspended - списание по приостановленной подписке
+	ErrSubscriptionSuspended = errors.New("subscription is suspended")
+	// ErrSubscriptionNotSuspended - попытка возобновить неприостановленную подписку
+	ErrSubscriptionNotSuspended = errors.New("subscription is not suspended")
+
+	// ErrEmptyCustomerID - не указан идентификатор клиента
+	ErrEmptyCustomerID = errors.New("customer ID cannot be empty")
+	// ErrInvalidContact - некорректные контактные данные клиента
+	ErrInvalidContact = errors.New("invalid contact info")
+	// ErrUnknownCustomerStatus - неизвестный статус клиента
+	ErrUnknownCustomerStatus = errors.New("unknown customer status")
+	// ErrCustomerBlocked - операция запрещена заблокированному клиенту
+	ErrCustomerBlocked = errors.New("customer is blocked")
+)
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// GiftCard - агрегат подарочной карты. Баланс уменьшается при оплате
+// заказов и не может стать отрицательным.
+type GiftCard struct {
+	id        string
+	balance   Money
+	expiresAt time.Time // нулевое значение - карта бессрочная
+}
+
+// GiftCardSnapshot - полное состояние подарочной карты для сохранения и восстановления
+type GiftCardSnapshot struct {
+	ID        string
+	Balance   Money
+	ExpiresAt time.Time
+}
+
+// NewGiftCard создаёт подарочную карту с начальным балансом balance.
+// При нулевом expiresAt карта действует бессрочно.
+func NewGiftCard(id string, balance Money, expiresAt time.Time) (*GiftCard, error) {
+	if id == "" {
+		return nil, ErrEmptyGiftCardID
+	}
+	if balance.Currency() == "" {
+		return nil, ErrEmptyCurrency
+	}
+	return &GiftCard{id: id, balance: balance, expiresAt: expiresAt}, nil
+}
+
+// RestoreGiftCard восстанавливает подарочную карту из снимка состояния
+func RestoreGiftCard(snapshot GiftCardSnapshot) *GiftCard {
+	return &GiftCard{
+		id:        snapshot.ID,
+		balance:   snapshot.Balance,
+		expiresAt: snapshot.ExpiresAt,
+	}
+}
+
+// Snapshot возвращает снимок состояния карты
+func (g *GiftCard) Snapshot() GiftCardSnapshot {
+	return GiftCardSnapshot{
+		ID:        g.id,
+		Balance:   g.balance,
+		ExpiresAt: g.expiresAt,
+	}
+}
+
+// ID возвращает идентификатор карты
+func (g *GiftCard) ID() string {
+	return g.id
+}
+
+// Balance возвращает остаток на карте
+func (g *GiftCard) Balance() Money {
+	return g.balance
+}
+
+// ExpiresAt возвращает срок действия карты; нулевое значение - бессрочная
+func (g *GiftCard) ExpiresAt() time.Time {
+	return g.expiresAt
+}
+
+// IsExpired проверяет, истёк ли срок действия карты на момент now
+func (g *GiftCard) IsExpired(now time.Time) bool {
+	return !g.expiresAt.IsZero() && !now.Before(g.expiresAt)
+}
+
+// Redeem списывает amount с баланса карты
+func (g *GiftCard) Redeem(amount Money, now time.Time) error {
+	// Инвариант: просроченной картой нельзя платить
+	if g.IsExpired(now) {
+		return fmt.Errorf("%w: card %s expired at %s", ErrGiftCardExpired, g.id, g.expiresAt.Format(time.RFC3339))
+	}
+	if amount.Currency() != g.balance.Currency() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, g.balance.Currency(), amount.Currency())
+	}
+
+	// Инвариант: баланс не может стать отрицательным
+	if amount.Amount() > g.balance.Amount() {
+		return fmt.Errorf("%w: card %s has %s, requested %s", ErrInsufficientBalance, g.id, g.balance, amount)
+	}
+
+	g.balance = Money{amount: g.balance.Amount() - amount.Amount(), currency: g.balance.Currency()}
+	return nil
+}
+
+// Credit возвращает amount на баланс карты, например при неудачной оплате.
+// Возврат возможен и после истечения срока действия.
+func (g *GiftCard) Credit(amount Money) error {
+	balance, err := g.balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	g.balance = balance
+	return nil
+}
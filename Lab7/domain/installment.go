@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxInstallments - наибольшее число платежей в рассрочке
+const MaxInstallments = 36
+
+// Installment - платёж по графику рассрочки
+type Installment struct {
+	Number int       // номер платежа, с 1
+	DueAt  time.Time // срок платежа
+	Amount Money
+	PaidAt time.Time // нулевое значение - платёж не внесён
+}
+
+// IsPaid проверяет, внесён ли платёж
+func (i Installment) IsPaid() bool {
+	return !i.PaidAt.IsZero()
+}
+
+// IsOverdue проверяет, что платёж не внесён, а срок прошёл к моменту now
+func (i Installment) IsOverdue(now time.Time) bool {
+	return !i.IsPaid() && now.After(i.DueAt)
+}
+
+// SplitAmount делит сумму total на n частей без потерь: все части,
+// кроме последней, равны total / n, последняя получает остаток деления
+func SplitAmount(total Money, n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: %d parts", ErrInvalidInstallmentPlan, n)
+	}
+	base := total.Amount() / int64(n)
+	if base == 0 {
+		return nil, fmt.Errorf("%w: %s is too small for %d parts", ErrInvalidInstallmentPlan, total, n)
+	}
+	parts := make([]Money, n)
+	for i := range parts {
+		parts[i] = Money{amount: base, currency: total.Currency()}
+	}
+	parts[n-1].amount += total.Amount() - base*int64(n)
+	return parts, nil
+}
+
+// newInstallments строит график рассрочки суммы total по срокам dueDates
+func newInstallments(total Money, dueDates []time.Time) ([]Installment, error) {
+	if len(dueDates) < 2 || len(dueDates) > MaxInstallments {
+		return nil, fmt.Errorf("%w: expected 2 to %d payments, got %d", ErrInvalidInstallmentPlan, MaxInstallments, len(dueDates))
+	}
+	for i := 1; i < len(dueDates); i++ {
+		if !dueDates[i].After(dueDates[i-1]) {
+			return nil, fmt.Errorf("%w: due dates must be increasing", ErrInvalidInstallmentPlan)
+		}
+	}
+	amounts, err := SplitAmount(total, len(dueDates))
+	if err != nil {
+		return nil, err
+	}
+	installments := make([]Installment, len(dueDates))
+	for i := range installments {
+		installments[i] = Installment{Number: i + 1, DueAt: dueDates[i], Amount: amounts[i]}
+	}
+	return installments, nil
+}
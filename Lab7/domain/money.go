@@ -1,23 +1,22 @@
 package domain
 
 import (
-	"errors"
 	"fmt"
 )
 
 // Money - value object для представления денежной суммы
 type Money struct {
-	amount   int64  // сумма в минимальных единицах (например, копейки)
+	amount   int64 // сумма в минимальных единицах (например, копейки)
 	currency string
 }
 
 // NewMoney создаёт новый объект Money
 func NewMoney(amount int64, currency string) (Money, error) {
 	if amount < 0 {
-		return Money{}, errors.New("amount cannot be negative")
+		return Money{}, ErrNegativeAmount
 	}
 	if currency == "" {
-		return Money{}, errors.New("currency cannot be empty")
+		return Money{}, ErrEmptyCurrency
 	}
 	return Money{amount: amount, currency: currency}, nil
 }
@@ -35,7 +34,7 @@ func (m Money) Currency() string {
 // Add складывает две суммы
 func (m Money) Add(other Money) (Money, error) {
 	if m.currency != other.currency {
-		return Money{}, fmt.Errorf("cannot add different currencies: %s and %s", m.currency, other.currency)
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
 	}
 	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
 }
@@ -1,25 +1,55 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
+	"time"
 )
 
 // Order - агрегат заказа
 type Order struct {
-	id     string
-	lines  []OrderLine
-	status OrderStatus
+	id           string
+	customerID   string
+	lines        []OrderLine
+	status       OrderStatus
+	createdAt    time.Time
+	delivery     Delivery
+	shipping     Money // стоимость доставки; пустая валюта - доставка не рассчитана
+	payments     []PaymentLeg
+	payBy        time.Time     // срок оплаты; нулевое значение - без срока
+	installments []Installment // график рассрочки; пусто - оплата целиком
+}
+
+// OrderSnapshot - полное состояние заказа для сохранения и восстановления
+type OrderSnapshot struct {
+	ID           string
+	CustomerID   string
+	Lines        []OrderLine
+	Status       OrderStatus
+	CreatedAt    time.Time
+	Delivery     Delivery
+	Shipping     Money
+	Payments     []PaymentLeg
+	PayBy        time.Time
+	Installments []Installment
 }
 
 // NewOrder создаёт новый заказ
 func NewOrder(id string) *Order {
 	return &Order{
-		id:     id,
-		lines:  make([]OrderLine, 0),
-		status: OrderStatusPending,
+		id:        id,
+		lines:     make([]OrderLine, 0),
+		status:    OrderStatusPending,
+		createdAt: time.Now().UTC(),
 	}
 }
 
+// NewOrderForCustomer создаёт новый заказ клиента customerID
+func NewOrderForCustomer(id, customerID string) *Order {
+	order := NewOrder(id)
+	order.customerID = customerID
+	return order
+}
+
 // ReconstructOrder восстанавливает заказ из хранилища
 func ReconstructOrder(id string, lines []OrderLine, status OrderStatus) *Order {
 	return &Order{
@@ -29,11 +59,50 @@ func ReconstructOrder(id string, lines []OrderLine, status OrderStatus) *Order {
 	}
 }
 
+// RestoreOrder восстанавливает заказ из снимка состояния
+func RestoreOrder(snapshot OrderSnapshot) *Order {
+	lines := make([]OrderLine, len(snapshot.Lines))
+	copy(lines, snapshot.Lines)
+	return &Order{
+		id:           snapshot.ID,
+		customerID:   snapshot.CustomerID,
+		lines:        lines,
+		status:       snapshot.Status,
+		createdAt:    snapshot.CreatedAt,
+		delivery:     snapshot.Delivery,
+		shipping:     snapshot.Shipping,
+		payments:     append([]PaymentLeg(nil), snapshot.Payments...),
+		payBy:        snapshot.PayBy,
+		installments: append([]Installment(nil), snapshot.Installments...),
+	}
+}
+
+// Snapshot возвращает снимок состояния заказа
+func (o *Order) Snapshot() OrderSnapshot {
+	return OrderSnapshot{
+		ID:           o.id,
+		CustomerID:   o.customerID,
+		Lines:        o.Lines(),
+		Status:       o.status,
+		CreatedAt:    o.createdAt,
+		Delivery:     o.delivery,
+		Shipping:     o.shipping,
+		Payments:     o.Payments(),
+		PayBy:        o.payBy,
+		Installments: o.Installments(),
+	}
+}
+
 // ID возвращает идентификатор заказа
 func (o *Order) ID() string {
 	return o.id
 }
 
+// CustomerID возвращает идентификатор клиента; пусто для заказов без владельца
+func (o *Order) CustomerID() string {
+	return o.customerID
+}
+
 // Lines возвращает копию строк заказа
 func (o *Order) Lines() []OrderLine {
 	linesCopy := make([]OrderLine, len(o.lines))
@@ -41,24 +110,233 @@ func (o *Order) Lines() []OrderLine {
 	return linesCopy
 }
 
+// CreatedAt возвращает время создания заказа
+func (o *Order) CreatedAt() time.Time {
+	return o.createdAt
+}
+
 // Status возвращает статус заказа
 func (o *Order) Status() OrderStatus {
 	return o.status
 }
 
-// AddLine добавляет строку в заказ
+// PayBy возвращает срок оплаты заказа; нулевое значение - срок не задан
+func (o *Order) PayBy() time.Time {
+	return o.payBy
+}
+
+// IsOverdue проверяет, что заказ не оплачен, а срок оплаты истёк к моменту now
+func (o *Order) IsOverdue(now time.Time) bool {
+	return o.status == OrderStatusPending && !o.payBy.IsZero() && !now.Before(o.payBy)
+}
+
+// Delivery возвращает параметры доставки; нулевое значение - доставка не нужна
+func (o *Order) Delivery() Delivery {
+	return o.delivery
+}
+
+// Shipping возвращает рассчитанную стоимость доставки; нулевое значение -
+// доставка не рассчитана
+func (o *Order) Shipping() Money {
+	return o.shipping
+}
+
+// Payments возвращает копию частей, которыми оплачен заказ
+func (o *Order) Payments() []PaymentLeg {
+	if len(o.payments) == 0 {
+		return nil
+	}
+	payments := make([]PaymentLeg, len(o.payments))
+	copy(payments, o.payments)
+	return payments
+}
+
+// Installments возвращает копию графика рассрочки
+func (o *Order) Installments() []Installment {
+	if len(o.installments) == 0 {
+		return nil
+	}
+	installments := make([]Installment, len(o.installments))
+	copy(installments, o.installments)
+	return installments
+}
+
+// OverdueInstallments возвращает платежи рассрочки, не внесённые в срок
+// к моменту now
+func (o *Order) OverdueInstallments(now time.Time) []Installment {
+	var overdue []Installment
+	for _, installment := range o.installments {
+		if installment.IsOverdue(now) {
+			overdue = append(overdue, installment)
+		}
+	}
+	return overdue
+}
+
+// WeightGrams возвращает общий вес товаров заказа в граммах
+func (o *Order) WeightGrams() int {
+	weight := 0
+	for _, line := range o.lines {
+		weight += line.WeightGrams()
+	}
+	return weight
+}
+
+// AddLine добавляет строку в заказ. Рассчитанная ранее доставка
+// сбрасывается, так как зависит от состава заказа.
 func (o *Order) AddLine(line OrderLine) error {
-	if o.status == OrderStatusPaid {
-		return errors.New("cannot modify paid order")
+	if o.status != OrderStatusPending {
+		return ErrOrderNotModifiable
 	}
 	o.lines = append(o.lines, line)
+	o.shipping = Money{}
+	o.installments = nil
+	return nil
+}
+
+// SetPaymentDeadline задаёт срок оплаты заказа; нулевое значение снимает срок
+func (o *Order) SetPaymentDeadline(payBy time.Time) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotModifiable
+	}
+	o.payBy = payBy
+	return nil
+}
+
+// Expire переводит неоплаченный заказ, срок оплаты которого истёк к
+// моменту now, в статус EXPIRED
+func (o *Order) Expire(now time.Time) error {
+	switch o.status {
+	case OrderStatusExpired:
+		return ErrOrderExpired
+	case OrderStatusPaid:
+		return ErrOrderAlreadyPaid
+	case OrderStatusRefunded:
+		return ErrOrderRefunded
+	}
+	if !o.IsOverdue(now) {
+		return ErrOrderNotOverdue
+	}
+	o.status = OrderStatusExpired
+	return nil
+}
+
+// SetDelivery задаёт адрес и способ доставки и сбрасывает рассчитанную доставку
+func (o *Order) SetDelivery(delivery Delivery) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotModifiable
+	}
+	o.delivery = delivery
+	o.shipping = Money{}
+	o.installments = nil
+	return nil
+}
+
+// QuoteShipping рассчитывает стоимость доставки по политике policy.
+// Самовывоз бесплатен; стоимость должна быть в валюте товаров.
+func (o *Order) QuoteShipping(policy ShippingPolicy) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotModifiable
+	}
+	if o.delivery.IsZero() {
+		return ErrDeliveryNotSet
+	}
+	subtotal, err := o.Subtotal()
+	if err != nil {
+		return err
+	}
+
+	cost := Money{amount: 0, currency: subtotal.Currency()}
+	if o.delivery.Method() != DeliveryMethodPickup {
+		cost, err = policy.Cost(Shipment{
+			Delivery:    o.delivery,
+			WeightGrams: o.WeightGrams(),
+			Subtotal:    subtotal,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := subtotal.Add(cost); err != nil {
+		return err
+	}
+
+	o.shipping = cost
+	o.installments = nil
+	return nil
+}
+
+// ScheduleInstallments задаёт график рассрочки: итог заказа делится на
+// len(dueDates) платежей без потерь, остаток деления приходится на
+// последний платёж. Изменение состава заказа или доставки сбрасывает график.
+func (o *Order) ScheduleInstallments(dueDates []time.Time) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotModifiable
+	}
+	if !o.delivery.IsZero() && o.shipping.Currency() == "" {
+		return ErrShippingNotQuoted
+	}
+	total, err := o.Total()
+	if err != nil {
+		return err
+	}
+	installments, err := newInstallments(total, dueDates)
+	if err != nil {
+		return err
+	}
+	o.installments = installments
 	return nil
 }
 
-// Total рассчитывает общую стоимость заказа
+// PayInstallment вносит очередной платёж по графику в момент now и
+// возвращает его. Заказ становится PARTIALLY_PAID, а после последнего
+// платежа - PAID. Каждый платёж записывается отдельной частью оплаты
+// способом method.
+func (o *Order) PayInstallment(method PaymentMethod, now time.Time) (Installment, error) {
+	switch o.status {
+	case OrderStatusPaid:
+		return Installment{}, ErrOrderAlreadyPaid
+	case OrderStatusRefunded:
+		return Installment{}, ErrOrderRefunded
+	case OrderStatusExpired:
+		return Installment{}, ErrOrderExpired
+	}
+	if o.IsOverdue(now) {
+		return Installment{}, ErrOrderExpired
+	}
+	if len(o.installments) == 0 {
+		return Installment{}, ErrInstallmentsNotScheduled
+	}
+
+	i := 0
+	for o.installments[i].IsPaid() {
+		i++
+	}
+	o.installments[i].PaidAt = now
+	o.payments = append(o.payments, PaymentLeg{Method: method, Amount: o.installments[i].Amount})
+	o.status = OrderStatusPartiallyPaid
+	if i == len(o.installments)-1 {
+		o.status = OrderStatusPaid
+	}
+	return o.installments[i], nil
+}
+
+// Total рассчитывает общую стоимость заказа: товары и доставку
 func (o *Order) Total() (Money, error) {
+	subtotal, err := o.Subtotal()
+	if err != nil {
+		return Money{}, err
+	}
+	if o.shipping.Currency() == "" {
+		return subtotal, nil
+	}
+	return subtotal.Add(o.shipping)
+}
+
+// Subtotal рассчитывает стоимость товаров заказа без доставки
+func (o *Order) Subtotal() (Money, error) {
 	if len(o.lines) == 0 {
-		return Money{}, errors.New("order has no lines")
+		return Money{}, ErrOrderHasNoLines
 	}
 
 	// Берём валюту из первой строки
@@ -78,28 +356,109 @@ func (o *Order) Total() (Money, error) {
 	return total, nil
 }
 
-// Pay выполняет оплату заказа
+// Pay выполняет оплату заказа целиком способом по умолчанию в текущий
+// момент
 func (o *Order) Pay() error {
+	return o.PayWith(nil, time.Now())
+}
+
+// PayWith выполняет оплату заказа частями legs в момент now. Части должны
+// в сумме точно равняться итогу заказа; nil означает оплату целиком
+// способом по умолчанию.
+func (o *Order) PayWith(legs []PaymentLeg, now time.Time) error {
 	// Инвариант: нельзя оплатить пустой заказ
 	if len(o.lines) == 0 {
-		return errors.New("cannot pay empty order")
+		return ErrEmptyOrder
 	}
 
 	// Инвариант: нельзя оплатить заказ повторно
 	if o.status == OrderStatusPaid {
-		return errors.New("order is already paid")
+		return ErrOrderAlreadyPaid
+	}
+	if o.status == OrderStatusRefunded {
+		return ErrOrderRefunded
+	}
+
+	// Инвариант: просроченный заказ оплатить нельзя, даже если он ещё
+	// не переведён в EXPIRED
+	if o.status == OrderStatusExpired || o.IsOverdue(now) {
+		return ErrOrderExpired
+	}
+
+	// Инвариант: заказ с графиком рассрочки оплачивается только по графику
+	if len(o.installments) > 0 {
+		return ErrInstallmentsScheduled
+	}
+
+	// Инвариант: доставка оплачивается вместе с заказом
+	if !o.delivery.IsZero() && o.shipping.Currency() == "" {
+		return ErrShippingNotQuoted
 	}
 
 	// Проверяем, что итоговая сумма корректна
-	_, err := o.Total()
+	total, err := o.Total()
 	if err != nil {
 		return err
 	}
 
+	// Инвариант: части оплаты покрывают итог без остатка и переплаты
+	if legs == nil {
+		legs = []PaymentLeg{{Amount: total}}
+	} else if err := validateSplit(total, legs); err != nil {
+		return err
+	}
+
+	o.payments = make([]PaymentLeg, len(legs))
+	copy(o.payments, legs)
 	o.status = OrderStatusPaid
 	return nil
 }
 
+// Refund отменяет оплату заказа и возвращает сумму к возврату
+func (o *Order) Refund() (Money, error) {
+	amount, err := o.RefundableAmount()
+	if err != nil {
+		return Money{}, err
+	}
+	o.status = OrderStatusRefunded
+	return amount, nil
+}
+
+// RefundableAmount возвращает сумму к возврату, не меняя заказ
+func (o *Order) RefundableAmount() (Money, error) {
+	// Инвариант: вернуть можно только оплаченный заказ и только один раз
+	if o.status == OrderStatusRefunded {
+		return Money{}, ErrOrderRefunded
+	}
+	if o.status == OrderStatusPartiallyPaid {
+		// По рассрочке возвращаются только внесённые платежи
+		paid := Money{currency: o.installments[0].Amount.Currency()}
+		for _, installment := range o.installments {
+			if installment.IsPaid() {
+				paid.amount += installment.Amount.Amount()
+			}
+		}
+		return paid, nil
+	}
+	if o.status != OrderStatusPaid {
+		return Money{}, ErrOrderNotPaid
+	}
+	return o.Total()
+}
+
+// MarkPaymentRefunded отмечает часть оплаты i возвращённой. Так возврат,
+// прерванный на середине, при повторе не возвращает часть второй раз.
+func (o *Order) MarkPaymentRefunded(i int) error {
+	if _, err := o.RefundableAmount(); err != nil {
+		return err
+	}
+	if i < 0 || i >= len(o.payments) {
+		return fmt.Errorf("%w: no payment leg %d", ErrSplitMismatch, i+1)
+	}
+	o.payments[i].Refunded = true
+	return nil
+}
+
 // IsPaid проверяет, оплачен ли заказ
 func (o *Order) IsPaid() bool {
 	return o.status == OrderStatusPaid
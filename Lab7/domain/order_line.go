@@ -1,34 +1,98 @@
 package domain
 
-import "errors"
+import "fmt"
 
 // OrderLine - строка заказа (часть агрегата Order)
 type OrderLine struct {
-	productID string
-	price     Money
-	quantity  int
+	productID   string
+	name        string
+	price       Money
+	quantity    int
+	weightGrams int
+	vatRate     VATRate
+}
+
+// OrderLineSnapshot - полное состояние строки заказа для сохранения и восстановления
+type OrderLineSnapshot struct {
+	ProductID   string
+	Name        string
+	Price       Money
+	Quantity    int
+	WeightGrams int     // вес единицы товара
+	VATRate     VATRate // пусто - VATNone
 }
 
 // NewOrderLine создаёт новую строку заказа
 func NewOrderLine(productID string, price Money, quantity int) (OrderLine, error) {
-	if productID == "" {
-		return OrderLine{}, errors.New("productID cannot be empty")
+	return RestoreOrderLine(OrderLineSnapshot{ProductID: productID, Price: price, Quantity: quantity})
+}
+
+// NewOrderLineFromProduct создаёт строку заказа по товару каталога.
+// Название, цена, вес и ставка НДС товара копируются в строку и не
+// меняются вместе с каталогом.
+func NewOrderLineFromProduct(product *Product, quantity int) (OrderLine, error) {
+	// Инвариант: снятый с продажи товар нельзя добавить в заказ
+	if !product.IsActive() {
+		return OrderLine{}, fmt.Errorf("%w: %s", ErrProductInactive, product.ID())
+	}
+	return RestoreOrderLine(OrderLineSnapshot{
+		ProductID:   product.ID(),
+		Name:        product.Name(),
+		Price:       product.Price(),
+		Quantity:    quantity,
+		WeightGrams: product.WeightGrams(),
+		VATRate:     product.VATRate(),
+	})
+}
+
+// RestoreOrderLine восстанавливает строку заказа из хранилища
+func RestoreOrderLine(snapshot OrderLineSnapshot) (OrderLine, error) {
+	if snapshot.ProductID == "" {
+		return OrderLine{}, ErrEmptyProductID
+	}
+	if snapshot.Quantity <= 0 {
+		return OrderLine{}, ErrNonPositiveQuantity
 	}
-	if quantity <= 0 {
-		return OrderLine{}, errors.New("quantity must be positive")
+	if snapshot.WeightGrams < 0 {
+		return OrderLine{}, ErrNegativeWeight
+	}
+	vatRate, err := ParseVATRate(string(snapshot.VATRate))
+	if err != nil {
+		return OrderLine{}, err
 	}
 	return OrderLine{
-		productID: productID,
-		price:     price,
-		quantity:  quantity,
+		productID:   snapshot.ProductID,
+		name:        snapshot.Name,
+		price:       snapshot.Price,
+		quantity:    snapshot.Quantity,
+		weightGrams: snapshot.WeightGrams,
+		vatRate:     vatRate,
 	}, nil
 }
 
+// Snapshot возвращает снимок состояния строки
+func (ol OrderLine) Snapshot() OrderLineSnapshot {
+	return OrderLineSnapshot{
+		ProductID:   ol.productID,
+		Name:        ol.name,
+		Price:       ol.price,
+		Quantity:    ol.quantity,
+		WeightGrams: ol.weightGrams,
+		VATRate:     ol.vatRate,
+	}
+}
+
 // ProductID возвращает ID продукта
 func (ol OrderLine) ProductID() string {
 	return ol.productID
 }
 
+// Name возвращает название товара на момент добавления строки;
+// пусто для строк, созданных без каталога
+func (ol OrderLine) Name() string {
+	return ol.name
+}
+
 // Price возвращает цену за единицу
 func (ol OrderLine) Price() Money {
 	return ol.price
@@ -39,6 +103,21 @@ func (ol OrderLine) Quantity() int {
 	return ol.quantity
 }
 
+// WeightGrams возвращает вес всей строки в граммах
+func (ol OrderLine) WeightGrams() int {
+	return ol.weightGrams * ol.quantity
+}
+
+// VATRate возвращает ставку НДС строки
+func (ol OrderLine) VATRate() VATRate {
+	return ol.vatRate
+}
+
+// VAT возвращает НДС, включённый в стоимость строки
+func (ol OrderLine) VAT() Money {
+	return ol.vatRate.Included(ol.Total())
+}
+
 // Total рассчитывает общую стоимость строки
 func (ol OrderLine) Total() Money {
 	totalAmount := ol.price.Amount() * int64(ol.quantity)
@@ -1,16 +1,34 @@
 package domain
 
+import "fmt"
+
 // OrderStatus - статус заказа
 type OrderStatus string
 
 const (
 	// OrderStatusPending - заказ создан, но не оплачен
 	OrderStatusPending OrderStatus = "PENDING"
+	// OrderStatusPartiallyPaid - внесена часть платежей по графику рассрочки
+	OrderStatusPartiallyPaid OrderStatus = "PARTIALLY_PAID"
 	// OrderStatusPaid - заказ оплачен
 	OrderStatusPaid OrderStatus = "PAID"
+	// OrderStatusRefunded - по оплаченному заказу выполнен возврат средств
+	OrderStatusRefunded OrderStatus = "REFUNDED"
+	// OrderStatusExpired - заказ не оплачен до истечения срока оплаты
+	OrderStatusExpired OrderStatus = "EXPIRED"
 )
 
 // String возвращает строковое представление статуса
 func (s OrderStatus) String() string {
 	return string(s)
 }
+
+// ParseOrderStatus разбирает строковое представление статуса
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	switch status := OrderStatus(s); status {
+	case OrderStatusPending, OrderStatusPartiallyPaid, OrderStatusPaid, OrderStatusRefunded, OrderStatusExpired:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownOrderStatus, s)
+	}
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PaymentMethodKind - вид способа оплаты
+type PaymentMethodKind string
+
+const (
+	// PaymentMethodCard - банковская карта, сохранённая у платёжного провайдера
+	PaymentMethodCard PaymentMethodKind = "CARD"
+	// PaymentMethodWallet - электронный кошелёк
+	PaymentMethodWallet PaymentMethodKind = "WALLET"
+	// PaymentMethodBankTransfer - банковский перевод
+	PaymentMethodBankTransfer PaymentMethodKind = "BANK_TRANSFER"
+	// PaymentMethodCashOnDelivery - оплата наличными при получении
+	PaymentMethodCashOnDelivery PaymentMethodKind = "CASH_ON_DELIVERY"
+	// PaymentMethodGiftCard - подарочная карта магазина
+	PaymentMethodGiftCard PaymentMethodKind = "GIFT_CARD"
+)
+
+// String возвращает строковое представление вида оплаты
+func (k PaymentMethodKind) String() string {
+	return string(k)
+}
+
+// PaymentMethod - способ оплаты (Value Object). Нулевое значение означает,
+// что способ не указан и шлюз применяет способ по умолчанию.
+type PaymentMethod struct {
+	kind      PaymentMethodKind
+	reference string // токен карты, идентификатор кошелька, счёта или подарочной карты
+}
+
+// NewCardToken создаёт оплату картой по токену провайдера. Номер карты
+// вместо токена не принимается, чтобы он не попал в хранилище и логи.
+func NewCardToken(token string) (PaymentMethod, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return PaymentMethod{}, fmt.Errorf("%w: card token is required", ErrInvalidPaymentMethod)
+	}
+	if looksLikeCardNumber(token) {
+		return PaymentMethod{}, fmt.Errorf("%w: card number given instead of a token", ErrInvalidPaymentMethod)
+	}
+	return PaymentMethod{kind: PaymentMethodCard, reference: token}, nil
+}
+
+// NewWallet создаёт оплату из электронного кошелька walletID
+func NewWallet(walletID string) (PaymentMethod, error) {
+	walletID = strings.TrimSpace(walletID)
+	if walletID == "" {
+		return PaymentMethod{}, fmt.Errorf("%w: wallet ID is required", ErrInvalidPaymentMethod)
+	}
+	return PaymentMethod{kind: PaymentMethodWallet, reference: walletID}, nil
+}
+
+// NewBankTransfer создаёт оплату переводом со счёта account
+func NewBankTransfer(account string) (PaymentMethod, error) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return PaymentMethod{}, fmt.Errorf("%w: bank account is required", ErrInvalidPaymentMethod)
+	}
+	return PaymentMethod{kind: PaymentMethodBankTransfer, reference: account}, nil
+}
+
+// CashOnDelivery создаёт оплату наличными при получении
+func CashOnDelivery() PaymentMethod {
+	return PaymentMethod{kind: PaymentMethodCashOnDelivery}
+}
+
+// NewGiftCardPayment создаёт оплату подарочной картой cardID
+func NewGiftCardPayment(cardID string) (PaymentMethod, error) {
+	cardID = strings.TrimSpace(cardID)
+	if cardID == "" {
+		return PaymentMethod{}, fmt.Errorf("%w: %w", ErrInvalidPaymentMethod, ErrEmptyGiftCardID)
+	}
+	return PaymentMethod{kind: PaymentMethodGiftCard, reference: cardID}, nil
+}
+
+// ParsePaymentMethod создаёт способ оплаты по виду и реквизиту
+func ParsePaymentMethod(kind, reference string) (PaymentMethod, error) {
+	switch PaymentMethodKind(kind) {
+	case PaymentMethodCard:
+		return NewCardToken(reference)
+	case PaymentMethodWallet:
+		return NewWallet(reference)
+	case PaymentMethodBankTransfer:
+		return NewBankTransfer(reference)
+	case PaymentMethodCashOnDelivery:
+		return CashOnDelivery(), nil
+	case PaymentMethodGiftCard:
+		return NewGiftCardPayment(reference)
+	default:
+		return PaymentMethod{}, fmt.Errorf("%w: %q", ErrUnknownPaymentMethod, kind)
+	}
+}
+
+// Kind возвращает вид оплаты; пусто, если способ не указан
+func (m PaymentMethod) Kind() PaymentMethodKind {
+	return m.kind
+}
+
+// Reference возвращает реквизит способа оплаты
+func (m PaymentMethod) Reference() string {
+	return m.reference
+}
+
+// IsZero проверяет, что способ оплаты не указан
+func (m PaymentMethod) IsZero() bool {
+	return m == PaymentMethod{}
+}
+
+// String возвращает вид оплаты и последние символы реквизита
+func (m PaymentMethod) String() string {
+	switch {
+	case m.IsZero():
+		return "default"
+	case len(m.reference) > 4:
+		return fmt.Sprintf("%s *%s", m.kind, m.reference[len(m.reference)-4:])
+	case m.reference != "":
+		return fmt.Sprintf("%s %s", m.kind, m.reference)
+	default:
+		return m.kind.String()
+	}
+}
+
+// looksLikeCardNumber проверяет, похожа ли строка на номер карты
+func looksLikeCardNumber(s string) bool {
+	digits := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == ' ' || r == '-':
+		default:
+			return false
+		}
+	}
+	return digits >= 13 && digits <= 19
+}
+
+// PaymentLeg - часть оплаты заказа одним способом
+type PaymentLeg struct {
+	Method   PaymentMethod
+	Amount   Money
+	Refunded bool // часть уже возвращена, а заказ ещё не переведён в REFUNDED
+}
+
+// validateSplit проверяет, что части оплаты положительны и в сумме
+// точно равны total
+func validateSplit(total Money, legs []PaymentLeg) error {
+	if len(legs) == 0 {
+		return fmt.Errorf("%w: no payment legs", ErrSplitMismatch)
+	}
+	sum := Money{amount: 0, currency: total.Currency()}
+	for i, leg := range legs {
+		if leg.Amount.IsZero() {
+			return fmt.Errorf("%w: leg %d has zero amount", ErrSplitMismatch, i+1)
+		}
+		var err error
+		if sum, err = sum.Add(leg.Amount); err != nil {
+			return err
+		}
+	}
+	if !sum.Equals(total) {
+		return fmt.Errorf("%w: legs sum to %s, order total is %s", ErrSplitMismatch, sum, total)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package domain
+
+import "strings"
+
+// Product - агрегат товара каталога. Цена товара - единственный
+// источник цены для строк заказа.
+type Product struct {
+	id          string
+	name        string
+	price       Money
+	weightGrams int
+	vatRate     VATRate
+	active      bool
+}
+
+// ProductSnapshot - полное состояние товара для сохранения и восстановления
+type ProductSnapshot struct {
+	ID          string
+	Name        string
+	Price       Money
+	WeightGrams int
+	VATRate     VATRate
+	Active      bool
+}
+
+// NewProduct создаёт активный товар
+func NewProduct(id, name string, price Money) (*Product, error) {
+	if id == "" {
+		return nil, ErrEmptyProductID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyProductName
+	}
+	if price.Currency() == "" {
+		return nil, ErrEmptyCurrency
+	}
+	return &Product{id: id, name: name, price: price, vatRate: VATNone, active: true}, nil
+}
+
+// RestoreProduct восстанавливает товар из снимка состояния
+func RestoreProduct(snapshot ProductSnapshot) *Product {
+	return &Product{
+		id:          snapshot.ID,
+		name:        snapshot.Name,
+		price:       snapshot.Price,
+		weightGrams: snapshot.WeightGrams,
+		vatRate:     snapshot.VATRate,
+		active:      snapshot.Active,
+	}
+}
+
+// Snapshot возвращает снимок состояния товара
+func (p *Product) Snapshot() ProductSnapshot {
+	return ProductSnapshot{
+		ID:          p.id,
+		Name:        p.name,
+		Price:       p.price,
+		WeightGrams: p.weightGrams,
+		VATRate:     p.vatRate,
+		Active:      p.active,
+	}
+}
+
+// ID возвращает идентификатор товара
+func (p *Product) ID() string {
+	return p.id
+}
+
+// Name возвращает название товара
+func (p *Product) Name() string {
+	return p.name
+}
+
+// Price возвращает текущую цену товара
+func (p *Product) Price() Money {
+	return p.price
+}
+
+// WeightGrams возвращает вес единицы товара в граммах
+func (p *Product) WeightGrams() int {
+	return p.weightGrams
+}
+
+// SetWeight устанавливает вес единицы товара в граммах
+func (p *Product) SetWeight(grams int) error {
+	if grams < 0 {
+		return ErrNegativeWeight
+	}
+	p.weightGrams = grams
+	return nil
+}
+
+// VATRate возвращает ставку НДС товара
+func (p *Product) VATRate() VATRate {
+	return p.vatRate
+}
+
+// SetVATRate устанавливает ставку НДС товара
+func (p *Product) SetVATRate(rate VATRate) error {
+	rate, err := ParseVATRate(string(rate))
+	if err != nil {
+		return err
+	}
+	p.vatRate = rate
+	return nil
+}
+
+// IsActive проверяет, продаётся ли товар
+func (p *Product) IsActive() bool {
+	return p.active
+}
+
+// ChangePrice устанавливает новую цену; строки уже созданных заказов
+// сохраняют прежнюю цену
+func (p *Product) ChangePrice(price Money) error {
+	if price.Currency() == "" {
+		return ErrEmptyCurrency
+	}
+	p.price = price
+	return nil
+}
+
+// Activate возвращает товар в продажу
+func (p *Product) Activate() {
+	p.active = true
+}
+
+// Deactivate снимает товар с продажи
+func (p *Product) Deactivate() {
+	p.active = false
+}
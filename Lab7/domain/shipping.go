@@ -0,0 +1,108 @@
+package domain
+
+import "fmt"
+
+// Shipment - данные заказа, от которых зависит стоимость доставки
+type Shipment struct {
+	Delivery    Delivery
+	WeightGrams int   // общий вес товаров
+	Subtotal    Money // стоимость товаров без доставки
+}
+
+// ShippingPolicy - правило расчёта стоимости доставки (Strategy).
+// Политики не знают о заказе и могут вкладываться друг в друга.
+type ShippingPolicy interface {
+	Cost(shipment Shipment) (Money, error)
+}
+
+// FlatRateShipping - доставка по фиксированной цене
+type FlatRateShipping struct {
+	rate Money
+}
+
+// NewFlatRateShipping создаёт политику с фиксированной ценой доставки
+func NewFlatRateShipping(rate Money) FlatRateShipping {
+	return FlatRateShipping{rate: rate}
+}
+
+// Cost возвращает фиксированную цену
+func (p FlatRateShipping) Cost(Shipment) (Money, error) {
+	return p.rate, nil
+}
+
+// WeightBasedShipping - базовая цена плюс плата за каждый начатый килограмм
+type WeightBasedShipping struct {
+	base        Money
+	perKilogram Money
+}
+
+// NewWeightBasedShipping создаёт политику расчёта по весу
+func NewWeightBasedShipping(base, perKilogram Money) (WeightBasedShipping, error) {
+	if base.Currency() != perKilogram.Currency() {
+		return WeightBasedShipping{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, base.Currency(), perKilogram.Currency())
+	}
+	return WeightBasedShipping{base: base, perKilogram: perKilogram}, nil
+}
+
+// Cost рассчитывает стоимость по весу; неполный килограмм считается целым
+func (p WeightBasedShipping) Cost(shipment Shipment) (Money, error) {
+	kilograms := int64((shipment.WeightGrams + 999) / 1000)
+	return NewMoney(p.base.Amount()+p.perKilogram.Amount()*kilograms, p.base.Currency())
+}
+
+// ZoneTableShipping - выбор политики по зоне доставки. Зона задаётся
+// ключом "СТРАНА/РЕГИОН" или "СТРАНА"; более точный ключ имеет приоритет.
+type ZoneTableShipping struct {
+	zones    map[string]ShippingPolicy
+	fallback ShippingPolicy
+}
+
+// NewZoneTableShipping создаёт таблицу зон. fallback применяется к адресам
+// вне таблицы; при nil доставка по таким адресам недоступна.
+func NewZoneTableShipping(zones map[string]ShippingPolicy, fallback ShippingPolicy) ZoneTableShipping {
+	table := make(map[string]ShippingPolicy, len(zones))
+	for zone, policy := range zones {
+		table[zone] = policy
+	}
+	return ZoneTableShipping{zones: table, fallback: fallback}
+}
+
+// Cost рассчитывает стоимость по политике зоны адреса
+func (p ZoneTableShipping) Cost(shipment Shipment) (Money, error) {
+	address := shipment.Delivery.Address()
+	if address.Region() != "" {
+		if policy, ok := p.zones[address.Country()+"/"+address.Region()]; ok {
+			return policy.Cost(shipment)
+		}
+	}
+	if policy, ok := p.zones[address.Country()]; ok {
+		return policy.Cost(shipment)
+	}
+	if p.fallback != nil {
+		return p.fallback.Cost(shipment)
+	}
+	return Money{}, fmt.Errorf("%w: no zone for %s/%s", ErrShippingUnavailable, address.Country(), address.Region())
+}
+
+// FreeOverThresholdShipping - бесплатная доставка для заказов от порога,
+// иначе стоимость рассчитывает вложенная политика
+type FreeOverThresholdShipping struct {
+	threshold Money
+	next      ShippingPolicy
+}
+
+// NewFreeOverThresholdShipping создаёт политику бесплатной доставки от суммы threshold
+func NewFreeOverThresholdShipping(threshold Money, next ShippingPolicy) FreeOverThresholdShipping {
+	return FreeOverThresholdShipping{threshold: threshold, next: next}
+}
+
+// Cost возвращает ноль, если стоимость товаров не меньше порога
+func (p FreeOverThresholdShipping) Cost(shipment Shipment) (Money, error) {
+	if shipment.Subtotal.Currency() != p.threshold.Currency() {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, shipment.Subtotal.Currency(), p.threshold.Currency())
+	}
+	if shipment.Subtotal.Amount() >= p.threshold.Amount() {
+		return NewMoney(0, p.threshold.Currency())
+	}
+	return p.next.Cost(shipment)
+}
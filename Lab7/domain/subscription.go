@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillingInterval - период списаний по подписке
+type BillingInterval string
+
+const (
+	// BillingWeekly - раз в неделю
+	BillingWeekly BillingInterval = "WEEKLY"
+	// BillingMonthly - раз в месяц
+	BillingMonthly BillingInterval = "MONTHLY"
+	// BillingYearly - раз в год
+	BillingYearly BillingInterval = "YEARLY"
+)
+
+// String возвращает строковое представление периода
+func (i BillingInterval) String() string {
+	return string(i)
+}
+
+// ParseBillingInterval разбирает строковое представление периода
+func ParseBillingInterval(s string) (BillingInterval, error) {
+	switch interval := BillingInterval(s); interval {
+	case BillingWeekly, BillingMonthly, BillingYearly:
+		return interval, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownBillingInterval, s)
+	}
+}
+
+// After возвращает дату n-го списания после первого списания в момент
+// start. Если в месяце нет дня start, списание переносится на последний
+// день месяца: подписка от 31 января списывается 29 февраля и 31 марта.
+func (i BillingInterval) After(start time.Time, n int) time.Time {
+	months := 0
+	switch i {
+	case BillingWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case BillingYearly:
+		months = 12 * n
+	default:
+		months = n
+	}
+	year, month, day := start.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	hour, minute, sec := start.Clock()
+	return time.Date(first.Year(), first.Month(), min(day, lastDay), hour, minute, sec, start.Nanosecond(), start.Location())
+}
+
+// Plan - тарифный план подписки (Value Object). За каждый период
+// выставляется заказ с одной строкой по цене плана.
+type Plan struct {
+	id      string
+	name    string
+	price   Money
+	vatRate VATRate
+}
+
+// NewPlan создаёт тарифный план
+func NewPlan(id, name string, price Money, vatRate VATRate) (Plan, error) {
+	if id == "" {
+		return Plan{}, ErrEmptyProductID
+	}
+	if price.Currency() == "" {
+		return Plan{}, ErrEmptyCurrency
+	}
+	vatRate, err := ParseVATRate(string(vatRate))
+	if err != nil {
+		return Plan{}, err
+	}
+	return Plan{id: id, name: name, price: price, vatRate: vatRate}, nil
+}
+
+// ID возвращает идентификатор плана; он же идентификатор товара в заказах
+func (p Plan) ID() string {
+	return p.id
+}
+
+// Name возвращает название плана
+func (p Plan) Name() string {
+	return p.name
+}
+
+// Price возвращает цену за период
+func (p Plan) Price() Money {
+	return p.price
+}
+
+// VATRate возвращает ставку НДС плана
+func (p Plan) VATRate() VATRate {
+	return p.vatRate
+}
+
+// OrderLine возвращает строку заказа за один период
+func (p Plan) OrderLine() (OrderLine, error) {
+	return RestoreOrderLine(OrderLineSnapshot{
+		ProductID: p.id,
+		Name:      p.name,
+		Price:     p.price,
+		Quantity:  1,
+		VATRate:   p.vatRate,
+	})
+}
+
+// RetrySchedule - задержки повторных попыток списания после неудачи.
+// Подписка приостанавливается, когда все повторы исчерпаны.
+type RetrySchedule []time.Duration
+
+// DefaultRetrySchedule - повторы через 1, 3 и 7 дней
+var DefaultRetrySchedule = RetrySchedule{24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour}
+
+// Subscription - агрегат подписки. За каждый период (цикл) выставляется
+// и оплачивается отдельный заказ.
+type Subscription struct {
+	id            string
+	customerID    string
+	plan          Plan
+	interval      BillingInterval
+	status        SubscriptionStatus
+	startedAt     time.Time // дата первого списания; от неё считаются следующие
+	cycle         int       // номер текущего неоплаченного периода, с 1
+	nextBillingAt time.Time // дата списания за текущий период
+	failures      int       // неудачных попыток за текущий период
+	deferrals     int       // отложенных подряд попыток из-за сбоев шлюза
+	retryAt       time.Time // время следующего повтора; нулевое - повтора нет
+}
+
+// SubscriptionSnapshot - полное состояние подписки для сохранения и восстановления
+type SubscriptionSnapshot struct {
+	ID            string
+	CustomerID    string
+	Plan          Plan
+	Interval      BillingInterval
+	Status        SubscriptionStatus
+	StartedAt     time.Time
+	Cycle         int
+	NextBillingAt time.Time
+	Failures      int
+	Deferrals     int
+	RetryAt       time.Time
+}
+
+// NewSubscription создаёт активную подписку клиента customerID на план
+// plan; первое списание - в момент start
+func NewSubscription(id, customerID string, plan Plan, interval BillingInterval, start time.Time) (*Subscription, error) {
+	if id == "" {
+		return nil, ErrEmptySubscriptionID
+	}
+	if customerID == "" {
+		return nil, ErrEmptyCustomerID
+	}
+	interval, err := ParseBillingInterval(string(interval))
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{
+		id:            id,
+		customerID:    customerID,
+		plan:          plan,
+		interval:      interval,
+		status:        SubscriptionStatusActive,
+		startedAt:     start,
+		cycle:         1,
+		nextBillingAt: start,
+	}, nil
+}
+
+// RestoreSubscription восстанавливает подписку из снимка состояния
+func RestoreSubscription(snapshot SubscriptionSnapshot) *Subscription {
+	return &Subscription{
+		id:            snapshot.ID,
+		customerID:    snapshot.CustomerID,
+		plan:          snapshot.Plan,
+		interval:      snapshot.Interval,
+		status:        snapshot.Status,
+		startedAt:     snapshot.StartedAt,
+		cycle:         snapshot.Cycle,
+		nextBillingAt: snapshot.NextBillingAt,
+		failures:      snapshot.Failures,
+		deferrals:     snapshot.Deferrals,
+		retryAt:       snapshot.RetryAt,
+	}
+}
+
+// Snapshot возвращает снимок состояния подписки
+func (s *Subscription) Snapshot() SubscriptionSnapshot {
+	return SubscriptionSnapshot{
+		ID:            s.id,
+		CustomerID:    s.customerID,
+		Plan:          s.plan,
+		Interval:      s.interval,
+		Status:        s.status,
+		StartedAt:     s.startedAt,
+		Cycle:         s.cycle,
+		NextBillingAt: s.nextBillingAt,
+		Failures:      s.failures,
+		Deferrals:     s.deferrals,
+		RetryAt:       s.retryAt,
+	}
+}
+
+// ID возвращает идентификатор подписки
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// CustomerID возвращает идентификатор клиента
+func (s *Subscription) CustomerID() string {
+	return s.customerID
+}
+
+// Plan возвращает тарифный план
+func (s *Subscription) Plan() Plan {
+	return s.plan
+}
+
+// Interval возвращает период списаний
+func (s *Subscription) Interval() BillingInterval {
+	return s.interval
+}
+
+// Status возвращает статус подписки
+func (s *Subscription) Status() SubscriptionStatus {
+	return s.status
+}
+
+// Cycle возвращает номер текущего неоплаченного периода
+func (s *Subscription) Cycle() int {
+	return s.cycle
+}
+
+// NextBillingAt возвращает дату списания за текущий период
+func (s *Subscription) NextBillingAt() time.Time {
+	return s.nextBillingAt
+}
+
+// Failures возвращает число неудачных попыток за текущий период
+func (s *Subscription) Failures() int {
+	return s.failures
+}
+
+// Deferrals возвращает число отложенных подряд попыток списания
+func (s *Subscription) Deferrals() int {
+	return s.deferrals
+}
+
+// DueAt возвращает время ближайшей попытки списания: дату периода или
+// время повтора после неудачи
+func (s *Subscription) DueAt() time.Time {
+	if s.status == SubscriptionStatusPastDue {
+		return s.retryAt
+	}
+	return s.nextBillingAt
+}
+
+// IsDue проверяет, пора ли списывать оплату к моменту now
+func (s *Subscription) IsDue(now time.Time) bool {
+	if s.status != SubscriptionStatusActive && s.status != SubscriptionStatusPastDue {
+		return false
+	}
+	return !now.Before(s.DueAt())
+}
+
+// CycleOrderID возвращает идентификатор заказа за текущий период.
+// Повторы списания за период используют тот же заказ.
+func (s *Subscription) CycleOrderID() string {
+	return fmt.Sprintf("%s-%d", s.id, s.cycle)
+}
+
+// RecordPayment отмечает оплату текущего периода и переходит к следующему
+func (s *Subscription) RecordPayment() error {
+	if err := s.checkBillable(); err != nil {
+		return err
+	}
+	s.cycle++
+	s.nextBillingAt = s.interval.After(s.startedAt, s.cycle-1)
+	s.status = SubscriptionStatusActive
+	s.failures = 0
+	s.deferrals = 0
+	s.retryAt = time.Time{}
+	return nil
+}
+
+// RecordFailure отмечает неудачное списание в момент now. Следующая
+// попытка назначается по расписанию schedule; когда повторы исчерпаны,
+// подписка приостанавливается.
+func (s *Subscription) RecordFailure(now time.Time, schedule RetrySchedule) error {
+	if err := s.checkBillable(); err != nil {
+		return err
+	}
+	s.failures++
+	s.deferrals = 0
+	if s.failures > len(schedule) {
+		s.status = SubscriptionStatusSuspended
+		s.retryAt = time.Time{}
+		return nil
+	}
+	s.status = SubscriptionStatusPastDue
+	s.retryAt = now.Add(schedule[s.failures-1])
+	return nil
+}
+
+// RecordDeferral отмечает попытку списания, отложенную из-за временного
+// сбоя шлюза. Такая попытка не засчитывается как неудачная и не меняет
+// срок следующей попытки.
+func (s *Subscription) RecordDeferral() error {
+	if err := s.checkBillable(); err != nil {
+		return err
+	}
+	s.deferrals++
+	return nil
+}
+
+// Resume возобновляет приостановленную подписку: неоплаченный период
+// списывается заново начиная с момента now
+func (s *Subscription) Resume(now time.Time) error {
+	if s.status != SubscriptionStatusSuspended {
+		return fmt.Errorf("%w: subscription %s is %s", ErrSubscriptionNotSuspended, s.id, s.status)
+	}
+	s.status = SubscriptionStatusPastDue
+	s.failures = 0
+	s.deferrals = 0
+	s.retryAt = now
+	return nil
+}
+
+// Cancel отменяет подписку; списаний по ней больше не будет
+func (s *Subscription) Cancel() error {
+	if s.status == SubscriptionStatusCancelled {
+		return fmt.Errorf("%w: subscription %s", ErrSubscriptionCancelled, s.id)
+	}
+	s.status = SubscriptionStatusCancelled
+	s.retryAt = time.Time{}
+	return nil
+}
+
+// checkBillable проверяет, что по подписке можно списывать оплату
+func (s *Subscription) checkBillable() error {
+	switch s.status {
+	case SubscriptionStatusCancelled:
+		return fmt.Errorf("%w: subscription %s", ErrSubscriptionCancelled, s.id)
+	case SubscriptionStatusSuspended:
+		return fmt.Errorf("%w: subscription %s", ErrSubscriptionSuspended, s.id)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "fmt"
+
+// SubscriptionStatus - статус подписки
+type SubscriptionStatus string
+
+const (
+	// SubscriptionStatusActive - подписка оплачивается по расписанию
+	SubscriptionStatusActive SubscriptionStatus = "ACTIVE"
+	// SubscriptionStatusPastDue - очередное списание не прошло, ожидается повтор
+	SubscriptionStatusPastDue SubscriptionStatus = "PAST_DUE"
+	// SubscriptionStatusSuspended - подписка приостановлена после исчерпания повторов
+	SubscriptionStatusSuspended SubscriptionStatus = "SUSPENDED"
+	// SubscriptionStatusCancelled - подписка отменена
+	SubscriptionStatusCancelled SubscriptionStatus = "CANCELLED"
+)
+
+// String возвращает строковое представление статуса
+func (s SubscriptionStatus) String() string {
+	return string(s)
+}
+
+// ParseSubscriptionStatus разбирает строковое представление статуса
+func ParseSubscriptionStatus(s string) (SubscriptionStatus, error) {
+	switch status := SubscriptionStatus(s); status {
+	case SubscriptionStatusActive, SubscriptionStatusPastDue, SubscriptionStatusSuspended, SubscriptionStatusCancelled:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownSubscriptionStatus, s)
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "fmt"
+
+// VATRate - ставка НДС. Цены в заказе указываются с учётом НДС,
+// поэтому налог выделяется из суммы, а не начисляется сверху.
+type VATRate string
+
+const (
+	// VATNone - товар не облагается НДС
+	VATNone VATRate = "NONE"
+	// VAT0 - ставка 0%
+	VAT0 VATRate = "VAT0"
+	// VAT10 - ставка 10%
+	VAT10 VATRate = "VAT10"
+	// VAT20 - ставка 20%
+	VAT20 VATRate = "VAT20"
+)
+
+// String возвращает строковое представление ставки
+func (r VATRate) String() string {
+	return string(r)
+}
+
+// ParseVATRate разбирает строковое представление ставки; пустая строка
+// означает VATNone
+func ParseVATRate(s string) (VATRate, error) {
+	switch rate := VATRate(s); rate {
+	case "":
+		return VATNone, nil
+	case VATNone, VAT0, VAT10, VAT20:
+		return rate, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownVATRate, s)
+	}
+}
+
+// Percent возвращает ставку в процентах
+func (r VATRate) Percent() int64 {
+	switch r {
+	case VAT10:
+		return 10
+	case VAT20:
+		return 20
+	default:
+		return 0
+	}
+}
+
+// Included выделяет НДС из суммы gross, включающей налог. Результат
+// округляется до минимальной единицы валюты по правилам арифметики.
+func (r VATRate) Included(gross Money) Money {
+	p := r.Percent()
+	tax := (gross.Amount()*p*2 + 100 + p) / (2 * (100 + p))
+	return Money{amount: tax, currency: gross.Currency()}
+}
@@ -0,0 +1,219 @@
+// Package audit ведёт журнал изменений заказов, защищённый от подделки.
+//
+// Журнал пополняется только дописыванием. Каждая запись хранит автора
+// изменения, время, состояние заказа до и после изменения и хеш SHA-256,
+// в который входит хеш предыдущей записи. Изменение записи меняет её
+// хеш, а удаление разрывает цепочку, поэтому Verify обнаруживает и то и
+// другое. Записи создаёт декоратор OrderRepository при каждом сохранении,
+// которое меняет заказ.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"sync"
+	"time"
+)
+
+// ErrTampered - журнал изменён: запись подменена, удалена или вставлена
+var ErrTampered = errors.New("audit log tampered")
+
+// ActorSystem - автор изменений, если он не указан в контексте
+const ActorSystem = "system"
+
+// Action - вид изменения заказа
+type Action string
+
+const (
+	// ActionCreated - заказ создан
+	ActionCreated Action = "order.created"
+	// ActionLinesChanged - изменены строки заказа
+	ActionLinesChanged Action = "order.lines_changed"
+	// ActionDeliveryChanged - изменена доставка или её стоимость
+	ActionDeliveryChanged Action = "order.delivery_changed"
+	// ActionInstallmentsScheduled - назначен или сброшен график рассрочки
+	ActionInstallmentsScheduled Action = "order.installments_scheduled"
+	// ActionInstallmentPaid - внесён платёж по графику рассрочки
+	ActionInstallmentPaid Action = "order.installment_paid"
+	// ActionPaid - заказ оплачен
+	ActionPaid Action = "order.paid"
+	// ActionRefunded - по заказу выполнен возврат средств
+	ActionRefunded Action = "order.refunded"
+	// ActionExpired - заказ просрочен
+	ActionExpired Action = "order.expired"
+	// ActionUpdated - прочие изменения
+	ActionUpdated Action = "order.updated"
+)
+
+// Entry - запись журнала
+type Entry struct {
+	Seq       uint64                 `json:"seq"` // номер записи, с 1
+	At        time.Time              `json:"at"`
+	Actor     string                 `json:"actor"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Action    Action                 `json:"action"`
+	OrderID   string                 `json:"order_id"`
+	Before    *application.OrderView `json:"before,omitempty"` // nil для ActionCreated
+	After     *application.OrderView `json:"after"`
+	PrevHash  string                 `json:"prev_hash"` // пусто для первой записи
+	Hash      string                 `json:"hash"`
+}
+
+// computeHash вычисляет хеш записи по всем полям, кроме самого хеша
+func computeHash(entry Entry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify проверяет цепочку записей: номера идут подряд с 1, хеш каждой
+// записи совпадает с её содержимым и указан в следующей записи. Возвращает
+// все найденные нарушения, каждое оборачивает ErrTampered.
+func Verify(entries []Entry) error {
+	var errs []error
+	prevHash := ""
+	for i, entry := range entries {
+		if entry.Seq != uint64(i+1) {
+			errs = append(errs, fmt.Errorf("%w: entry %d has seq %d", ErrTampered, i+1, entry.Seq))
+		}
+		if entry.PrevHash != prevHash {
+			errs = append(errs, fmt.Errorf("%w: entry %d does not follow the previous entry", ErrTampered, entry.Seq))
+		}
+		hash, err := computeHash(entry)
+		if err != nil {
+			return err
+		}
+		if hash != entry.Hash {
+			errs = append(errs, fmt.Errorf("%w: entry %d content does not match its hash", ErrTampered, entry.Seq))
+		}
+		prevHash = entry.Hash
+	}
+	return errors.Join(errs...)
+}
+
+// Store - хранилище записей журнала. Записи только дописываются.
+type Store interface {
+	Append(ctx context.Context, entry Entry) error
+	Entries(ctx context.Context) ([]Entry, error)
+}
+
+// Log - журнал изменений заказов поверх Store. Назначает записям номера
+// и связывает их в цепочку хешей.
+type Log struct {
+	mu    sync.Mutex
+	store Store
+	now   func() time.Time
+	seq   uint64
+	head  string // хеш последней записи
+}
+
+// LogOption - необязательная настройка журнала
+type LogOption func(*Log)
+
+// WithClock задаёт источник времени записей
+func WithClock(now func() time.Time) LogOption {
+	return func(l *Log) {
+		l.now = now
+	}
+}
+
+// NewLog открывает журнал: проверяет уже сохранённые записи и продолжает
+// цепочку с последней из них
+func NewLog(ctx context.Context, store Store, opts ...LogOption) (*Log, error) {
+	l := &Log{store: store, now: time.Now}
+	for _, opt := range opts {
+		opt(l)
+	}
+	entries, err := store.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(entries); err != nil {
+		return nil, err
+	}
+	if n := len(entries); n > 0 {
+		l.seq, l.head = entries[n-1].Seq, entries[n-1].Hash
+	}
+	return l, nil
+}
+
+// Record дописывает запись об изменении заказа. Автор и идентификатор
+// запроса берутся из контекста (application.WithActor,
+// application.WithRequestID).
+func (l *Log) Record(ctx context.Context, action Action, orderID string, before, after *application.OrderView) (Entry, error) {
+	actor := application.ActorFromContext(ctx)
+	if actor == "" {
+		actor = ActorSystem
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := Entry{
+		Seq:       l.seq + 1,
+		At:        l.now().UTC(),
+		Actor:     actor,
+		RequestID: application.RequestIDFromContext(ctx),
+		Action:    action,
+		OrderID:   orderID,
+		Before:    before,
+		After:     after,
+		PrevHash:  l.head,
+	}
+	hash, err := computeHash(entry)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.Hash = hash
+	if err := l.store.Append(ctx, entry); err != nil {
+		return Entry{}, err
+	}
+	l.seq, l.head = entry.Seq, entry.Hash
+	return entry, nil
+}
+
+// Verify проверяет цепочку записей в хранилище. Помимо Verify
+// обнаруживает удаление последних записей, сделанных этим журналом.
+func (l *Log) Verify(ctx context.Context) error {
+	entries, err := l.store.Entries(ctx)
+	if err != nil {
+		return err
+	}
+	if err := Verify(entries); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	last := Entry{}
+	if n := len(entries); n > 0 {
+		last = entries[n-1]
+	}
+	if last.Seq != l.seq || last.Hash != l.head {
+		return fmt.Errorf("%w: expected %d entries, found %d", ErrTampered, l.seq, last.Seq)
+	}
+	return nil
+}
+
+// Entries возвращает записи об изменениях заказа orderID в порядке записи
+func (l *Log) Entries(ctx context.Context, orderID string) ([]Entry, error) {
+	entries, err := l.store.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result []Entry
+	for _, entry := range entries {
+		if entry.OrderID == orderID {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+)
+
+// OrderRepository - декоратор OrderRepository, который записывает в журнал
+// каждое сохранение, меняющее заказ. Сохранения без изменений в журнал
+// не попадают.
+type OrderRepository struct {
+	mu   sync.Mutex // упорядочивает чтение состояния "до", сохранение и запись
+	next application.OrderRepository
+	log  *Log
+}
+
+// NewOrderRepository создаёт декоратор репозитория, пишущий в журнал log
+func NewOrderRepository(next application.OrderRepository, log *Log) *OrderRepository {
+	return &OrderRepository{next: next, log: log}
+}
+
+// GetByID загружает заказ по идентификатору
+func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	return r.next.GetByID(ctx, orderID)
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу
+func (r *OrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	return r.next.Find(ctx, query)
+}
+
+// Save сохраняет заказ и записывает изменение в журнал. Если заказ
+// сохранён, а запись в журнал не удалась, возвращается ошибка журнала.
+func (r *OrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var before *application.OrderView
+	previous, err := r.next.GetByID(ctx, order.ID())
+	switch {
+	case err == nil:
+		view := application.NewOrderView(previous)
+		before = &view
+	case !errors.Is(err, application.ErrOrderNotFound):
+		return err
+	}
+
+	if err := r.next.Save(ctx, order); err != nil {
+		return err
+	}
+
+	after := application.NewOrderView(order)
+	action, changed := classify(before, &after)
+	if !changed {
+		return nil
+	}
+	if _, err := r.log.Record(ctx, action, order.ID(), before, &after); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// Update атомарно изменяет заказ и записывает изменение в журнал.
+// Состояние "до" берётся из заказа, загруженного для fn.
+func (r *OrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var before, after application.OrderView
+	err := r.next.Update(ctx, orderID, func(order *domain.Order) error {
+		before = application.NewOrderView(order)
+		if err := fn(order); err != nil {
+			return err
+		}
+		after = application.NewOrderView(order)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	action, changed := classify(&before, &after)
+	if !changed {
+		return nil
+	}
+	if _, err := r.log.Record(ctx, action, orderID, &before, &after); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// classify определяет вид изменения заказа; false - заказ не изменился
+func classify(before, after *application.OrderView) (Action, bool) {
+	if before == nil {
+		return ActionCreated, true
+	}
+	if before.Status != after.Status {
+		switch domain.OrderStatus(after.Status) {
+		case domain.OrderStatusPartiallyPaid:
+			return ActionInstallmentPaid, true
+		case domain.OrderStatusPaid:
+			if before.Status == domain.OrderStatusPartiallyPaid.String() {
+				return ActionInstallmentPaid, true
+			}
+			return ActionPaid, true
+		case domain.OrderStatusRefunded:
+			return ActionRefunded, true
+		case domain.OrderStatusExpired:
+			return ActionExpired, true
+		}
+		return ActionUpdated, true
+	}
+	switch {
+	case !sameJSON(before.Lines, after.Lines):
+		return ActionLinesChanged, true
+	case !sameJSON(before.Delivery, after.Delivery) || before.Shipping != after.Shipping:
+		return ActionDeliveryChanged, true
+	case !sameJSON(before.Installments, after.Installments):
+		return ActionInstallmentsScheduled, true
+	case !sameJSON(before, after):
+		return ActionUpdated, true
+	}
+	return "", false
+}
+
+// sameJSON сравнивает значения по их JSON-представлению; так время
+// сравнивается без учёта показаний монотонных часов
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && string(x) == string(y)
+}
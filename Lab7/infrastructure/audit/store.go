@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// MemoryStore хранит записи журнала в памяти
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemoryStore создаёт пустое хранилище
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append дописывает запись
+func (s *MemoryStore) Append(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Entries возвращает копию записей в порядке записи
+func (s *MemoryStore) Entries(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, len(s.entries))
+	copy(entries, s.entries)
+	return entries, nil
+}
+
+// FileStore хранит записи журнала в файле JSON Lines, по записи в строке.
+// Файл открывается только на дозапись; каждая запись сбрасывается на диск
+// до возврата из Append.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileStore открывает или создаёт файл журнала. Файл закрывается через Close.
+func NewFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Append дописывает запись в конец файла
+func (s *FileStore) Append(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Entries читает все записи из файла
+func (s *FileStore) Entries(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	decoder := json.NewDecoder(file)
+	for {
+		var entry Entry
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %w", ErrTampered, len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Close закрывает файл журнала
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
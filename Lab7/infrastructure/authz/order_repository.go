@@ -0,0 +1,123 @@
+// Package authz содержит декораторы репозитория и use-case, которые
+// проверяют права пользователя из контекста (application.WithPrincipal)
+// по политике доступа application.Policy.
+//
+// Без пользователя в контексте вызовы отклоняются с
+// application.ErrUnauthenticated, запрещённые политикой - с
+// *application.AccessDeniedError (errors.Is(err, application.ErrForbidden)).
+package authz
+
+import (
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+)
+
+// OrderRepository - декоратор OrderRepository с проверкой прав: чтение
+// заказа требует PermissionReadOrder, сохранение - PermissionWriteOrder,
+// поиск - PermissionListOrders. Пользователь с правом поиска только
+// своих заказов видит лишь их. Изменение заказа, оплату или возврат
+// которого уже разрешил декоратор use-case (PayOrderUseCase,
+// RefundOrderUseCase), право PermissionWriteOrder не требует.
+type OrderRepository struct {
+	next   application.OrderRepository
+	policy *application.Policy
+}
+
+// NewOrderRepository создаёт декоратор репозитория
+func NewOrderRepository(next application.OrderRepository, policy *application.Policy) *OrderRepository {
+	return &OrderRepository{next: next, policy: policy}
+}
+
+// GetByID загружает заказ, если пользователю разрешено его просматривать
+func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	principal, err := application.PrincipalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := r.next.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.policy.Authorize(principal, application.PermissionReadOrder, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Save сохраняет заказ, если пользователю разрешено его изменять. Для
+// существующего заказа владелец определяется по сохранённой версии,
+// поэтому чужой заказ нельзя присвоить, сменив в нём клиента.
+func (r *OrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	principal, err := application.PrincipalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if granted(ctx, principal, order.ID()) {
+		return r.next.Save(ctx, order)
+	}
+	stored, err := r.next.GetByID(ctx, order.ID())
+	switch {
+	case err == nil:
+		if err := r.policy.Authorize(principal, application.PermissionWriteOrder, stored); err != nil {
+			return err
+		}
+	case !errors.Is(err, application.ErrOrderNotFound):
+		return err
+	}
+	if err := r.policy.Authorize(principal, application.PermissionWriteOrder, order); err != nil {
+		return err
+	}
+	return r.next.Save(ctx, order)
+}
+
+// Update атомарно изменяет заказ, если пользователю разрешено изменять
+// его сохранённую версию и результат изменения
+func (r *OrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	principal, err := application.PrincipalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if granted(ctx, principal, orderID) {
+		return r.next.Update(ctx, orderID, fn)
+	}
+	return r.next.Update(ctx, orderID, func(order *domain.Order) error {
+		if err := r.policy.Authorize(principal, application.PermissionWriteOrder, order); err != nil {
+			return err
+		}
+		if err := fn(order); err != nil {
+			return err
+		}
+		return r.policy.Authorize(principal, application.PermissionWriteOrder, order)
+	})
+}
+
+// Find возвращает страницу заказов. Если пользователю разрешён поиск
+// только своих заказов, запрос ограничивается его заказами; запрос
+// чужих заказов отклоняется.
+func (r *OrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	principal, err := application.PrincipalFromContext(ctx)
+	if err != nil {
+		return application.OrderPage{}, err
+	}
+	switch r.policy.Scope(principal, application.PermissionListOrders) {
+	case application.ScopeAny:
+	case application.ScopeOwn:
+		if query.CustomerID != "" && query.CustomerID != principal.ID {
+			return application.OrderPage{}, &application.AccessDeniedError{
+				PrincipalID: principal.ID,
+				Permission:  application.PermissionListOrders,
+				Reason:      "orders of another customer",
+			}
+		}
+		query.CustomerID = principal.ID
+	default:
+		return application.OrderPage{}, &application.AccessDeniedError{
+			PrincipalID: principal.ID,
+			Permission:  application.PermissionListOrders,
+			Reason:      "no role grants this permission",
+		}
+	}
+	return r.next.Find(ctx, query)
+}
@@ -0,0 +1,94 @@
+package authz
+
+import (
+	"context"
+	"lab7/application"
+)
+
+// authorize проверяет право пользователя из контекста на действие над
+// заказом orderID. Заказ загружается из orders, чтобы узнать владельца.
+func authorize(ctx context.Context, orders application.OrderRepository, policy *application.Policy, permission application.Permission, orderID string) error {
+	principal, err := application.PrincipalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	order, err := orders.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return policy.Authorize(principal, permission, order)
+}
+
+// grantKey - ключ контекста с действием, уже разрешённым декоратором use-case
+type grantKey struct{}
+
+// grant - разрешённое пользователю действие над заказом
+type grant struct {
+	principalID string
+	orderID     string
+}
+
+// withGrant отмечает в контексте, что действие use-case над заказом
+// orderID разрешено. Декоратор репозитория пропускает изменение этого
+// заказа, поэтому use-case, обёрнутый декоратором, можно строить поверх
+// authz.OrderRepository: финансам не нужно право PermissionWriteOrder,
+// чтобы сохранить возвращённый заказ.
+func withGrant(ctx context.Context, orderID string) context.Context {
+	principal, _ := application.PrincipalFromContext(ctx)
+	return context.WithValue(ctx, grantKey{}, grant{principalID: principal.ID, orderID: orderID})
+}
+
+// granted сообщает, разрешено ли пользователю principal действие над
+// заказом orderID декоратором use-case
+func granted(ctx context.Context, principal application.Principal, orderID string) bool {
+	g, ok := ctx.Value(grantKey{}).(grant)
+	return ok && g.principalID == principal.ID && g.orderID == orderID
+}
+
+// PayOrderUseCase - декоратор use-case оплаты; требует PermissionPayOrder
+type PayOrderUseCase struct {
+	next   application.PayOrderExecutor
+	orders application.OrderRepository
+	policy *application.Policy
+}
+
+// NewPayOrderUseCase создаёт декоратор use-case оплаты. Репозиторий orders
+// используется только для определения владельца заказа.
+func NewPayOrderUseCase(next application.PayOrderExecutor, orders application.OrderRepository, policy *application.Policy) *PayOrderUseCase {
+	return &PayOrderUseCase{next: next, orders: orders, policy: policy}
+}
+
+// Execute выполняет оплату заказа, если она разрешена пользователю
+func (uc *PayOrderUseCase) Execute(ctx context.Context, orderID string) (application.PayOrderResult, error) {
+	return uc.ExecuteCommand(ctx, application.PayOrderCommand{OrderID: orderID})
+}
+
+// ExecuteCommand выполняет оплату заказа частями, если она разрешена
+// пользователю
+func (uc *PayOrderUseCase) ExecuteCommand(ctx context.Context, cmd application.PayOrderCommand) (application.PayOrderResult, error) {
+	if err := authorize(ctx, uc.orders, uc.policy, application.PermissionPayOrder, cmd.OrderID); err != nil {
+		return application.PayOrderResult{Success: false, Message: err.Error()}, err
+	}
+	return uc.next.ExecuteCommand(withGrant(ctx, cmd.OrderID), cmd)
+}
+
+// RefundOrderUseCase - декоратор use-case возврата; требует PermissionRefundOrder
+type RefundOrderUseCase struct {
+	next   application.RefundOrderExecutor
+	orders application.OrderRepository
+	policy *application.Policy
+}
+
+// NewRefundOrderUseCase создаёт декоратор use-case возврата. Репозиторий
+// orders используется только для определения владельца заказа.
+func NewRefundOrderUseCase(next application.RefundOrderExecutor, orders application.OrderRepository, policy *application.Policy) *RefundOrderUseCase {
+	return &RefundOrderUseCase{next: next, orders: orders, policy: policy}
+}
+
+// Execute выполняет возврат средств, если он разрешён пользователю
+func (uc *RefundOrderUseCase) Execute(ctx context.Context, orderID string) (application.RefundOrderResult, error) {
+	if err := authorize(ctx, uc.orders, uc.policy, application.PermissionRefundOrder, orderID); err != nil {
+		return application.RefundOrderResult{Success: false, Message: err.Error()}, err
+	}
+	return uc.next.Execute(withGrant(ctx, orderID), orderID)
+}
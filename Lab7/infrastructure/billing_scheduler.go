@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"time"
+)
+
+// DefaultBillingInterval - период проверки подписок по умолчанию
+const DefaultBillingInterval = time.Hour
+
+// BillingScheduler - фоновая горутина, которая периодически списывает
+// оплату по подпискам через BillSubscriptionsUseCase
+type BillingScheduler struct {
+	periodic
+	bill   *application.BillSubscriptionsUseCase
+	onBill func(result application.BillSubscriptionsResult, err error)
+}
+
+// BillingSchedulerOption - необязательная настройка BillingScheduler
+type BillingSchedulerOption func(*BillingScheduler)
+
+// BillingTicks задаёт источник сигналов к проходу вместо таймера
+func BillingTicks(ticks <-chan time.Time) BillingSchedulerOption {
+	return func(s *BillingScheduler) {
+		s.ticks = ticks
+	}
+}
+
+// OnBilling задаёт функцию, вызываемую после каждого прохода
+func OnBilling(fn func(result application.BillSubscriptionsResult, err error)) BillingSchedulerOption {
+	return func(s *BillingScheduler) {
+		s.onBill = fn
+	}
+}
+
+// NewBillingScheduler создаёт BillingScheduler с периодом interval; при
+// interval <= 0 используется DefaultBillingInterval
+func NewBillingScheduler(bill *application.BillSubscriptionsUseCase, interval time.Duration, opts ...BillingSchedulerOption) *BillingScheduler {
+	if interval <= 0 {
+		interval = DefaultBillingInterval
+	}
+	s := &BillingScheduler{bill: bill}
+	s.interval = interval
+	s.run = s.billDue
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start запускает фоновые списания. Горутина работает до Stop или до
+// отмены ctx.
+func (s *BillingScheduler) Start(ctx context.Context) error {
+	return s.start(ctx)
+}
+
+// Stop останавливает списания и ждёт завершения текущего прохода
+func (s *BillingScheduler) Stop() {
+	s.stop()
+}
+
+// billDue выполняет один проход
+func (s *BillingScheduler) billDue(ctx context.Context) {
+	result, err := s.bill.Execute(ctx)
+	if s.onBill != nil {
+		s.onBill(result, err)
+	}
+}
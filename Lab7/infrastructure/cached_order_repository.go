@@ -0,0 +1,249 @@
+package infrastructure
+
+import (
+	"container/list"
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL - время жизни заказа в кеше по умолчанию
+const DefaultCacheTTL = time.Minute
+
+// CacheStats - статистика обращений к кешу
+type CacheStats struct {
+	Hits      uint64 // заказ найден в кеше
+	Misses    uint64 // заказа не было в кеше или он устарел
+	Loads     uint64 // загрузки из хранилища; меньше Misses, если загрузки объединялись
+	Evictions uint64 // заказы, вытесненные из-за ограничения размера
+}
+
+// HitRatio возвращает долю попаданий; 0, если обращений не было
+func (s CacheStats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// cacheKey - ключ заказа в кеше. Заказы разных магазинов
+// (application.WithTenant) с одинаковым идентификатором хранятся раздельно;
+// без магазина в контексте tenantID пуст.
+type cacheKey struct {
+	tenantID string
+	orderID  string
+}
+
+// newCacheKey возвращает ключ заказа для магазина из контекста
+func newCacheKey(ctx context.Context, orderID string) cacheKey {
+	tenantID, _ := application.TenantFromContext(ctx)
+	return cacheKey{tenantID: tenantID, orderID: orderID}
+}
+
+// cacheEntry - заказ в кеше
+type cacheEntry struct {
+	key       cacheKey
+	order     *domain.Order
+	expiresAt time.Time
+}
+
+// cacheLoad - загрузка заказа из хранилища, которую ожидают все
+// одновременные запросы этого заказа
+type cacheLoad struct {
+	done  chan struct{}
+	order *domain.Order
+	err   error
+	stale bool // заказ сохранён во время загрузки; результат не кешируется
+}
+
+// CachedOrderRepository - декоратор OrderRepository, кеширующий GetByID.
+// Кеш ограничен по размеру (вытесняются давно не использованные заказы) и
+// по времени жизни записи. Одновременные запросы отсутствующего в кеше
+// заказа загружают его из хранилища один раз. Save сбрасывает заказ из
+// кеша; Find всегда обращается к хранилищу. Кеш хранит и возвращает копии
+// заказов, поэтому изменения полученного заказа не видны другим. Записи
+// различаются по магазину из контекста, поэтому декоратор можно ставить
+// поверх TenantOrderRepository.
+type CachedOrderRepository struct {
+	next     application.OrderRepository
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element // значения - *cacheEntry
+	lru     *list.List                 // в начале - недавно использованные
+	loads   map[cacheKey]*cacheLoad
+	stats   CacheStats
+}
+
+// CacheOption - необязательная настройка кеша
+type CacheOption func(*CachedOrderRepository)
+
+// CacheTTL задаёт время жизни заказа в кеше; по умолчанию DefaultCacheTTL
+func CacheTTL(ttl time.Duration) CacheOption {
+	return func(r *CachedOrderRepository) {
+		r.ttl = ttl
+	}
+}
+
+// CacheClock задаёт источник времени для проверки срока жизни записей
+func CacheClock(now func() time.Time) CacheOption {
+	return func(r *CachedOrderRepository) {
+		r.now = now
+	}
+}
+
+// NewCachedOrderRepository создаёт кеширующий декоратор, хранящий не
+// больше capacity заказов
+func NewCachedOrderRepository(next application.OrderRepository, capacity int, opts ...CacheOption) *CachedOrderRepository {
+	r := &CachedOrderRepository{
+		next:     next,
+		capacity: max(capacity, 1),
+		ttl:      DefaultCacheTTL,
+		now:      time.Now,
+		entries:  make(map[cacheKey]*list.Element),
+		lru:      list.New(),
+		loads:    make(map[cacheKey]*cacheLoad),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetByID возвращает копию заказа из кеша или загружает его из хранилища.
+// Ошибки загрузки, в том числе ErrOrderNotFound, не кешируются.
+func (r *CachedOrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	key := newCacheKey(ctx, orderID)
+	r.mu.Lock()
+	if order, ok := r.lookup(key); ok {
+		r.stats.Hits++
+		r.mu.Unlock()
+		return copyOrder(order), nil
+	}
+	r.stats.Misses++
+	load, ok := r.loads[key]
+	if !ok {
+		load = &cacheLoad{done: make(chan struct{})}
+		r.loads[key] = load
+		r.stats.Loads++
+		// Загрузка не зависит от отмены запроса, который её начал: её
+		// результат ждут и другие запросы
+		go r.load(context.WithoutCancel(ctx), key, load)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-load.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if load.err != nil {
+		return nil, load.err
+	}
+	return copyOrder(load.order), nil
+}
+
+// load загружает заказ из хранилища и кладёт его в кеш
+func (r *CachedOrderRepository) load(ctx context.Context, key cacheKey, load *cacheLoad) {
+	load.order, load.err = r.next.GetByID(ctx, key.orderID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loads[key] == load {
+		delete(r.loads, key)
+	}
+	if load.err == nil && !load.stale {
+		r.store(key, load.order)
+	}
+	close(load.done)
+}
+
+// Save сохраняет заказ и сбрасывает его из кеша, в том числе если
+// сохранение не удалось
+func (r *CachedOrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	err := r.next.Save(ctx, order)
+	r.Invalidate(ctx, order.ID())
+	return err
+}
+
+// Update атомарно изменяет заказ в хранилище и сбрасывает его из кеша.
+// Заказ для fn всегда загружается из хранилища, а не из кеша.
+func (r *CachedOrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	err := r.next.Update(ctx, orderID, fn)
+	r.Invalidate(ctx, orderID)
+	return err
+}
+
+// Find возвращает страницу заказов из хранилища в обход кеша
+func (r *CachedOrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	return r.next.Find(ctx, query)
+}
+
+// Invalidate сбрасывает из кеша заказ магазина из контекста. Идущая загрузка заказа завершится
+// для ожидающих её запросов, но её результат не попадёт в кеш.
+func (r *CachedOrderRepository) Invalidate(ctx context.Context, orderID string) {
+	key := newCacheKey(ctx, orderID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if element, ok := r.entries[key]; ok {
+		r.remove(element)
+	}
+	if load, ok := r.loads[key]; ok {
+		load.stale = true
+		delete(r.loads, key)
+	}
+}
+
+// Stats возвращает статистику обращений к кешу
+func (r *CachedOrderRepository) Stats() CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Len возвращает число заказов в кеше
+func (r *CachedOrderRepository) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lru.Len()
+}
+
+// lookup возвращает заказ из кеша, если он там есть и не устарел
+func (r *CachedOrderRepository) lookup(key cacheKey) (*domain.Order, bool) {
+	element, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !r.now().Before(entry.expiresAt) {
+		r.remove(element)
+		return nil, false
+	}
+	r.lru.MoveToFront(element)
+	return entry.order, true
+}
+
+// store кладёт копию заказа в кеш, вытесняя давно не использованные
+func (r *CachedOrderRepository) store(key cacheKey, order *domain.Order) {
+	entry := &cacheEntry{key: key, order: copyOrder(order), expiresAt: r.now().Add(r.ttl)}
+	if element, ok := r.entries[key]; ok {
+		element.Value = entry
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.entries[key] = r.lru.PushFront(entry)
+	for r.lru.Len() > r.capacity {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+}
+
+// remove удаляет запись из кеша
+func (r *CachedOrderRepository) remove(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*cacheEntry).key)
+}
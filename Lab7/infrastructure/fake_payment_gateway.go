@@ -1,47 +1,102 @@
 package infrastructure
 
 import (
+	"context"
 	"errors"
+	"lab7/application"
 	"lab7/domain"
+	"math/rand/v2"
 	"sync"
+	"time"
 )
 
 // PaymentRecord - запись об оплате
 type PaymentRecord struct {
 	OrderID string
+	Method  domain.PaymentMethod
 	Amount  domain.Money
 }
 
-// FakePaymentGateway - фейковая реализация платёжного шлюза для тестирования
+// FakePaymentGateway - фейковая реализация платёжного шлюза для тестирования.
+// Сбои задаются сценариями (SetScenarios) или целиком через SetShouldFail.
 type FakePaymentGateway struct {
 	mu            sync.RWMutex
 	payments      []PaymentRecord
+	refunds       []PaymentRecord
 	shouldFail    bool
 	failureReason string
+
+	scenarios []*faultScenarioState
+	rng       *rand.Rand
+	now       func() time.Time
+	calls     []GatewayCall
+	callCount map[string]int
+	attempts  map[string]int
 }
 
 // NewFakePaymentGateway создаёт новый фейковый платёжный шлюз
 func NewFakePaymentGateway() *FakePaymentGateway {
 	return &FakePaymentGateway{
 		payments:   make([]PaymentRecord, 0),
+		refunds:    make([]PaymentRecord, 0),
 		shouldFail: false,
+		rng:        newFaultRand(0),
+		now:        time.Now,
+		calls:      make([]GatewayCall, 0),
+		callCount:  make(map[string]int),
+		attempts:   make(map[string]int),
 	}
 }
 
 // Charge выполняет списание средств
-func (g *FakePaymentGateway) Charge(orderID string, money domain.Money) error {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+func (g *FakePaymentGateway) Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	return g.call(ctx, GatewayOperationCharge, orderID, method, money, &g.payments)
+}
 
+// Refund возвращает ранее списанные средства
+func (g *FakePaymentGateway) Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	return g.call(ctx, GatewayOperationRefund, orderID, method, money, &g.refunds)
+}
+
+// call выполняет операцию с учётом сценариев сбоев и записывает её в историю
+func (g *FakePaymentGateway) call(ctx context.Context, operation, orderID string, method domain.PaymentMethod, money domain.Money, records *[]PaymentRecord) error {
+	g.mu.Lock()
+	g.callCount[operation]++
+	g.attempts[operation+"/"+orderID]++
+	record := GatewayCall{
+		Operation: operation,
+		OrderID:   orderID,
+		Method:    method,
+		Amount:    money,
+		Call:      g.callCount[operation],
+		Attempt:   g.attempts[operation+"/"+orderID],
+		At:        g.now(),
+	}
+	decision := decide(g.scenarios, g.rng, operation, orderID, method, money, record.Call)
 	if g.shouldFail {
-		return errors.New(g.failureReason)
+		decision.err = errors.New(g.failureReason)
+	}
+	g.mu.Unlock()
+
+	// Задержка выдерживается без блокировки, чтобы не задерживать другие вызовы
+	record.Latency = decision.latency
+	record.Scenario = decision.scenario
+	record.Err = decision.err
+	if err := sleep(ctx, decision.latency); err != nil {
+		record.Err = err
 	}
 
-	g.payments = append(g.payments, PaymentRecord{
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls = append(g.calls, record)
+	if record.Err != nil {
+		return record.Err
+	}
+	*records = append(*records, PaymentRecord{
 		OrderID: orderID,
+		Method:  method,
 		Amount:  money,
 	})
-
 	return nil
 }
 
@@ -55,6 +110,52 @@ func (g *FakePaymentGateway) GetPayments() []PaymentRecord {
 	return paymentsCopy
 }
 
+// GetRefunds возвращает список всех возвратов
+func (g *FakePaymentGateway) GetRefunds() []PaymentRecord {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	refundsCopy := make([]PaymentRecord, len(g.refunds))
+	copy(refundsCopy, g.refunds)
+	return refundsCopy
+}
+
+// Transactions возвращает успешные списания и возвраты в порядке вызовов
+// (application.TransactionLog)
+func (g *FakePaymentGateway) Transactions(ctx context.Context) ([]application.Transaction, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	transactions := make([]application.Transaction, 0, len(g.payments)+len(g.refunds))
+	for _, call := range g.calls {
+		if call.Err != nil {
+			continue
+		}
+		kind := application.TransactionCharge
+		if call.Operation == GatewayOperationRefund {
+			kind = application.TransactionRefund
+		}
+		transactions = append(transactions, application.Transaction{
+			Kind:    kind,
+			OrderID: call.OrderID,
+			Method:  call.Method,
+			Amount:  call.Amount,
+			At:      call.At,
+		})
+	}
+	return transactions, nil
+}
+
+// GetCalls возвращает историю всех вызовов, включая неудачные
+func (g *FakePaymentGateway) GetCalls() []GatewayCall {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	callsCopy := make([]GatewayCall, len(g.calls))
+	copy(callsCopy, g.calls)
+	return callsCopy
+}
+
 // SetShouldFail устанавливает, должен ли шлюз симулировать ошибку
 func (g *FakePaymentGateway) SetShouldFail(shouldFail bool, reason string) {
 	g.mu.Lock()
@@ -64,12 +165,64 @@ func (g *FakePaymentGateway) SetShouldFail(shouldFail bool, reason string) {
 	g.failureReason = reason
 }
 
+// SetScenarios заменяет сценарии сбоев; счётчики срабатываний обнуляются
+func (g *FakePaymentGateway) SetScenarios(scenarios ...FaultScenario) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.scenarios = make([]*faultScenarioState, len(scenarios))
+	for i, scenario := range scenarios {
+		g.scenarios[i] = &faultScenarioState{scenario: scenario}
+	}
+}
+
+// SetSeed задаёт seed генератора для сценариев с Rate
+func (g *FakePaymentGateway) SetSeed(seed uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.rng = newFaultRand(seed)
+}
+
+// SetClock задаёт источник времени для истории вызовов
+func (g *FakePaymentGateway) SetClock(now func() time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.now = now
+}
+
 // Reset сбрасывает состояние шлюза
 func (g *FakePaymentGateway) Reset() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	g.payments = make([]PaymentRecord, 0)
+	g.refunds = make([]PaymentRecord, 0)
 	g.shouldFail = false
 	g.failureReason = ""
+	g.scenarios = nil
+	g.calls = make([]GatewayCall, 0)
+	g.callCount = make(map[string]int)
+	g.attempts = make(map[string]int)
+}
+
+// newFaultRand создаёт детерминированный генератор для seed
+func newFaultRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// sleep ждёт d или отмены ctx; для уже отменённого ctx сразу возвращает ошибку
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,377 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// orderLineRecord - формат строки заказа в файле
+type orderLineRecord struct {
+	ProductID   string `json:"product_id"`
+	Name        string `json:"name,omitempty"`
+	UnitPrice   int64  `json:"unit_price"`
+	Currency    string `json:"currency"`
+	Quantity    int    `json:"quantity"`
+	WeightGrams int    `json:"weight_grams,omitempty"` // вес единицы товара
+	VATRate     string `json:"vat_rate,omitempty"`
+}
+
+// deliveryRecord - формат доставки заказа в файле
+type deliveryRecord struct {
+	Method     string `json:"method"`
+	Country    string `json:"country"`
+	Region     string `json:"region,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Street     string `json:"street"`
+	// Shipping и ShippingCurrency - рассчитанная стоимость доставки;
+	// пустая валюта означает, что доставка ещё не рассчитана
+	Shipping         int64  `json:"shipping,omitempty"`
+	ShippingCurrency string `json:"shipping_currency,omitempty"`
+}
+
+// paymentLegRecord - формат части оплаты в файле
+type paymentLegRecord struct {
+	Method    string `json:"method,omitempty"` // пусто - способ по умолчанию
+	Reference string `json:"reference,omitempty"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Refunded  bool   `json:"refunded,omitempty"`
+}
+
+// installmentRecord - формат платежа рассрочки в файле
+type installmentRecord struct {
+	Number   int        `json:"number"`
+	DueAt    time.Time  `json:"due_at"`
+	Amount   int64      `json:"amount"`
+	Currency string     `json:"currency"`
+	PaidAt   *time.Time `json:"paid_at,omitempty"`
+}
+
+// orderRecord - формат заказа в файле
+type orderRecord struct {
+	ID           string              `json:"id"`
+	CustomerID   string              `json:"customer_id,omitempty"`
+	Status       string              `json:"status"`
+	CreatedAt    time.Time           `json:"created_at"`
+	PayBy        *time.Time          `json:"pay_by,omitempty"` // срок оплаты, если задан
+	Lines        []orderLineRecord   `json:"lines"`
+	Delivery     *deliveryRecord     `json:"delivery,omitempty"`
+	Payments     []paymentLegRecord  `json:"payments,omitempty"`
+	Installments []installmentRecord `json:"installments,omitempty"`
+}
+
+// orderFile - корневой объект файла с заказами
+type orderFile struct {
+	Orders []orderRecord `json:"orders"`
+}
+
+// FileOrderRepository - реализация OrderRepository поверх JSON-файла.
+// Заказы загружаются в память при создании репозитория, а каждое
+// сохранение атомарно перезаписывает файл целиком. Репозиторий безопасен
+// для использования из нескольких горутин, но не из нескольких процессов.
+type FileOrderRepository struct {
+	mu     sync.Mutex
+	path   string
+	memory *InMemoryOrderRepository
+}
+
+// NewFileOrderRepository открывает файловый репозиторий.
+// Если файла ещё нет, он будет создан при первом сохранении.
+func NewFileOrderRepository(path string) (*FileOrderRepository, error) {
+	r := &FileOrderRepository{
+		path:   path,
+		memory: NewInMemoryOrderRepository(),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetByID загружает заказ по идентификатору
+func (r *FileOrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	return r.memory.GetByID(ctx, orderID)
+}
+
+// Save сохраняет заказ и записывает изменения на диск. Если записать
+// файл не удалось, заказ в памяти возвращается к прежней версии.
+func (r *FileOrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, err := r.memory.GetByID(ctx, order.ID())
+	if err != nil && !errors.Is(err, application.ErrOrderNotFound) {
+		return err
+	}
+	if err := r.memory.Save(ctx, order); err != nil {
+		return err
+	}
+	return r.flushOrRestore(order.ID(), previous)
+}
+
+// Update атомарно изменяет заказ и записывает изменения на диск. Если
+// записать файл не удалось, заказ в памяти возвращается к прежней версии.
+func (r *FileOrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, err := r.memory.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if err := r.memory.Update(ctx, orderID, fn); err != nil {
+		return err
+	}
+	return r.flushOrRestore(orderID, previous)
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу
+func (r *FileOrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	return r.memory.Find(ctx, query)
+}
+
+// load читает заказы из файла
+func (r *FileOrderRepository) load() error {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file orderFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", r.path, err)
+	}
+
+	for _, record := range file.Orders {
+		order, err := record.toOrder()
+		if err != nil {
+			return fmt.Errorf("failed to decode order %s: %w", record.ID, err)
+		}
+		if err := r.memory.Save(context.Background(), order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushOrRestore записывает заказы в файл; при ошибке возвращает в память
+// прежнюю версию заказа orderID или удаляет его, если прежней версии не было
+func (r *FileOrderRepository) flushOrRestore(orderID string, previous *domain.Order) error {
+	err := r.flush()
+	if err == nil {
+		return nil
+	}
+	if previous == nil {
+		r.memory.delete(orderID)
+	} else {
+		r.memory.Save(context.Background(), previous)
+	}
+	return err
+}
+
+// flush атомарно записывает все заказы в файл
+func (r *FileOrderRepository) flush() error {
+	orders, err := r.memory.List()
+	if err != nil {
+		return err
+	}
+
+	file := orderFile{Orders: make([]orderRecord, 0, len(orders))}
+	for _, order := range orders {
+		file.Orders = append(file.Orders, newOrderRecord(order))
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить
+	// наполовину записанный файл при сбое
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// newOrderRecord переводит заказ в формат файла
+func newOrderRecord(order *domain.Order) orderRecord {
+	record := orderRecord{
+		ID:         order.ID(),
+		CustomerID: order.CustomerID(),
+		Status:     order.Status().String(),
+		CreatedAt:  order.CreatedAt(),
+		Lines:      make([]orderLineRecord, 0, len(order.Lines())),
+	}
+	if payBy := order.PayBy(); !payBy.IsZero() {
+		record.PayBy = &payBy
+	}
+	for _, line := range order.Lines() {
+		snapshot := line.Snapshot()
+		record.Lines = append(record.Lines, orderLineRecord{
+			ProductID:   snapshot.ProductID,
+			Name:        snapshot.Name,
+			UnitPrice:   snapshot.Price.Amount(),
+			Currency:    snapshot.Price.Currency(),
+			Quantity:    snapshot.Quantity,
+			WeightGrams: snapshot.WeightGrams,
+			VATRate:     snapshot.VATRate.String(),
+		})
+	}
+	for _, leg := range order.Payments() {
+		record.Payments = append(record.Payments, paymentLegRecord{
+			Method:    leg.Method.Kind().String(),
+			Reference: leg.Method.Reference(),
+			Amount:    leg.Amount.Amount(),
+			Currency:  leg.Amount.Currency(),
+			Refunded:  leg.Refunded,
+		})
+	}
+	for _, installment := range order.Installments() {
+		rec := installmentRecord{
+			Number:   installment.Number,
+			DueAt:    installment.DueAt,
+			Amount:   installment.Amount.Amount(),
+			Currency: installment.Amount.Currency(),
+		}
+		if installment.IsPaid() {
+			rec.PaidAt = &installment.PaidAt
+		}
+		record.Installments = append(record.Installments, rec)
+	}
+	if delivery := order.Delivery(); !delivery.IsZero() {
+		address := delivery.Address()
+		record.Delivery = &deliveryRecord{
+			Method:           delivery.Method().String(),
+			Country:          address.Country(),
+			Region:           address.Region(),
+			City:             address.City(),
+			PostalCode:       address.PostalCode(),
+			Street:           address.Street(),
+			Shipping:         order.Shipping().Amount(),
+			ShippingCurrency: order.Shipping().Currency(),
+		}
+	}
+	return record
+}
+
+// toOrder восстанавливает заказ из формата файла
+func (rec orderRecord) toOrder() (*domain.Order, error) {
+	status, err := domain.ParseOrderStatus(rec.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]domain.OrderLine, 0, len(rec.Lines))
+	for _, l := range rec.Lines {
+		price, err := domain.NewMoney(l.UnitPrice, l.Currency)
+		if err != nil {
+			return nil, err
+		}
+		line, err := domain.RestoreOrderLine(domain.OrderLineSnapshot{
+			ProductID:   l.ProductID,
+			Name:        l.Name,
+			Price:       price,
+			Quantity:    l.Quantity,
+			WeightGrams: l.WeightGrams,
+			VATRate:     domain.VATRate(l.VATRate),
+		})
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	snapshot := domain.OrderSnapshot{
+		ID:         rec.ID,
+		CustomerID: rec.CustomerID,
+		Lines:      lines,
+		Status:     status,
+		CreatedAt:  rec.CreatedAt,
+	}
+	if rec.PayBy != nil {
+		snapshot.PayBy = *rec.PayBy
+	}
+	for _, p := range rec.Payments {
+		leg, err := p.toPaymentLeg()
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Payments = append(snapshot.Payments, leg)
+	}
+	for _, i := range rec.Installments {
+		amount, err := domain.NewMoney(i.Amount, i.Currency)
+		if err != nil {
+			return nil, err
+		}
+		installment := domain.Installment{Number: i.Number, DueAt: i.DueAt, Amount: amount}
+		if i.PaidAt != nil {
+			installment.PaidAt = *i.PaidAt
+		}
+		snapshot.Installments = append(snapshot.Installments, installment)
+	}
+	if rec.Delivery != nil {
+		if snapshot.Delivery, snapshot.Shipping, err = rec.Delivery.toDelivery(); err != nil {
+			return nil, err
+		}
+	}
+	return domain.RestoreOrder(snapshot), nil
+}
+
+// toPaymentLeg восстанавливает часть оплаты из формата файла
+func (rec paymentLegRecord) toPaymentLeg() (domain.PaymentLeg, error) {
+	amount, err := domain.NewMoney(rec.Amount, rec.Currency)
+	if err != nil {
+		return domain.PaymentLeg{}, err
+	}
+	leg := domain.PaymentLeg{Amount: amount, Refunded: rec.Refunded}
+	if rec.Method != "" {
+		if leg.Method, err = domain.ParsePaymentMethod(rec.Method, rec.Reference); err != nil {
+			return domain.PaymentLeg{}, err
+		}
+	}
+	return leg, nil
+}
+
+// toDelivery восстанавливает доставку и её стоимость из формата файла
+func (rec deliveryRecord) toDelivery() (domain.Delivery, domain.Money, error) {
+	method, err := domain.ParseDeliveryMethod(rec.Method)
+	if err != nil {
+		return domain.Delivery{}, domain.Money{}, err
+	}
+	address, err := domain.NewAddress(rec.Country, rec.Region, rec.City, rec.PostalCode, rec.Street)
+	if err != nil {
+		return domain.Delivery{}, domain.Money{}, err
+	}
+	delivery, err := domain.NewDelivery(address, method)
+	if err != nil {
+		return domain.Delivery{}, domain.Money{}, err
+	}
+	if rec.ShippingCurrency == "" {
+		return delivery, domain.Money{}, nil
+	}
+	shipping, err := domain.NewMoney(rec.Shipping, rec.ShippingCurrency)
+	return delivery, shipping, err
+}
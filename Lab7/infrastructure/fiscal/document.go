@@ -0,0 +1,382 @@
+// Package fiscal формирует фискальные документы (чеки по 54-ФЗ) для
+// передачи оператору фискальных данных.
+//
+// Generator превращает оплаченный или возвращённый заказ в Document:
+// позиции с тегами ставки НДС (1199), признаком способа расчёта (1214) и
+// признаком предмета расчёта (1212), оплаты по видам и суммы НДС по
+// ставкам. Marshal сериализует документ детерминированно и проверяет его
+// по локальной схеме (schema.json).
+package fiscal
+
+import (
+	"errors"
+	"fmt"
+	"lab7/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Currency - валюта фискальных документов
+const Currency = "RUB"
+
+// TimestampLayout - формат времени документа, принятый операторами
+const TimestampLayout = "02.01.2006 15:04:05"
+
+// Operation - признак расчёта (тег 1054)
+type Operation string
+
+const (
+	// OperationSell - приход
+	OperationSell Operation = "sell"
+	// OperationSellRefund - возврат прихода
+	OperationSellRefund Operation = "sell_refund"
+)
+
+// VATType - ставка НДС позиции (тег 1199)
+type VATType string
+
+const (
+	// VATTypeNone - без НДС
+	VATTypeNone VATType = "none"
+	// VATTypeVAT0 - НДС 0%
+	VATTypeVAT0 VATType = "vat0"
+	// VATTypeVAT10 - НДС 10%
+	VATTypeVAT10 VATType = "vat10"
+	// VATTypeVAT20 - НДС 20%
+	VATTypeVAT20 VATType = "vat20"
+)
+
+// PaymentMethod - признак способа расчёта (тег 1214)
+type PaymentMethod string
+
+const (
+	// PaymentMethodFullPayment - полный расчёт
+	PaymentMethodFullPayment PaymentMethod = "full_payment"
+	// PaymentMethodPartialPayment - частичный расчёт и кредит: по рассрочке
+	// внесена только часть платежей
+	PaymentMethodPartialPayment PaymentMethod = "partial_payment"
+)
+
+// PaymentObject - признак предмета расчёта (тег 1212)
+type PaymentObject string
+
+const (
+	// PaymentObjectCommodity - товар
+	PaymentObjectCommodity PaymentObject = "commodity"
+	// PaymentObjectService - услуга, например доставка
+	PaymentObjectService PaymentObject = "service"
+)
+
+// PaymentType - вид оплаты в чеке
+type PaymentType int
+
+const (
+	// PaymentTypeCash - наличными (тег 1031)
+	PaymentTypeCash PaymentType = 0
+	// PaymentTypeElectronic - безналичными (тег 1081)
+	PaymentTypeElectronic PaymentType = 1
+	// PaymentTypePrepaid - зачёт предоплаты, например подарочной картой (тег 1215)
+	PaymentTypePrepaid PaymentType = 2
+	// PaymentTypeCredit - постоплата (кредит), невнесённые платежи рассрочки (тег 1216)
+	PaymentTypeCredit PaymentType = 3
+)
+
+// Amount - сумма в копейках. В JSON записывается в рублях с двумя
+// знаками после точки, без потерь на округлении.
+type Amount int64
+
+// MarshalJSON записывает сумму как число с двумя знаками после точки
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a < 0 {
+		return nil, fmt.Errorf("negative amount %d", int64(a))
+	}
+	return []byte(a.String()), nil
+}
+
+// String возвращает сумму в рублях
+func (a Amount) String() string {
+	return fmt.Sprintf("%d.%02d", a/100, a%100)
+}
+
+// UnmarshalJSON читает сумму в рублях, допускается не более двух знаков
+// после точки
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 {
+		return fmt.Errorf("amount %s has more than 2 decimal places", s)
+	}
+	rubles, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rubles < 0 {
+		return fmt.Errorf("invalid amount %s", s)
+	}
+	kopecks := int64(0)
+	if frac != "" {
+		if kopecks, err = strconv.ParseInt(frac+strings.Repeat("0", 2-len(frac)), 10, 64); err != nil {
+			return fmt.Errorf("invalid amount %s", s)
+		}
+	}
+	*a = Amount(rubles*100 + kopecks)
+	return nil
+}
+
+// Company - реквизиты продавца
+type Company struct {
+	INN            string `json:"inn"`             // ИНН, 10 или 12 цифр (тег 1018)
+	SNO            string `json:"sno"`             // система налогообложения (тег 1055)
+	Email          string `json:"email"`           // email отправителя чека (тег 1117)
+	PaymentAddress string `json:"payment_address"` // место расчётов, адрес сайта (тег 1187)
+}
+
+// Client - контакт покупателя для отправки чека (тег 1008).
+// Должен быть указан email или телефон.
+type Client struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// ItemVAT - НДС позиции
+type ItemVAT struct {
+	Type VATType `json:"type"`
+	Sum  Amount  `json:"sum"`
+}
+
+// Item - предмет расчёта
+type Item struct {
+	Name          string        `json:"name"`     // тег 1030
+	Price         Amount        `json:"price"`    // тег 1079
+	Quantity      int           `json:"quantity"` // тег 1023
+	Sum           Amount        `json:"sum"`      // тег 1043
+	PaymentMethod PaymentMethod `json:"payment_method"`
+	PaymentObject PaymentObject `json:"payment_object"`
+	VAT           ItemVAT       `json:"vat"`
+}
+
+// Payment - оплата одного вида
+type Payment struct {
+	Type PaymentType `json:"type"`
+	Sum  Amount      `json:"sum"`
+}
+
+// VATTotal - сумма НДС по одной ставке
+type VATTotal struct {
+	Type VATType `json:"type"`
+	Sum  Amount  `json:"sum"`
+}
+
+// Receipt - содержимое чека
+type Receipt struct {
+	Client   Client     `json:"client"`
+	Company  Company    `json:"company"`
+	Items    []Item     `json:"items"`
+	Payments []Payment  `json:"payments"`
+	VATs     []VATTotal `json:"vats"`
+	Total    Amount     `json:"total"`
+}
+
+// Document - фискальный документ для оператора фискальных данных
+type Document struct {
+	ExternalID string    `json:"external_id"` // идентификатор заказа
+	Operation  Operation `json:"operation"`
+	Timestamp  string    `json:"timestamp"`
+	Receipt    Receipt   `json:"receipt"`
+}
+
+// Generator - генератор фискальных документов по заказам
+type Generator struct {
+	company     Company
+	shippingVAT domain.VATRate
+	now         func() time.Time
+}
+
+// GeneratorOption - необязательная настройка генератора
+type GeneratorOption func(*Generator)
+
+// WithClock задаёт источник времени документов
+func WithClock(now func() time.Time) GeneratorOption {
+	return func(g *Generator) {
+		g.now = now
+	}
+}
+
+// ShippingVAT задаёт ставку НДС доставки; по умолчанию доставка без НДС
+func ShippingVAT(rate domain.VATRate) GeneratorOption {
+	return func(g *Generator) {
+		g.shippingVAT = rate
+	}
+}
+
+// NewGenerator создаёт генератор документов от имени продавца company
+func NewGenerator(company Company, opts ...GeneratorOption) *Generator {
+	g := &Generator{company: company, shippingVAT: domain.VATNone, now: time.Now}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Generate формирует документ по заказу: приход для оплаченного заказа и
+// возврат прихода для возвращённого. Заказ должен быть в рублях. Если по
+// рассрочке внесена только часть платежей (возврат частично оплаченного
+// заказа), позиции получают признак частичного расчёта, а оплаты - только
+// внесённые суммы и постоплату на остаток итога.
+func (g *Generator) Generate(order *domain.Order, client Client) (Document, error) {
+	var operation Operation
+	switch order.Status() {
+	case domain.OrderStatusPaid:
+		operation = OperationSell
+	case domain.OrderStatusRefunded:
+		operation = OperationSellRefund
+	default:
+		return Document{}, fmt.Errorf("%w: order %s", domain.ErrOrderNotPaid, order.ID())
+	}
+	total, err := order.Total()
+	if err != nil {
+		return Document{}, err
+	}
+	if total.Currency() != Currency {
+		return Document{}, fmt.Errorf("%w: fiscal receipts are issued in %s, order %s is in %s",
+			domain.ErrCurrencyMismatch, Currency, order.ID(), total.Currency())
+	}
+
+	receipt := Receipt{
+		Client:  client,
+		Company: g.company,
+		Items:   make([]Item, 0, len(order.Lines())+1),
+		Total:   Amount(total.Amount()),
+	}
+	method := paymentMethod(order)
+	for _, line := range order.Lines() {
+		name := line.Name()
+		if name == "" {
+			name = line.ProductID()
+		}
+		receipt.Items = append(receipt.Items, newItem(name, line.Price(), line.Quantity(), line.VATRate(), method, PaymentObjectCommodity))
+	}
+	if shipping := order.Shipping(); !shipping.IsZero() {
+		receipt.Items = append(receipt.Items, newItem("Доставка", shipping, 1, g.shippingVAT, method, PaymentObjectService))
+	}
+	receipt.VATs = vatTotals(receipt.Items)
+	receipt.Payments = payments(order.Payments(), total)
+
+	return Document{
+		ExternalID: order.ID(),
+		Operation:  operation,
+		Timestamp:  g.now().Format(TimestampLayout),
+		Receipt:    receipt,
+	}, nil
+}
+
+// paymentMethod определяет признак способа расчёта по графику оплаты
+// заказа: частичный расчёт, если по рассрочке внесены не все платежи
+func paymentMethod(order *domain.Order) PaymentMethod {
+	for _, installment := range order.Installments() {
+		if !installment.IsPaid() {
+			return PaymentMethodPartialPayment
+		}
+	}
+	return PaymentMethodFullPayment
+}
+
+// newItem строит позицию чека с выделенным НДС
+func newItem(name string, price domain.Money, quantity int, rate domain.VATRate, method PaymentMethod, object PaymentObject) Item {
+	sum, _ := domain.NewMoney(price.Amount()*int64(quantity), price.Currency())
+	return Item{
+		Name:          name,
+		Price:         Amount(price.Amount()),
+		Quantity:      quantity,
+		Sum:           Amount(sum.Amount()),
+		PaymentMethod: method,
+		PaymentObject: object,
+		VAT:           ItemVAT{Type: vatType(rate), Sum: Amount(rate.Included(sum).Amount())},
+	}
+}
+
+// vatType переводит доменную ставку НДС в значение тега 1199
+func vatType(rate domain.VATRate) VATType {
+	switch rate {
+	case domain.VAT0:
+		return VATTypeVAT0
+	case domain.VAT10:
+		return VATTypeVAT10
+	case domain.VAT20:
+		return VATTypeVAT20
+	default:
+		return VATTypeNone
+	}
+}
+
+// vatTotals суммирует НДС позиций по ставкам; ставки упорядочены по имени
+func vatTotals(items []Item) []VATTotal {
+	sums := make(map[VATType]Amount)
+	for _, item := range items {
+		sums[item.VAT.Type] += item.VAT.Sum
+	}
+	totals := make([]VATTotal, 0, len(sums))
+	for vatType, sum := range sums {
+		totals = append(totals, VATTotal{Type: vatType, Sum: sum})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Type < totals[j].Type })
+	return totals
+}
+
+// payments суммирует части оплаты заказа по видам оплаты. Заказ без
+// частей считается оплаченным безналично целиком; не внесённая часть
+// итога указывается постоплатой.
+func payments(legs []domain.PaymentLeg, total domain.Money) []Payment {
+	if len(legs) == 0 {
+		return []Payment{{Type: PaymentTypeElectronic, Sum: Amount(total.Amount())}}
+	}
+	sums := make(map[PaymentType]Amount)
+	paid := Amount(0)
+	for _, leg := range legs {
+		sums[paymentType(leg.Method)] += Amount(leg.Amount.Amount())
+		paid += Amount(leg.Amount.Amount())
+	}
+	if rest := Amount(total.Amount()) - paid; rest > 0 {
+		sums[PaymentTypeCredit] += rest
+	}
+	result := make([]Payment, 0, len(sums))
+	for paymentType, sum := range sums {
+		result = append(result, Payment{Type: paymentType, Sum: sum})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	return result
+}
+
+// paymentType определяет вид оплаты в чеке по способу оплаты
+func paymentType(method domain.PaymentMethod) PaymentType {
+	switch method.Kind() {
+	case domain.PaymentMethodCashOnDelivery:
+		return PaymentTypeCash
+	case domain.PaymentMethodGiftCard:
+		return PaymentTypePrepaid
+	default:
+		return PaymentTypeElectronic
+	}
+}
+
+// checkTotals проверяет согласованность сумм документа: сумма позиции
+// равна цене на количество, итог равен сумме позиций и сумме оплат
+func checkTotals(doc Document) error {
+	var errs []error
+	var items, paid Amount
+	for i, item := range doc.Receipt.Items {
+		if item.Sum != item.Price*Amount(item.Quantity) {
+			errs = append(errs, fmt.Errorf("%w: receipt.items[%d].sum %s is not price * quantity", ErrInvalidDocument, i, item.Sum))
+		}
+		items += item.Sum
+	}
+	for _, payment := range doc.Receipt.Payments {
+		paid += payment.Sum
+	}
+	if items != doc.Receipt.Total {
+		errs = append(errs, fmt.Errorf("%w: receipt.total %s differs from items sum %s", ErrInvalidDocument, doc.Receipt.Total, items))
+	}
+	if paid != doc.Receipt.Total {
+		errs = append(errs, fmt.Errorf("%w: receipt.total %s differs from payments sum %s", ErrInvalidDocument, doc.Receipt.Total, paid))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,203 @@
+package fiscal
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrInvalidDocument - документ не соответствует схеме или его суммы не сходятся
+var ErrInvalidDocument = errors.New("invalid fiscal document")
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// documentSchema - разобранная схема документа
+var documentSchema = mustParseSchema(schemaJSON)
+
+// schema - подмножество JSON Schema, которого достаточно для описания
+// фискального документа
+type schema struct {
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	MinProperties        int                `json:"minProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             int                `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Enum                 []any              `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            int                `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// mustParseSchema разбирает схему и компилирует шаблоны строк
+func mustParseSchema(data []byte) *schema {
+	var s schema
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		panic(fmt.Sprintf("fiscal: invalid schema: %v", err))
+	}
+	s.compile()
+	return &s
+}
+
+func (s *schema) compile() {
+	if s.Pattern != "" {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	for _, p := range s.Properties {
+		p.compile()
+	}
+	if s.Items != nil {
+		s.Items.compile()
+	}
+}
+
+// Marshal сериализует документ в компактный JSON и проверяет его по схеме.
+// Порядок полей фиксирован, поэтому одинаковые документы дают
+// одинаковые байты.
+func Marshal(doc Document) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate проверяет JSON-документ по схеме и сходимость его сумм.
+// Возвращает все найденные нарушения, каждое обёрнуто в ErrInvalidDocument.
+func Validate(data []byte) error {
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	var errs []error
+	documentSchema.validate("$", value, &errs)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	return checkTotals(doc)
+}
+
+// validate проверяет значение value по схеме и добавляет нарушения в errs
+func (s *schema) validate(path string, value any, errs *[]error) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fmt.Errorf("%w: %s: %s", ErrInvalidDocument, path, fmt.Sprintf(format, args...)))
+	}
+	if !s.hasType(value) {
+		fail("expected %s", s.Type)
+		return
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, value) {
+		fail("value %v is not one of %v", value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		if len(v) < s.MinProperties {
+			fail("expected at least %d properties", s.MinProperties)
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			if p, ok := s.Properties[name]; ok {
+				p.validate(path+"."+name, v[name], errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				fail("unexpected property %q", name)
+			}
+		}
+	case []any:
+		if len(v) < s.MinItems {
+			fail("expected at least %d items", s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("expected at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if length < s.MinLength {
+			fail("expected at least %d characters", s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("expected at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("%q does not match %s", v, s.Pattern)
+		}
+	case json.Number:
+		n, _ := v.Float64()
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("%s is less than %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("%s is greater than %v", v, *s.Maximum)
+		}
+	}
+}
+
+// hasType проверяет JSON-тип значения; пустой тип допускает любое значение
+func (s *schema) hasType(value any) bool {
+	switch s.Type {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(n.String(), 10, 64)
+		return err == nil
+	default:
+		return true
+	}
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+)
+
+// InMemoryCustomerRepository - in-memory реализация CustomerRepository
+type InMemoryCustomerRepository struct {
+	mu        sync.RWMutex
+	customers map[string]*domain.Customer
+}
+
+// NewInMemoryCustomerRepository создаёт новый in-memory репозиторий клиентов
+func NewInMemoryCustomerRepository() *InMemoryCustomerRepository {
+	return &InMemoryCustomerRepository{
+		customers: make(map[string]*domain.Customer),
+	}
+}
+
+// GetByID загружает клиента по идентификатору
+func (r *InMemoryCustomerRepository) GetByID(ctx context.Context, customerID string) (*domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	customer, exists := r.customers[customerID]
+	if !exists {
+		return nil, application.ErrCustomerNotFound
+	}
+
+	// Возвращаем копию, чтобы изменения не влияли на хранилище
+	return domain.RestoreCustomer(customer.Snapshot()), nil
+}
+
+// Save сохраняет клиента
+func (r *InMemoryCustomerRepository) Save(ctx context.Context, customer *domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.customers[customer.ID()] = domain.RestoreCustomer(customer.Snapshot())
+	return nil
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+)
+
+// InMemoryGiftCardRepository - in-memory реализация GiftCardRepository
+type InMemoryGiftCardRepository struct {
+	mu    sync.Mutex
+	cards map[string]*domain.GiftCard
+}
+
+// NewInMemoryGiftCardRepository создаёт новый in-memory репозиторий подарочных карт
+func NewInMemoryGiftCardRepository() *InMemoryGiftCardRepository {
+	return &InMemoryGiftCardRepository{
+		cards: make(map[string]*domain.GiftCard),
+	}
+}
+
+// GetByID загружает карту по идентификатору
+func (r *InMemoryGiftCardRepository) GetByID(ctx context.Context, cardID string) (*domain.GiftCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	card, exists := r.cards[cardID]
+	if !exists {
+		return nil, application.ErrGiftCardNotFound
+	}
+
+	// Возвращаем копию, чтобы изменения не влияли на хранилище
+	return domain.RestoreGiftCard(card.Snapshot()), nil
+}
+
+// Save сохраняет карту
+func (r *InMemoryGiftCardRepository) Save(ctx context.Context, card *domain.GiftCard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cards[card.ID()] = domain.RestoreGiftCard(card.Snapshot())
+	return nil
+}
+
+// Update атомарно изменяет карту. Блокировка удерживается на время fn,
+// поэтому fn не должна обращаться к репозиторию.
+func (r *InMemoryGiftCardRepository) Update(ctx context.Context, cardID string, fn func(card *domain.GiftCard) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.cards[cardID]
+	if !exists {
+		return application.ErrGiftCardNotFound
+	}
+
+	// fn работает с копией: при ошибке хранилище не меняется
+	card := domain.RestoreGiftCard(stored.Snapshot())
+	if err := fn(card); err != nil {
+		return err
+	}
+	r.cards[cardID] = card
+	return nil
+}
@@ -0,0 +1,162 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"lab7/application"
+	"sync"
+	"time"
+)
+
+// DefaultReservationTTL - срок действия резерва по умолчанию
+const DefaultReservationTTL = 15 * time.Minute
+
+// reservation - резерв товаров одного заказа
+type reservation struct {
+	items     map[string]int
+	expiresAt time.Time
+}
+
+// InMemoryInventory - in-memory реализация InventoryService.
+// Доступный остаток товара равен количеству на складе за вычетом
+// действующих резервов; истёкшие резервы удаляются при каждом обращении.
+type InMemoryInventory struct {
+	mu           sync.Mutex
+	ttl          time.Duration
+	now          func() time.Time
+	stock        map[string]int
+	reservations map[string]*reservation
+}
+
+// NewInMemoryInventory создаёт пустой склад; при ttl <= 0 используется
+// DefaultReservationTTL
+func NewInMemoryInventory(ttl time.Duration) *InMemoryInventory {
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	return &InMemoryInventory{
+		ttl:          ttl,
+		now:          time.Now,
+		stock:        make(map[string]int),
+		reservations: make(map[string]*reservation),
+	}
+}
+
+// SetStock устанавливает количество товара на складе
+func (inv *InMemoryInventory) SetStock(productID string, quantity int) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.stock[productID] = quantity
+}
+
+// SetClock задаёт источник времени для сроков резервов
+func (inv *InMemoryInventory) SetClock(now func() time.Time) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.now = now
+}
+
+// OnHand возвращает количество товара на складе, включая зарезервированный
+func (inv *InMemoryInventory) OnHand(productID string) int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	return inv.stock[productID]
+}
+
+// Available возвращает количество товара, доступное для резервирования
+func (inv *InMemoryInventory) Available(productID string) int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.expire()
+	return inv.available(productID)
+}
+
+// Reserve резервирует товары заказа
+func (inv *InMemoryInventory) Reserve(ctx context.Context, orderID string, items []application.StockItem) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.expire()
+	previous := inv.reservations[orderID]
+	delete(inv.reservations, orderID)
+
+	requested := make(map[string]int, len(items))
+	for _, item := range items {
+		requested[item.ProductID] += item.Quantity
+	}
+	for productID, quantity := range requested {
+		if available := inv.available(productID); quantity > available {
+			if previous != nil {
+				inv.reservations[orderID] = previous
+			}
+			return fmt.Errorf("%w: product %s: requested %d, available %d",
+				application.ErrInsufficientStock, productID, quantity, available)
+		}
+	}
+
+	inv.reservations[orderID] = &reservation{
+		items:     requested,
+		expiresAt: inv.now().Add(inv.ttl),
+	}
+	return nil
+}
+
+// Commit списывает зарезервированные товары со склада
+func (inv *InMemoryInventory) Commit(ctx context.Context, orderID string) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.expire()
+	r, ok := inv.reservations[orderID]
+	if !ok {
+		return fmt.Errorf("%w: order %s", application.ErrReservationNotFound, orderID)
+	}
+	for productID, quantity := range r.items {
+		inv.stock[productID] -= quantity
+	}
+	delete(inv.reservations, orderID)
+	return nil
+}
+
+// Release снимает резерв заказа
+func (inv *InMemoryInventory) Release(ctx context.Context, orderID string) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	delete(inv.reservations, orderID)
+	return nil
+}
+
+// Restock возвращает товары на склад
+func (inv *InMemoryInventory) Restock(ctx context.Context, items []application.StockItem) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for _, item := range items {
+		inv.stock[item.ProductID] += item.Quantity
+	}
+	return nil
+}
+
+// available возвращает свободный остаток; вызывается под блокировкой
+func (inv *InMemoryInventory) available(productID string) int {
+	available := inv.stock[productID]
+	for _, r := range inv.reservations {
+		available -= r.items[productID]
+	}
+	return available
+}
+
+// expire удаляет истёкшие резервы; вызывается под блокировкой
+func (inv *InMemoryInventory) expire() {
+	now := inv.now()
+	for orderID, r := range inv.reservations {
+		if !now.Before(r.expiresAt) {
+			delete(inv.reservations, orderID)
+		}
+	}
+}
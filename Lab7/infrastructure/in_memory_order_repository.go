@@ -1,50 +1,187 @@
 package infrastructure
 
 import (
-	"errors"
+	"context"
+	"lab7/application"
 	"lab7/domain"
+	"sort"
 	"sync"
 )
 
 // InMemoryOrderRepository - реализация OrderRepository в памяти
 type InMemoryOrderRepository struct {
-	mu     sync.RWMutex
-	orders map[string]*domain.Order
+	mu      sync.RWMutex
+	orders  map[string]*domain.Order
+	indexes *orderIndexes
 }
 
 // NewInMemoryOrderRepository создаёт новый репозиторий в памяти
 func NewInMemoryOrderRepository() *InMemoryOrderRepository {
 	return &InMemoryOrderRepository{
-		orders: make(map[string]*domain.Order),
+		orders:  make(map[string]*domain.Order),
+		indexes: newOrderIndexes(),
 	}
 }
 
 // GetByID загружает заказ по идентификатору
-func (r *InMemoryOrderRepository) GetByID(orderID string) (*domain.Order, error) {
+func (r *InMemoryOrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	order, exists := r.orders[orderID]
 	if !exists {
-		return nil, errors.New("order not found")
+		return nil, application.ErrOrderNotFound
 	}
 
 	// Возвращаем копию заказа для изоляции
-	return r.copyOrder(order), nil
+	return copyOrder(order), nil
 }
 
 // Save сохраняет заказ
-func (r *InMemoryOrderRepository) Save(order *domain.Order) error {
+func (r *InMemoryOrderRepository) Save(ctx context.Context, order *domain.Order) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	// Обновляем вторичные индексы по старой и новой версии заказа
+	if previous, exists := r.orders[order.ID()]; exists {
+		r.indexes.remove(previous)
+	}
+
 	// Сохраняем копию заказа
-	r.orders[order.ID()] = r.copyOrder(order)
+	stored := copyOrder(order)
+	r.orders[order.ID()] = stored
+	r.indexes.add(stored)
+	return nil
+}
+
+// Update атомарно изменяет заказ. Блокировка удерживается на время fn,
+// поэтому fn не должна обращаться к репозиторию.
+func (r *InMemoryOrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.orders[orderID]
+	if !exists {
+		return application.ErrOrderNotFound
+	}
+
+	// fn работает с копией: при ошибке хранилище не меняется
+	order := copyOrder(previous)
+	if err := fn(order); err != nil {
+		return err
+	}
+	r.indexes.remove(previous)
+	stored := copyOrder(order)
+	r.orders[orderID] = stored
+	r.indexes.add(stored)
 	return nil
 }
 
+// delete удаляет заказ вместе с записями индексов
+func (r *InMemoryOrderRepository) delete(orderID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, exists := r.orders[orderID]; exists {
+		r.indexes.remove(previous)
+		delete(r.orders, orderID)
+	}
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу.
+// Фильтры по статусу, валюте и продукту обслуживаются хеш-индексами,
+// диапазоны по сумме и времени создания - упорядоченными индексами.
+func (r *InMemoryOrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return application.OrderPage{}, err
+	}
+	cursor, hasCursor, err := application.DecodeCursor(query)
+	if err != nil {
+		return application.OrderPage{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries sortedIndex
+	if candidates, restricted := r.indexes.candidates(query); restricted {
+		// Упорядочиваем только кандидатов, отобранных индексами
+		entries = make(sortedIndex, 0, len(candidates))
+		for id := range candidates {
+			order := r.orders[id]
+			if query.Matches(order) {
+				entries = append(entries, indexEntry{key: application.OrderSortKey(order, query.SortBy), id: id})
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].less(entries[j]) })
+	} else {
+		// Без фильтров результат - это сам индекс сортировки
+		entries = r.indexes.sortIndex(query.SortBy)
+	}
+
+	page, more := pageEntries(entries, query, cursor, hasCursor)
+
+	result := application.OrderPage{Orders: make([]*domain.Order, 0, len(page))}
+	for _, e := range page {
+		result.Orders = append(result.Orders, copyOrder(r.orders[e.id]))
+	}
+	if more {
+		last := page[len(page)-1]
+		result.NextCursor = application.EncodeCursor(application.OrderCursor{
+			SortBy:     query.SortBy,
+			Descending: query.Descending,
+			Key:        last.key,
+			ID:         last.id,
+		})
+	}
+	return result, nil
+}
+
+// List возвращает копии всех заказов, упорядоченные по идентификатору
+func (r *InMemoryOrderRepository) List() ([]*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make([]*domain.Order, 0, len(r.indexes.byID))
+	for _, e := range r.indexes.byID {
+		orders = append(orders, copyOrder(r.orders[e.id]))
+	}
+	return orders, nil
+}
+
 // copyOrder создаёт копию заказа для изоляции
-func (r *InMemoryOrderRepository) copyOrder(order *domain.Order) *domain.Order {
-	lines := order.Lines()
-	return domain.ReconstructOrder(order.ID(), lines, order.Status())
+func copyOrder(order *domain.Order) *domain.Order {
+	return domain.RestoreOrder(order.Snapshot())
+}
+
+// pageEntries выбирает из упорядоченных entries страницу после курсора
+// и сообщает, остались ли элементы за её пределами
+func pageEntries(entries sortedIndex, q application.OrderQuery, cursor application.OrderCursor, hasCursor bool) (sortedIndex, bool) {
+	after := indexEntry{key: cursor.Key, id: cursor.ID}
+
+	if !q.Descending {
+		start := 0
+		if hasCursor {
+			// Первый элемент строго после курсора
+			start = entries.search(after)
+			if start < len(entries) && entries[start] == after {
+				start++
+			}
+		}
+		end := min(start+q.Limit, len(entries))
+		return entries[start:end], end < len(entries)
+	}
+
+	end := len(entries)
+	if hasCursor {
+		// Элементы строго перед курсором в порядке возрастания
+		end = entries.search(after)
+	}
+	start := max(end-q.Limit, 0)
+	page := make(sortedIndex, 0, end-start)
+	for i := end - 1; i >= start; i-- {
+		page = append(page, entries[i])
+	}
+	return page, start > 0
 }
@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"os"
+	"sync"
+)
+
+// InMemoryProductCatalog - in-memory реализация ProductCatalog
+type InMemoryProductCatalog struct {
+	mu       sync.RWMutex
+	products map[string]*domain.Product
+}
+
+// NewInMemoryProductCatalog создаёт пустой каталог
+func NewInMemoryProductCatalog() *InMemoryProductCatalog {
+	return &InMemoryProductCatalog{
+		products: make(map[string]*domain.Product),
+	}
+}
+
+// GetByID возвращает товар по идентификатору
+func (c *InMemoryProductCatalog) GetByID(ctx context.Context, productID string) (*domain.Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	product, exists := c.products[productID]
+	if !exists {
+		return nil, application.ErrProductNotFound
+	}
+
+	// Возвращаем копию, чтобы изменения не влияли на каталог
+	return domain.RestoreProduct(product.Snapshot()), nil
+}
+
+// Save добавляет или обновляет товар
+func (c *InMemoryProductCatalog) Save(ctx context.Context, product *domain.Product) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.products[product.ID()] = domain.RestoreProduct(product.Snapshot())
+	return nil
+}
+
+// productRecord - формат товара в файле каталога
+type productRecord struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Price       int64  `json:"price"`
+	Currency    string `json:"currency"`
+	WeightGrams int    `json:"weight_grams,omitempty"`
+	VATRate     string `json:"vat_rate,omitempty"`
+	// Active по умолчанию true
+	Active *bool `json:"active,omitempty"`
+}
+
+// LoadProductCatalog загружает каталог из JSON-файла вида
+// {"products":[{"id":"laptop","name":"Laptop","price":15000,"currency":"RUB"}]}
+func LoadProductCatalog(path string) (*InMemoryProductCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Products []productRecord `json:"products"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	catalog := NewInMemoryProductCatalog()
+	for _, rec := range file.Products {
+		price, err := domain.NewMoney(rec.Price, rec.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", rec.ID, err)
+		}
+		product, err := domain.NewProduct(rec.ID, rec.Name, price)
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", rec.ID, err)
+		}
+		if err := product.SetWeight(rec.WeightGrams); err != nil {
+			return nil, fmt.Errorf("product %s: %w", rec.ID, err)
+		}
+		if err := product.SetVATRate(domain.VATRate(rec.VATRate)); err != nil {
+			return nil, fmt.Errorf("product %s: %w", rec.ID, err)
+		}
+		if rec.Active != nil && !*rec.Active {
+			product.Deactivate()
+		}
+		catalog.products[product.ID()] = product
+	}
+	return catalog, nil
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InMemorySubscriptionRepository - in-memory реализация SubscriptionRepository
+type InMemorySubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*domain.Subscription
+}
+
+// NewInMemorySubscriptionRepository создаёт новый in-memory репозиторий подписок
+func NewInMemorySubscriptionRepository() *InMemorySubscriptionRepository {
+	return &InMemorySubscriptionRepository{
+		subscriptions: make(map[string]*domain.Subscription),
+	}
+}
+
+// GetByID загружает подписку по идентификатору
+func (r *InMemorySubscriptionRepository) GetByID(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, exists := r.subscriptions[subscriptionID]
+	if !exists {
+		return nil, application.ErrSubscriptionNotFound
+	}
+
+	// Возвращаем копию, чтобы изменения не влияли на хранилище
+	return domain.RestoreSubscription(subscription.Snapshot()), nil
+}
+
+// Save сохраняет подписку
+func (r *InMemorySubscriptionRepository) Save(ctx context.Context, subscription *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.ID()] = domain.RestoreSubscription(subscription.Snapshot())
+	return nil
+}
+
+// FindDue возвращает копии подписок, по которым пора списывать оплату
+func (r *InMemorySubscriptionRepository) FindDue(ctx context.Context, now time.Time) ([]*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []*domain.Subscription
+	for _, subscription := range r.subscriptions {
+		if subscription.IsDue(now) {
+			due = append(due, domain.RestoreSubscription(subscription.Snapshot()))
+		}
+	}
+	slices.SortFunc(due, func(a, b *domain.Subscription) int {
+		return strings.Compare(a.ID(), b.ID())
+	})
+	return due, nil
+}
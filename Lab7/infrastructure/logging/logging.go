@@ -0,0 +1,86 @@
+// Package logging содержит декораторы портов и use-case, которые пишут
+// структурированные логи через log/slog.
+//
+// Каждая запись содержит операцию, идентификатор заказа, длительность,
+// исход (success/failure) и код ошибки (application.ErrorCode), а также
+// идентификатор запроса и магазин из контекста (application.WithRequestID,
+// application.WithTenant).
+package logging
+
+import (
+	"context"
+	"lab7/application"
+	"log/slog"
+	"time"
+)
+
+// Ключи атрибутов записей
+const (
+	KeyOperation     = "operation"
+	KeyRequestID     = "request_id"
+	KeyTenant        = "tenant"
+	KeyOrderID       = "order_id"
+	KeyAmount        = "amount"
+	KeyCurrency      = "currency"
+	KeyPaymentMethod = "payment_method"
+	KeyDuration      = "duration"
+	KeyOutcome       = "outcome"
+	KeyErrorCode     = "error_code"
+	KeyError         = "error"
+)
+
+// Исходы операций
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// logCall пишет запись о завершённой операции. Успешные операции пишутся
+// на уровне successLevel, неудачные - на уровне Warn для ожидаемых
+// бизнес-ошибок и Error для внутренних.
+func logCall(ctx context.Context, logger *slog.Logger, successLevel slog.Level, operation string, start time.Time, err error, attrs ...slog.Attr) {
+	level := successLevel
+	outcome := OutcomeSuccess
+	code := application.ErrorCode(err)
+	if err != nil {
+		outcome = OutcomeFailure
+		level = slog.LevelWarn
+		switch code {
+		case application.ErrorCodeInternal, application.ErrorCodeGatewayDown, application.ErrorCodeDeclined,
+			application.ErrorCodePaymentFailed, application.ErrorCodeRefundFailed:
+			level = slog.LevelError
+		}
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	record := make([]slog.Attr, 0, len(attrs)+8)
+	record = append(record, slog.String(KeyOperation, operation))
+	if requestID := application.RequestIDFromContext(ctx); requestID != "" {
+		record = append(record, slog.String(KeyRequestID, requestID))
+	}
+	if tenantID, err := application.TenantFromContext(ctx); err == nil {
+		record = append(record, slog.String(KeyTenant, tenantID))
+	}
+	record = append(record, attrs...)
+	record = append(record,
+		slog.Duration(KeyDuration, time.Since(start)),
+		slog.String(KeyOutcome, outcome),
+		slog.String(KeyErrorCode, code),
+	)
+	if err != nil {
+		record = append(record, slog.String(KeyError, err.Error()))
+	}
+
+	logger.LogAttrs(ctx, level, operation+" "+outcome, record...)
+}
+
+// orderAttrs возвращает атрибуты заказа; сумма добавляется, если известна
+func orderAttrs(orderID string, amount int64, currency string) []slog.Attr {
+	attrs := []slog.Attr{slog.String(KeyOrderID, orderID)}
+	if currency != "" {
+		attrs = append(attrs, slog.Int64(KeyAmount, amount), slog.String(KeyCurrency, currency))
+	}
+	return attrs
+}
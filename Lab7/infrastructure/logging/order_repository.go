@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"log/slog"
+	"time"
+)
+
+// OrderRepository - декоратор OrderRepository, логирующий каждое обращение.
+// Успешные обращения пишутся на уровне Debug.
+type OrderRepository struct {
+	next   application.OrderRepository
+	logger *slog.Logger
+}
+
+// NewOrderRepository создаёт логирующий декоратор репозитория
+func NewOrderRepository(next application.OrderRepository, logger *slog.Logger) *OrderRepository {
+	return &OrderRepository{next: next, logger: logger}
+}
+
+// GetByID загружает заказ по идентификатору
+func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	start := time.Now()
+	order, err := r.next.GetByID(ctx, orderID)
+	logCall(ctx, r.logger, slog.LevelDebug, "repository.get", start, err, slog.String(KeyOrderID, orderID))
+	return order, err
+}
+
+// Save сохраняет заказ
+func (r *OrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	start := time.Now()
+	err := r.next.Save(ctx, order)
+	logCall(ctx, r.logger, slog.LevelDebug, "repository.save", start, err,
+		slog.String(KeyOrderID, order.ID()),
+		slog.String("status", order.Status().String()))
+	return err
+}
+
+// Update атомарно изменяет заказ
+func (r *OrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	start := time.Now()
+	err := r.next.Update(ctx, orderID, fn)
+	logCall(ctx, r.logger, slog.LevelDebug, "repository.update", start, err, slog.String(KeyOrderID, orderID))
+	return err
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу
+func (r *OrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	start := time.Now()
+	page, err := r.next.Find(ctx, query)
+	logCall(ctx, r.logger, slog.LevelDebug, "repository.find", start, err, slog.Int("results", len(page.Orders)))
+	return page, err
+}
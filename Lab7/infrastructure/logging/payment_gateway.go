@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"log/slog"
+	"time"
+)
+
+// PaymentGateway - декоратор PaymentGateway, логирующий списания и возвраты
+type PaymentGateway struct {
+	next   application.PaymentGateway
+	logger *slog.Logger
+}
+
+// NewPaymentGateway создаёт логирующий декоратор платёжного шлюза
+func NewPaymentGateway(next application.PaymentGateway, logger *slog.Logger) *PaymentGateway {
+	return &PaymentGateway{next: next, logger: logger}
+}
+
+// Charge выполняет списание средств
+func (g *PaymentGateway) Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	start := time.Now()
+	err := g.next.Charge(ctx, orderID, method, money)
+	logCall(ctx, g.logger, slog.LevelInfo, "gateway.charge", start, err,
+		paymentAttrs(orderID, method, money)...)
+	return err
+}
+
+// Refund возвращает ранее списанные средства
+func (g *PaymentGateway) Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	start := time.Now()
+	err := g.next.Refund(ctx, orderID, method, money)
+	logCall(ctx, g.logger, slog.LevelInfo, "gateway.refund", start, err,
+		paymentAttrs(orderID, method, money)...)
+	return err
+}
+
+// paymentAttrs возвращает атрибуты платежа; реквизиты способа оплаты
+// не логируются, только его вид
+func paymentAttrs(orderID string, method domain.PaymentMethod, money domain.Money) []slog.Attr {
+	attrs := orderAttrs(orderID, money.Amount(), money.Currency())
+	if !method.IsZero() {
+		attrs = append(attrs, slog.String(KeyPaymentMethod, method.Kind().String()))
+	}
+	return attrs
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue - значение, которым заменяются чувствительные данные
+const RedactedValue = "[REDACTED]"
+
+// DefaultSensitiveKeys - ключи атрибутов, значения которых всегда скрываются
+var DefaultSensitiveKeys = []string{
+	"card_number", "card_token", "cvv", "pan",
+	"email", "phone", "password", "token", "authorization", "secret",
+}
+
+// cardNumberPattern находит последовательности цифр, похожие на номер
+// карты, в том числе разделённые пробелами или дефисами
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// RedactingHandler - slog.Handler, скрывающий чувствительные данные перед
+// передачей записи следующему обработчику: значения атрибутов с
+// чувствительными ключами заменяются на RedactedValue, а номера карт в
+// строках и сообщениях маскируются до последних четырёх цифр.
+type RedactingHandler struct {
+	next slog.Handler
+	keys map[string]bool
+}
+
+// NewRedactingHandler создаёт обработчик; если keys не заданы,
+// используются DefaultSensitiveKeys
+func NewRedactingHandler(next slog.Handler, keys ...string) *RedactingHandler {
+	if len(keys) == 0 {
+		keys = DefaultSensitiveKeys
+	}
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return &RedactingHandler{next: next, keys: set}
+}
+
+// Enabled сообщает, обрабатывается ли уровень level
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle скрывает чувствительные данные и передаёт запись дальше
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, maskCardNumbers(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redact(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs возвращает обработчик с дополнительными атрибутами
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redact(attr)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), keys: h.keys}
+}
+
+// WithGroup возвращает обработчик для группы атрибутов
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+// redact возвращает атрибут со скрытыми чувствительными данными
+func (h *RedactingHandler) redact(attr slog.Attr) slog.Attr {
+	if h.keys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, RedactedValue)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redacted[i] = h.redact(member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		return slog.String(attr.Key, maskCardNumbers(value.String()))
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, maskCardNumbers(err.Error()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+// maskCardNumbers оставляет от номеров карт только последние четыре
+// цифры; разделители сохраняются
+func maskCardNumbers(s string) string {
+	return cardNumberPattern.ReplaceAllStringFunc(s, func(number string) string {
+		masked := []byte(number)
+		keep := 4
+		for i := len(masked) - 1; i >= 0; i-- {
+			if masked[i] < '0' || masked[i] > '9' {
+				continue
+			}
+			if keep > 0 {
+				keep--
+				continue
+			}
+			masked[i] = '*'
+		}
+		return string(masked)
+	})
+}
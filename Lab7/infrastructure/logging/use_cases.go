@@ -0,0 +1,340 @@
+package logging
+
+import (
+	"context"
+	"lab7/application"
+	"log/slog"
+	"time"
+)
+
+// PayOrderUseCase - декоратор use-case оплаты заказа
+type PayOrderUseCase struct {
+	next   application.PayOrderExecutor
+	logger *slog.Logger
+}
+
+// NewPayOrderUseCase создаёт логирующий декоратор use-case оплаты
+func NewPayOrderUseCase(next application.PayOrderExecutor, logger *slog.Logger) *PayOrderUseCase {
+	return &PayOrderUseCase{next: next, logger: logger}
+}
+
+// Execute выполняет оплату заказа
+func (uc *PayOrderUseCase) Execute(ctx context.Context, orderID string) (application.PayOrderResult, error) {
+	return uc.ExecuteCommand(ctx, application.PayOrderCommand{OrderID: orderID})
+}
+
+// ExecuteCommand выполняет оплату заказа частями
+func (uc *PayOrderUseCase) ExecuteCommand(ctx context.Context, cmd application.PayOrderCommand) (application.PayOrderResult, error) {
+	start := time.Now()
+	result, err := uc.next.ExecuteCommand(ctx, cmd)
+	logCall(ctx, uc.logger, slog.LevelInfo, "pay_order", start, err,
+		orderAttrs(cmd.OrderID, result.Amount, result.Currency)...)
+	return result, err
+}
+
+// RefundOrderUseCase - декоратор use-case возврата средств
+type RefundOrderUseCase struct {
+	next   application.RefundOrderExecutor
+	logger *slog.Logger
+}
+
+// NewRefundOrderUseCase создаёт логирующий декоратор use-case возврата
+func NewRefundOrderUseCase(next application.RefundOrderExecutor, logger *slog.Logger) *RefundOrderUseCase {
+	return &RefundOrderUseCase{next: next, logger: logger}
+}
+
+// Execute выполняет возврат средств за заказ
+func (uc *RefundOrderUseCase) Execute(ctx context.Context, orderID string) (application.RefundOrderResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx, orderID)
+	logCall(ctx, uc.logger, slog.LevelInfo, "refund_order", start, err,
+		orderAttrs(orderID, result.Amount, result.Currency)...)
+	return result, err
+}
+
+// CreateOrderUseCase - декоратор use-case создания заказа
+type CreateOrderUseCase struct {
+	next   application.CreateOrderExecutor
+	logger *slog.Logger
+}
+
+// NewCreateOrderUseCase создаёт логирующий декоратор use-case создания заказа
+func NewCreateOrderUseCase(next application.CreateOrderExecutor, logger *slog.Logger) *CreateOrderUseCase {
+	return &CreateOrderUseCase{next: next, logger: logger}
+}
+
+// Execute создаёт заказ
+func (uc *CreateOrderUseCase) Execute(ctx context.Context, cmd application.CreateOrderCommand) (application.OrderView, error) {
+	start := time.Now()
+	view, err := uc.next.Execute(ctx, cmd)
+	logCall(ctx, uc.logger, slog.LevelInfo, "create_order", start, err, slog.String(KeyOrderID, cmd.OrderID))
+	return view, err
+}
+
+// AddOrderLineUseCase - декоратор use-case добавления строки в заказ
+type AddOrderLineUseCase struct {
+	next   application.AddOrderLineExecutor
+	logger *slog.Logger
+}
+
+// NewAddOrderLineUseCase создаёт логирующий декоратор use-case добавления строки
+func NewAddOrderLineUseCase(next application.AddOrderLineExecutor, logger *slog.Logger) *AddOrderLineUseCase {
+	return &AddOrderLineUseCase{next: next, logger: logger}
+}
+
+// Execute добавляет строку в заказ
+func (uc *AddOrderLineUseCase) Execute(ctx context.Context, cmd application.AddOrderLineCommand) (application.OrderView, error) {
+	start := time.Now()
+	view, err := uc.next.Execute(ctx, cmd)
+	logCall(ctx, uc.logger, slog.LevelInfo, "add_order_line", start, err,
+		append(orderAttrs(cmd.OrderID, view.Total, view.Currency),
+			slog.String("product_id", cmd.ProductID),
+			slog.Int("quantity", cmd.Quantity))...)
+	return view, err
+}
+
+// SetDeliveryUseCase - декоратор use-case выбора доставки
+type SetDeliveryUseCase struct {
+	next   application.SetDeliveryExecutor
+	logger *slog.Logger
+}
+
+// NewSetDeliveryUseCase создаёт логирующий декоратор use-case выбора доставки
+func NewSetDeliveryUseCase(next application.SetDeliveryExecutor, logger *slog.Logger) *SetDeliveryUseCase {
+	return &SetDeliveryUseCase{next: next, logger: logger}
+}
+
+// Execute задаёт доставку заказа. Адрес в лог не попадает.
+func (uc *SetDeliveryUseCase) Execute(ctx context.Context, cmd application.SetDeliveryCommand) (application.OrderView, error) {
+	start := time.Now()
+	view, err := uc.next.Execute(ctx, cmd)
+	logCall(ctx, uc.logger, slog.LevelInfo, "set_delivery", start, err,
+		slog.String(KeyOrderID, cmd.OrderID),
+		slog.String("delivery_method", cmd.Method))
+	return view, err
+}
+
+// ScheduleInstallmentsUseCase - декоратор use-case оформления рассрочки
+type ScheduleInstallmentsUseCase struct {
+	next   application.ScheduleInstallmentsExecutor
+	logger *slog.Logger
+}
+
+// NewScheduleInstallmentsUseCase создаёт логирующий декоратор use-case
+// оформления рассрочки
+func NewScheduleInstallmentsUseCase(next application.ScheduleInstallmentsExecutor, logger *slog.Logger) *ScheduleInstallmentsUseCase {
+	return &ScheduleInstallmentsUseCase{next: next, logger: logger}
+}
+
+// Execute оформляет рассрочку
+func (uc *ScheduleInstallmentsUseCase) Execute(ctx context.Context, cmd application.ScheduleInstallmentsCommand) (application.OrderView, error) {
+	start := time.Now()
+	view, err := uc.next.Execute(ctx, cmd)
+	logCall(ctx, uc.logger, slog.LevelInfo, "schedule_installments", start, err,
+		append(orderAttrs(cmd.OrderID, view.Total, view.Currency), slog.Int("installments", cmd.Count))...)
+	return view, err
+}
+
+// PayInstallmentUseCase - декоратор use-case оплаты платежа рассрочки
+type PayInstallmentUseCase struct {
+	next   application.PayInstallmentExecutor
+	logger *slog.Logger
+}
+
+// NewPayInstallmentUseCase создаёт логирующий декоратор use-case оплаты
+// платежа рассрочки
+func NewPayInstallmentUseCase(next application.PayInstallmentExecutor, logger *slog.Logger) *PayInstallmentUseCase {
+	return &PayInstallmentUseCase{next: next, logger: logger}
+}
+
+// Execute вносит очередной платёж рассрочки
+func (uc *PayInstallmentUseCase) Execute(ctx context.Context, cmd application.PayInstallmentCommand) (application.PayInstallmentResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx, cmd)
+	attrs := orderAttrs(cmd.OrderID, result.Amount, result.Currency)
+	if result.Number > 0 {
+		attrs = append(attrs, slog.Int("installment", result.Number))
+	}
+	logCall(ctx, uc.logger, slog.LevelInfo, "pay_installment", start, err, attrs...)
+	return result, err
+}
+
+// GetOrderUseCase - декоратор use-case просмотра заказа. Успешные вызовы
+// пишутся на уровне Debug.
+type GetOrderUseCase struct {
+	next   application.GetOrderExecutor
+	logger *slog.Logger
+}
+
+// NewGetOrderUseCase создаёт логирующий декоратор use-case просмотра заказа
+func NewGetOrderUseCase(next application.GetOrderExecutor, logger *slog.Logger) *GetOrderUseCase {
+	return &GetOrderUseCase{next: next, logger: logger}
+}
+
+// Execute возвращает заказ
+func (uc *GetOrderUseCase) Execute(ctx context.Context, orderID string) (application.OrderView, error) {
+	start := time.Now()
+	view, err := uc.next.Execute(ctx, orderID)
+	logCall(ctx, uc.logger, slog.LevelDebug, "get_order", start, err, slog.String(KeyOrderID, orderID))
+	return view, err
+}
+
+// ListOrdersUseCase - декоратор use-case поиска заказов. Успешные вызовы
+// пишутся на уровне Debug.
+type ListOrdersUseCase struct {
+	next   application.ListOrdersExecutor
+	logger *slog.Logger
+}
+
+// NewListOrdersUseCase создаёт логирующий декоратор use-case поиска заказов
+func NewListOrdersUseCase(next application.ListOrdersExecutor, logger *slog.Logger) *ListOrdersUseCase {
+	return &ListOrdersUseCase{next: next, logger: logger}
+}
+
+// Execute возвращает страницу заказов
+func (uc *ListOrdersUseCase) Execute(ctx context.Context, req application.ListOrdersRequest) (application.ListOrdersResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx, req)
+	logCall(ctx, uc.logger, slog.LevelDebug, "list_orders", start, err, slog.Int("results", len(result.Orders)))
+	return result, err
+}
+
+// GetReceiptUseCase - декоратор use-case построения чека. Успешные вызовы
+// пишутся на уровне Debug.
+type GetReceiptUseCase struct {
+	next   application.GetReceiptExecutor
+	logger *slog.Logger
+}
+
+// NewGetReceiptUseCase создаёт логирующий декоратор use-case построения чека
+func NewGetReceiptUseCase(next application.GetReceiptExecutor, logger *slog.Logger) *GetReceiptUseCase {
+	return &GetReceiptUseCase{next: next, logger: logger}
+}
+
+// Execute строит чек по заказу
+func (uc *GetReceiptUseCase) Execute(ctx context.Context, orderID string) (application.Receipt, error) {
+	start := time.Now()
+	receipt, err := uc.next.Execute(ctx, orderID)
+	logCall(ctx, uc.logger, slog.LevelDebug, "get_receipt", start, err,
+		orderAttrs(orderID, receipt.Total, receipt.Currency)...)
+	return receipt, err
+}
+
+// PayOrdersBatchUseCase - декоратор use-case пакетной оплаты. Оплата
+// каждого заказа логируется декоратором PayOrderUseCase, если он
+// подключён к пакету; здесь пишется итог пакета.
+type PayOrdersBatchUseCase struct {
+	next   application.PayOrdersBatchExecutor
+	logger *slog.Logger
+}
+
+// NewPayOrdersBatchUseCase создаёт логирующий декоратор use-case пакетной оплаты
+func NewPayOrdersBatchUseCase(next application.PayOrdersBatchExecutor, logger *slog.Logger) *PayOrdersBatchUseCase {
+	return &PayOrdersBatchUseCase{next: next, logger: logger}
+}
+
+// Execute оплачивает пакет заказов
+func (uc *PayOrdersBatchUseCase) Execute(ctx context.Context, orderIDs []string) (application.PayOrdersBatchResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx, orderIDs)
+	counts := make([]any, 0, len(result.Counts))
+	for outcome, n := range result.Counts {
+		counts = append(counts, slog.Int(string(outcome), n))
+	}
+	logCall(ctx, uc.logger, slog.LevelInfo, "pay_orders_batch", start, err,
+		slog.Int("orders", len(result.Payments)),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Group("outcomes", counts...))
+	return result, err
+}
+
+// ExpireOrdersUseCase - декоратор use-case отмены неоплаченных в срок заказов
+type ExpireOrdersUseCase struct {
+	next   application.ExpireOrdersExecutor
+	logger *slog.Logger
+}
+
+// NewExpireOrdersUseCase создаёт логирующий декоратор use-case отмены заказов
+func NewExpireOrdersUseCase(next application.ExpireOrdersExecutor, logger *slog.Logger) *ExpireOrdersUseCase {
+	return &ExpireOrdersUseCase{next: next, logger: logger}
+}
+
+// Execute отменяет просроченные заказы
+func (uc *ExpireOrdersUseCase) Execute(ctx context.Context) (application.ExpireOrdersResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx)
+	logCall(ctx, uc.logger, slog.LevelInfo, "expire_orders", start, err, slog.Int("expired", len(result.Expired)))
+	return result, err
+}
+
+// ReconcilePaymentsUseCase - декоратор use-case сверки платежей
+type ReconcilePaymentsUseCase struct {
+	next   application.ReconcilePaymentsExecutor
+	logger *slog.Logger
+}
+
+// NewReconcilePaymentsUseCase создаёт логирующий декоратор use-case сверки
+func NewReconcilePaymentsUseCase(next application.ReconcilePaymentsExecutor, logger *slog.Logger) *ReconcilePaymentsUseCase {
+	return &ReconcilePaymentsUseCase{next: next, logger: logger}
+}
+
+// Execute сверяет заказы с выпиской шлюза
+func (uc *ReconcilePaymentsUseCase) Execute(ctx context.Context) (application.ReconcilePaymentsResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx)
+	logCall(ctx, uc.logger, slog.LevelInfo, "reconcile_payments", start, err,
+		slog.Int("orders", result.Orders),
+		slog.Int("unsettled", len(result.Unsettled)),
+		slog.Int("discrepancies", len(result.Discrepancies)))
+	return result, err
+}
+
+// BillSubscriptionsUseCase - декоратор use-case списаний по подпискам
+type BillSubscriptionsUseCase struct {
+	next   application.BillSubscriptionsExecutor
+	logger *slog.Logger
+}
+
+// NewBillSubscriptionsUseCase создаёт логирующий декоратор use-case
+// списаний по подпискам
+func NewBillSubscriptionsUseCase(next application.BillSubscriptionsExecutor, logger *slog.Logger) *BillSubscriptionsUseCase {
+	return &BillSubscriptionsUseCase{next: next, logger: logger}
+}
+
+// Execute выполняет проход списаний
+func (uc *BillSubscriptionsUseCase) Execute(ctx context.Context) (application.BillSubscriptionsResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx)
+	counts := make(map[application.BillingOutcome]int)
+	for _, billing := range result.Billings {
+		counts[billing.Outcome]++
+	}
+	outcomes := make([]any, 0, len(counts))
+	for outcome, n := range counts {
+		outcomes = append(outcomes, slog.Int(string(outcome), n))
+	}
+	logCall(ctx, uc.logger, slog.LevelInfo, "bill_subscriptions", start, err,
+		slog.Int("subscriptions", len(result.Billings)),
+		slog.Group("outcomes", outcomes...))
+	return result, err
+}
+
+// ReportOverdueInstallmentsUseCase - декоратор use-case отчёта о
+// просроченных платежах рассрочки. Успешные вызовы пишутся на уровне Debug.
+type ReportOverdueInstallmentsUseCase struct {
+	next   application.ReportOverdueInstallmentsExecutor
+	logger *slog.Logger
+}
+
+// NewReportOverdueInstallmentsUseCase создаёт логирующий декоратор отчёта
+func NewReportOverdueInstallmentsUseCase(next application.ReportOverdueInstallmentsExecutor, logger *slog.Logger) *ReportOverdueInstallmentsUseCase {
+	return &ReportOverdueInstallmentsUseCase{next: next, logger: logger}
+}
+
+// Execute возвращает просроченные платежи
+func (uc *ReportOverdueInstallmentsUseCase) Execute(ctx context.Context) ([]application.OverdueInstallment, error) {
+	start := time.Now()
+	overdue, err := uc.next.Execute(ctx)
+	logCall(ctx, uc.logger, slog.LevelDebug, "report_overdue_installments", start, err, slog.Int("overdue", len(overdue)))
+	return overdue, err
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"lab7/application"
+	"time"
+)
+
+// Исходы операций в метках outcome
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Options - настройки метрик оплаты
+type Options struct {
+	// Buckets - границы гистограмм длительности в секундах;
+	// если не заданы, используются DefaultBuckets
+	Buckets []float64
+}
+
+// Collector - метрики use-case, платёжного шлюза и репозитория.
+// Декораторы этого пакета записывают в него результаты вызовов.
+type Collector struct {
+	useCaseExecutions *CounterVec
+	useCaseDuration   *HistogramVec
+	gatewayCalls      *CounterVec
+	gatewayDuration   *HistogramVec
+	repositoryOps     *CounterVec
+	repositoryLatency *HistogramVec
+}
+
+// NewCollector создаёт метрики и регистрирует их в реестре
+func NewCollector(registry *Registry, opts Options) (*Collector, error) {
+	c := &Collector{}
+	var err error
+
+	if c.useCaseExecutions, err = registry.NewCounterVec("orders_use_case_executions_total",
+		"Use-case executions by outcome and error code.", "use_case", "outcome", "error_code"); err != nil {
+		return nil, err
+	}
+	if c.useCaseDuration, err = registry.NewHistogramVec("orders_use_case_duration_seconds",
+		"Use-case execution latency in seconds.", opts.Buckets, "use_case"); err != nil {
+		return nil, err
+	}
+	if c.gatewayCalls, err = registry.NewCounterVec("orders_gateway_calls_total",
+		"Payment gateway calls by operation, outcome and error code.", "operation", "outcome", "error_code"); err != nil {
+		return nil, err
+	}
+	if c.gatewayDuration, err = registry.NewHistogramVec("orders_gateway_call_duration_seconds",
+		"Payment gateway call latency in seconds.", opts.Buckets, "operation"); err != nil {
+		return nil, err
+	}
+	if c.repositoryOps, err = registry.NewCounterVec("orders_repository_operations_total",
+		"Order repository operations by operation and outcome.", "operation", "outcome"); err != nil {
+		return nil, err
+	}
+	if c.repositoryLatency, err = registry.NewHistogramVec("orders_repository_operation_duration_seconds",
+		"Order repository operation latency in seconds.", opts.Buckets, "operation"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Collector) observeUseCase(useCase string, start time.Time, err error) {
+	c.useCaseExecutions.Inc(useCase, outcome(err), application.ErrorCode(err))
+	c.useCaseDuration.Observe(time.Since(start).Seconds(), useCase)
+}
+
+func (c *Collector) observeGateway(operation string, start time.Time, err error) {
+	c.gatewayCalls.Inc(operation, outcome(err), application.ErrorCode(err))
+	c.gatewayDuration.Observe(time.Since(start).Seconds(), operation)
+}
+
+func (c *Collector) observeRepository(operation string, start time.Time, err error) {
+	c.repositoryOps.Inc(operation, outcome(err))
+	c.repositoryLatency.Observe(time.Since(start).Seconds(), operation)
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CounterVec - семейство монотонно растущих счётчиков с метками
+type CounterVec struct {
+	vec
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	series
+	value float64
+}
+
+// NewCounterVec создаёт и регистрирует семейство счётчиков
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) (*CounterVec, error) {
+	if err := validateLabelNames(labelNames); err != nil {
+		return nil, err
+	}
+	c := &CounterVec{
+		vec:    vec{metricName: name, help: help, labelNames: labelNames},
+		values: make(map[string]*counterSeries),
+	}
+	if err := r.register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Inc увеличивает счётчик ряда на единицу
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add увеличивает счётчик ряда на delta; отрицательные значения игнорируются
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	key, err := c.key(labelValues)
+	if err != nil {
+		panic(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &counterSeries{series: series{labelValues: append([]string(nil), labelValues...)}}
+		c.values[key] = s
+	}
+	s.value += delta
+}
+
+// Value возвращает текущее значение ряда
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key, err := c.key(labelValues)
+	if err != nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.values[key]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.writeHeader(w, "counter"); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(c.values) {
+		s := c.values[key]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.metricName, c.formatLabels(s.labelValues), formatFloat(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
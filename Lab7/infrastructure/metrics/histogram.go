@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// HistogramVec - семейство гистограмм с метками
+type HistogramVec struct {
+	vec
+	bounds []float64 // верхние границы корзин по возрастанию, последняя - +Inf
+	mu     sync.Mutex
+	values map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	series
+	counts []uint64 // наблюдения по корзинам, не накопительно
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec создаёт и регистрирует семейство гистограмм.
+// Если buckets пуст, используются DefaultBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) (*HistogramVec, error) {
+	if err := validateLabelNames(labelNames); err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	if math.IsInf(buckets[len(buckets)-1], +1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		return nil, errors.New("histogram buckets must be in increasing order")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] == buckets[i-1] {
+			return nil, fmt.Errorf("duplicate histogram bucket %v", buckets[i])
+		}
+	}
+
+	h := &HistogramVec{
+		vec:    vec{metricName: name, help: help, labelNames: labelNames},
+		bounds: append(buckets, math.Inf(+1)),
+		values: make(map[string]*histogramSeries),
+	}
+	if err := r.register(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Observe добавляет наблюдение value в ряд
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key, err := h.key(labelValues)
+	if err != nil {
+		panic(err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogramSeries{
+			series: series{labelValues: append([]string(nil), labelValues...)},
+			counts: make([]uint64, len(h.bounds)),
+		}
+		h.values[key] = s
+	}
+	s.counts[sort.SearchFloat64s(h.bounds[:len(h.bounds)-1], value)]++
+	s.sum += value
+	s.count++
+}
+
+// Count возвращает число наблюдений ряда
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key, err := h.key(labelValues)
+	if err != nil {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.values[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.writeHeader(w, "histogram"); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(h.values) {
+		s := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.bounds {
+			cumulative += s.counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n",
+				h.metricName, h.formatLabels(s.labelValues, "le", formatFloat(bound)), cumulative); err != nil {
+				return err
+			}
+		}
+		labels := h.formatLabels(s.labelValues)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n",
+			h.metricName, labels, formatFloat(s.sum), h.metricName, labels, s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
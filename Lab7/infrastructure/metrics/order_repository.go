@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"time"
+)
+
+// OrderRepository - декоратор OrderRepository, собирающий метрики обращений
+type OrderRepository struct {
+	next      application.OrderRepository
+	collector *Collector
+}
+
+// NewOrderRepository создаёт декоратор репозитория
+func NewOrderRepository(next application.OrderRepository, collector *Collector) *OrderRepository {
+	return &OrderRepository{next: next, collector: collector}
+}
+
+// GetByID загружает заказ по идентификатору
+func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	start := time.Now()
+	order, err := r.next.GetByID(ctx, orderID)
+	r.collector.observeRepository("get", start, err)
+	return order, err
+}
+
+// Save сохраняет заказ
+func (r *OrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	start := time.Now()
+	err := r.next.Save(ctx, order)
+	r.collector.observeRepository("save", start, err)
+	return err
+}
+
+// Update атомарно изменяет заказ
+func (r *OrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	start := time.Now()
+	err := r.next.Update(ctx, orderID, fn)
+	r.collector.observeRepository("update", start, err)
+	return err
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу
+func (r *OrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	start := time.Now()
+	page, err := r.next.Find(ctx, query)
+	r.collector.observeRepository("find", start, err)
+	return page, err
+}
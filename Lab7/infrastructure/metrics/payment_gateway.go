@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"time"
+)
+
+// PaymentGateway - декоратор PaymentGateway, собирающий метрики вызовов
+type PaymentGateway struct {
+	next      application.PaymentGateway
+	collector *Collector
+}
+
+// NewPaymentGateway создаёт декоратор платёжного шлюза
+func NewPaymentGateway(next application.PaymentGateway, collector *Collector) *PaymentGateway {
+	return &PaymentGateway{next: next, collector: collector}
+}
+
+// Charge выполняет списание средств
+func (g *PaymentGateway) Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	start := time.Now()
+	err := g.next.Charge(ctx, orderID, method, money)
+	g.collector.observeGateway("charge", start, err)
+	return err
+}
+
+// Refund возвращает ранее списанные средства
+func (g *PaymentGateway) Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	start := time.Now()
+	err := g.next.Refund(ctx, orderID, method, money)
+	g.collector.observeGateway("refund", start, err)
+	return err
+}
@@ -0,0 +1,194 @@
+// Package metrics реализует счётчики и гистограммы с выдачей в текстовом
+// формате Prometheus (exposition format 0.0.4) только на стандартной
+// библиотеке, а также декораторы портов и use-case, которые их собирают.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType - тип содержимого текстового формата Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets - границы гистограмм длительности по умолчанию, в секундах
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// family - семейство метрик с общим именем и набором меток
+type family interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry - набор метрик, выдаваемых одним HTTP-обработчиком
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry создаёт пустой реестр
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register добавляет семейство метрик; имена должны быть уникальны
+func (r *Registry) register(f family) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !metricNamePattern.MatchString(f.name()) {
+		return fmt.Errorf("invalid metric name %q", f.name())
+	}
+	if _, exists := r.families[f.name()]; exists {
+		return fmt.Errorf("metric %q is already registered", f.name())
+	}
+	r.families[f.name()] = f
+	return nil
+}
+
+// WriteTo записывает все метрики в текстовом формате Prometheus
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+
+	cw := &countingWriter{w: w}
+	for _, f := range families {
+		if err := f.write(cw); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики реестра
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if req.Method == http.MethodHead {
+			return
+		}
+		r.WriteTo(w)
+	})
+}
+
+// series - временной ряд с конкретными значениями меток
+type series struct {
+	labelValues []string
+}
+
+// vec - общая часть семейств с метками
+type vec struct {
+	metricName string
+	help       string
+	labelNames []string
+}
+
+func (v *vec) name() string { return v.metricName }
+
+// key проверяет число значений меток и строит ключ ряда
+func (v *vec) key(labelValues []string) (string, error) {
+	if len(labelValues) != len(v.labelNames) {
+		return "", fmt.Errorf("metric %s: expected %d label values, got %d",
+			v.metricName, len(v.labelNames), len(labelValues))
+	}
+	return strings.Join(labelValues, "\xff"), nil
+}
+
+// writeHeader записывает строки HELP и TYPE
+func (v *vec) writeHeader(w io.Writer, metricType string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n",
+		v.metricName, escapeHelp(v.help), v.metricName, metricType)
+	return err
+}
+
+// formatLabels форматирует метки ряда; extra добавляется в конец
+func (v *vec) formatLabels(labelValues []string, extra ...string) string {
+	if len(labelValues) == 0 && len(extra) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labelValues)+len(extra)/2)
+	for i, name := range v.labelNames {
+		parts = append(parts, name+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// validateLabelNames проверяет имена меток
+func validateLabelNames(names []string) error {
+	for _, name := range names {
+		if !metricNamePattern.MatchString(name) || strings.Contains(name, ":") || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if name == "le" {
+			return errors.New(`label name "le" is reserved`)
+		}
+	}
+	return nil
+}
+
+// sortedKeys возвращает ключи рядов в детерминированном порядке
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelValueEscaper.Replace(s) }
+
+// countingWriter считает записанные байты для WriteTo
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
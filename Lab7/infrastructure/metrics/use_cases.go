@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"lab7/application"
+	"time"
+)
+
+// PayOrderUseCase - декоратор use-case оплаты заказа
+type PayOrderUseCase struct {
+	next      application.PayOrderExecutor
+	collector *Collector
+}
+
+// NewPayOrderUseCase создаёт декоратор use-case оплаты
+func NewPayOrderUseCase(next application.PayOrderExecutor, collector *Collector) *PayOrderUseCase {
+	return &PayOrderUseCase{next: next, collector: collector}
+}
+
+// Execute выполняет оплату заказа
+func (uc *PayOrderUseCase) Execute(ctx context.Context, orderID string) (application.PayOrderResult, error) {
+	return uc.ExecuteCommand(ctx, application.PayOrderCommand{OrderID: orderID})
+}
+
+// ExecuteCommand выполняет оплату заказа частями
+func (uc *PayOrderUseCase) ExecuteCommand(ctx context.Context, cmd application.PayOrderCommand) (application.PayOrderResult, error) {
+	start := time.Now()
+	result, err := uc.next.ExecuteCommand(ctx, cmd)
+	uc.collector.observeUseCase("pay_order", start, err)
+	return result, err
+}
+
+// RefundOrderUseCase - декоратор use-case возврата средств
+type RefundOrderUseCase struct {
+	next      application.RefundOrderExecutor
+	collector *Collector
+}
+
+// NewRefundOrderUseCase создаёт декоратор use-case возврата
+func NewRefundOrderUseCase(next application.RefundOrderExecutor, collector *Collector) *RefundOrderUseCase {
+	return &RefundOrderUseCase{next: next, collector: collector}
+}
+
+// Execute выполняет возврат средств за заказ
+func (uc *RefundOrderUseCase) Execute(ctx context.Context, orderID string) (application.RefundOrderResult, error) {
+	start := time.Now()
+	result, err := uc.next.Execute(ctx, orderID)
+	uc.collector.observeUseCase("refund_order", start, err)
+	return result, err
+}
@@ -0,0 +1,232 @@
+package infrastructure
+
+import (
+	"lab7/application"
+	"lab7/domain"
+	"math"
+	"sort"
+)
+
+// idSet - множество идентификаторов заказов
+type idSet map[string]struct{}
+
+// hashIndex - вторичный индекс "значение -> множество заказов"
+type hashIndex map[string]idSet
+
+func (idx hashIndex) add(value, orderID string) {
+	set, ok := idx[value]
+	if !ok {
+		set = make(idSet)
+		idx[value] = set
+	}
+	set[orderID] = struct{}{}
+}
+
+func (idx hashIndex) remove(value, orderID string) {
+	set := idx[value]
+	delete(set, orderID)
+	if len(set) == 0 {
+		delete(idx, value)
+	}
+}
+
+// indexEntry - элемент упорядоченного индекса
+type indexEntry struct {
+	key int64
+	id  string
+}
+
+func (e indexEntry) less(other indexEntry) bool {
+	if e.key != other.key {
+		return e.key < other.key
+	}
+	return e.id < other.id
+}
+
+// sortedIndex - упорядоченный по (key, id) индекс для диапазонных
+// запросов и сортировки без полного перебора
+type sortedIndex []indexEntry
+
+// search возвращает позицию первого элемента, не меньшего e
+func (idx sortedIndex) search(e indexEntry) int {
+	return sort.Search(len(idx), func(i int) bool { return !idx[i].less(e) })
+}
+
+func (idx *sortedIndex) insert(e indexEntry) {
+	i := idx.search(e)
+	*idx = append(*idx, indexEntry{})
+	copy((*idx)[i+1:], (*idx)[i:])
+	(*idx)[i] = e
+}
+
+func (idx *sortedIndex) remove(e indexEntry) {
+	i := idx.search(e)
+	if i < len(*idx) && (*idx)[i] == e {
+		*idx = append((*idx)[:i], (*idx)[i+1:]...)
+	}
+}
+
+// keyRange возвращает элементы с ключом в диапазоне [from, to]
+func (idx sortedIndex) keyRange(from, to int64) sortedIndex {
+	lo := idx.search(indexEntry{key: from})
+	hi := sort.Search(len(idx), func(i int) bool { return idx[i].key > to })
+	if lo > hi {
+		return nil
+	}
+	return idx[lo:hi]
+}
+
+// orderIndexes - вторичные индексы InMemoryOrderRepository
+type orderIndexes struct {
+	byStatus   hashIndex
+	byCurrency hashIndex
+	byProduct  hashIndex
+	byCustomer hashIndex
+	byID       sortedIndex // все заказы по идентификатору
+	byCreated  sortedIndex // заказы по времени создания
+	byTotal    sortedIndex // заказы по итоговой сумме
+}
+
+func newOrderIndexes() *orderIndexes {
+	return &orderIndexes{
+		byStatus:   make(hashIndex),
+		byCurrency: make(hashIndex),
+		byProduct:  make(hashIndex),
+		byCustomer: make(hashIndex),
+	}
+}
+
+// add добавляет заказ во все индексы
+func (ix *orderIndexes) add(order *domain.Order) {
+	id := order.ID()
+	ix.byStatus.add(order.Status().String(), id)
+	for _, productID := range productIDs(order) {
+		ix.byProduct.add(productID, id)
+	}
+	if customerID := order.CustomerID(); customerID != "" {
+		ix.byCustomer.add(customerID, id)
+	}
+	ix.byID.insert(indexEntry{id: id})
+	ix.byCreated.insert(indexEntry{key: order.CreatedAt().UnixNano(), id: id})
+	ix.byTotal.insert(indexEntry{key: application.OrderSortKey(order, application.SortByTotal), id: id})
+	if _, currency, ok := application.OrderTotalKey(order); ok {
+		ix.byCurrency.add(currency, id)
+	}
+}
+
+// remove удаляет заказ из всех индексов
+func (ix *orderIndexes) remove(order *domain.Order) {
+	id := order.ID()
+	ix.byStatus.remove(order.Status().String(), id)
+	for _, productID := range productIDs(order) {
+		ix.byProduct.remove(productID, id)
+	}
+	if customerID := order.CustomerID(); customerID != "" {
+		ix.byCustomer.remove(customerID, id)
+	}
+	ix.byID.remove(indexEntry{id: id})
+	ix.byCreated.remove(indexEntry{key: order.CreatedAt().UnixNano(), id: id})
+	ix.byTotal.remove(indexEntry{key: application.OrderSortKey(order, application.SortByTotal), id: id})
+	if _, currency, ok := application.OrderTotalKey(order); ok {
+		ix.byCurrency.remove(currency, id)
+	}
+}
+
+// sortIndex возвращает индекс, упорядоченный по полю сортировки
+func (ix *orderIndexes) sortIndex(field application.OrderSortField) sortedIndex {
+	switch field {
+	case application.SortByCreatedAt:
+		return ix.byCreated
+	case application.SortByTotal:
+		return ix.byTotal
+	default:
+		return ix.byID
+	}
+}
+
+// candidates сужает выборку по индексам. Если запрос не содержит
+// индексируемых фильтров, restricted равен false.
+func (ix *orderIndexes) candidates(q application.OrderQuery) (set idSet, restricted bool) {
+	var sets []idSet
+
+	if len(q.Statuses) > 0 {
+		union := make(idSet)
+		for _, status := range q.Statuses {
+			for id := range ix.byStatus[status.String()] {
+				union[id] = struct{}{}
+			}
+		}
+		sets = append(sets, union)
+	}
+	if q.Currency != "" {
+		sets = append(sets, ix.byCurrency[q.Currency])
+	}
+	if q.ProductID != "" {
+		sets = append(sets, ix.byProduct[q.ProductID])
+	}
+	if q.CustomerID != "" {
+		sets = append(sets, ix.byCustomer[q.CustomerID])
+	}
+	if !q.CreatedFrom.IsZero() || !q.CreatedTo.IsZero() {
+		from, to := int64(math.MinInt64), int64(math.MaxInt64)
+		if !q.CreatedFrom.IsZero() {
+			from = q.CreatedFrom.UnixNano()
+		}
+		if !q.CreatedTo.IsZero() {
+			to = q.CreatedTo.UnixNano() - 1
+		}
+		sets = append(sets, entriesToSet(ix.byCreated.keyRange(from, to)))
+	}
+	if q.MinTotal != nil || q.MaxTotal != nil {
+		from, to := int64(math.MinInt64), int64(math.MaxInt64)
+		if q.MinTotal != nil {
+			from = *q.MinTotal
+		}
+		if q.MaxTotal != nil {
+			to = *q.MaxTotal
+		}
+		sets = append(sets, entriesToSet(ix.byTotal.keyRange(from, to)))
+	}
+
+	if len(sets) == 0 {
+		return nil, false
+	}
+
+	// Пересекаем множества, начиная с наименьшего
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	result := make(idSet, len(sets[0]))
+	for id := range sets[0] {
+		inAll := true
+		for _, other := range sets[1:] {
+			if _, ok := other[id]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			result[id] = struct{}{}
+		}
+	}
+	return result, true
+}
+
+func entriesToSet(entries sortedIndex) idSet {
+	set := make(idSet, len(entries))
+	for _, e := range entries {
+		set[e.id] = struct{}{}
+	}
+	return set
+}
+
+// productIDs возвращает уникальные идентификаторы продуктов заказа
+func productIDs(order *domain.Order) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, line := range order.Lines() {
+		if !seen[line.ProductID()] {
+			seen[line.ProductID()] = true
+			ids = append(ids, line.ProductID())
+		}
+	}
+	return ids
+}
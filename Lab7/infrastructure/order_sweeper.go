@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"time"
+)
+
+// DefaultSweepInterval - период проверки просроченных заказов по умолчанию
+const DefaultSweepInterval = time.Minute
+
+// OrderSweeper - фоновая горутина, которая периодически переводит
+// просроченные заказы в EXPIRED через ExpireOrdersUseCase
+type OrderSweeper struct {
+	periodic
+	expire  *application.ExpireOrdersUseCase
+	onSweep func(result application.ExpireOrdersResult, err error)
+}
+
+// OrderSweeperOption - необязательная настройка OrderSweeper
+type OrderSweeperOption func(*OrderSweeper)
+
+// SweepTicks задаёт источник сигналов к проверке вместо таймера;
+// в тестах позволяет запускать проходы вручную
+func SweepTicks(ticks <-chan time.Time) OrderSweeperOption {
+	return func(s *OrderSweeper) {
+		s.ticks = ticks
+	}
+}
+
+// OnSweep задаёт функцию, вызываемую после каждого прохода, например
+// для логирования ошибок
+func OnSweep(fn func(result application.ExpireOrdersResult, err error)) OrderSweeperOption {
+	return func(s *OrderSweeper) {
+		s.onSweep = fn
+	}
+}
+
+// NewOrderSweeper создаёт OrderSweeper с периодом interval; при
+// interval <= 0 используется DefaultSweepInterval
+func NewOrderSweeper(expire *application.ExpireOrdersUseCase, interval time.Duration, opts ...OrderSweeperOption) *OrderSweeper {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+	s := &OrderSweeper{expire: expire}
+	s.interval = interval
+	s.run = s.sweep
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start запускает фоновую проверку. Горутина работает до Stop или до
+// отмены ctx.
+func (s *OrderSweeper) Start(ctx context.Context) error {
+	return s.start(ctx)
+}
+
+// Stop останавливает фоновую проверку и ждёт завершения текущего прохода.
+// После Stop проверку можно запустить снова.
+func (s *OrderSweeper) Stop() {
+	s.stop()
+}
+
+// sweep выполняет один проход
+func (s *OrderSweeper) sweep(ctx context.Context) {
+	result, err := s.expire.Execute(ctx)
+	if s.onSweep != nil {
+		s.onSweep(result, err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package ordercsv выгружает заказы в CSV и загружает их обратно.
+//
+// Каждая строка файла - одна строка заказа вместе с полями самого заказа
+// (идентификатор, клиент, статус, валюта, время создания). Заказ без строк
+// выгружается одной строкой с пустыми полями строки заказа. Суммы указаны
+// в минимальных единицах валюты. Доставка, части оплаты и график
+// рассрочки в файл не попадают.
+package ordercsv
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"lab7/application"
+	"lab7/domain"
+	"strconv"
+	"time"
+)
+
+// Столбцы файла
+const (
+	ColumnOrderID     = "order_id"
+	ColumnCustomerID  = "customer_id"
+	ColumnStatus      = "status"
+	ColumnCurrency    = "currency"
+	ColumnCreatedAt   = "created_at"
+	ColumnProductID   = "product_id"
+	ColumnName        = "name"
+	ColumnQuantity    = "quantity"
+	ColumnUnitPrice   = "unit_price"
+	ColumnLineTotal   = "line_total"
+	ColumnVATRate     = "vat_rate"
+	ColumnWeightGrams = "weight_grams"
+)
+
+// Header - заголовок файла в порядке выгрузки
+var Header = []string{
+	ColumnOrderID, ColumnCustomerID, ColumnStatus, ColumnCurrency, ColumnCreatedAt,
+	ColumnProductID, ColumnName, ColumnQuantity, ColumnUnitPrice, ColumnLineTotal,
+	ColumnVATRate, ColumnWeightGrams,
+}
+
+// Export выгружает в w заказы из repo, удовлетворяющие query, по всем
+// страницам результата, и возвращает число выгруженных заказов
+func Export(ctx context.Context, w io.Writer, repo application.OrderRepository, query application.OrderQuery) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Header); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for {
+		page, err := repo.Find(ctx, query)
+		if err != nil {
+			return exported, err
+		}
+		for _, order := range page.Orders {
+			for _, record := range records(order) {
+				if err := writer.Write(record); err != nil {
+					return exported, err
+				}
+			}
+			exported++
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	writer.Flush()
+	return exported, writer.Error()
+}
+
+// records возвращает строки файла для заказа. Валюта берётся из цены
+// строки: в заказе с разными валютами у строк она разная. Вес
+// выгружается за единицу товара, как и цена.
+func records(order *domain.Order) [][]string {
+	record := func(currency string, line ...string) []string {
+		return append([]string{
+			order.ID(), order.CustomerID(), order.Status().String(), currency,
+			order.CreatedAt().UTC().Format(time.RFC3339Nano),
+		}, line...)
+	}
+
+	lines := order.Lines()
+	if len(lines) == 0 {
+		return [][]string{record("", "", "", "", "", "", "", "")}
+	}
+	result := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, record(line.Price().Currency(),
+			line.ProductID(),
+			line.Name(),
+			strconv.Itoa(line.Quantity()),
+			strconv.FormatInt(line.Price().Amount(), 10),
+			strconv.FormatInt(line.Total().Amount(), 10),
+			line.VATRate().String(),
+			strconv.Itoa(line.Snapshot().WeightGrams),
+		))
+	}
+	return result
+}
@@ -0,0 +1,328 @@
+package ordercsv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"lab7/application"
+	"lab7/domain"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrMissingColumn - в заголовке файла нет обязательного столбца
+	ErrMissingColumn = errors.New("missing csv column")
+	// ErrInvalidRow - строка файла не разбирается или противоречит
+	// другим строкам того же заказа
+	ErrInvalidRow = errors.New("invalid csv row")
+)
+
+// RowError - ошибка в строке файла. Строки нумеруются с 1, заголовок -
+// строка 1.
+type RowError struct {
+	Row     int
+	OrderID string
+	Err     error
+}
+
+// Error возвращает текст ошибки с номером строки
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d (order %s): %v", e.Row, e.OrderID, e.Err)
+}
+
+// Unwrap возвращает причину ошибки
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportReport - результат загрузки файла
+type ImportReport struct {
+	Rows   int        // прочитано строк без учёта заголовка
+	Orders int        // заказов сохранено или, при пробном прогоне, прошло проверку
+	Errors []RowError // ошибки строк; заказ с ошибкой в любой строке пропускается
+}
+
+// importOptions - настройки загрузки
+type importOptions struct {
+	dryRun bool
+}
+
+// ImportOption - необязательная настройка загрузки
+type ImportOption func(*importOptions)
+
+// DryRun включает пробный прогон: файл проверяется целиком, но заказы не
+// сохраняются
+func DryRun() ImportOption {
+	return func(o *importOptions) {
+		o.dryRun = true
+	}
+}
+
+// Import загружает заказы из r в repo. Строки проверяются через
+// domain.NewMoney и domain.NewOrderLine; загружаются только заказы в
+// статусах PENDING и EXPIRED. Ошибка в строке не прерывает
+// загрузку, а пропускает только её заказ и попадает в отчёт. Заказ,
+// который уже есть в repo, не перезаписывается. Ошибкой возвращаются
+// только нечитаемый файл, неполный заголовок и сбой хранилища.
+func Import(ctx context.Context, r io.Reader, repo application.OrderRepository, opts ...ImportOption) (ImportReport, error) {
+	var options importOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return ImportReport{}, fmt.Errorf("%w: empty file", ErrMissingColumn)
+	}
+	if err != nil {
+		return ImportReport{}, err
+	}
+	columns, err := columnIndex(header)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	var report ImportReport
+	var drafts []*draft
+	byID := make(map[string]*draft)
+	for rowNum := 2; ; rowNum++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Rows++
+
+		row := columns.row(record)
+		d, ok := byID[row.orderID]
+		if !ok {
+			d = &draft{id: row.orderID, row: rowNum}
+			byID[row.orderID] = d
+			drafts = append(drafts, d)
+		}
+		if err := d.add(row); err != nil {
+			d.failed = true
+			report.Errors = append(report.Errors, RowError{Row: rowNum, OrderID: row.orderID, Err: err})
+		}
+	}
+
+	for _, d := range drafts {
+		if d.failed {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		_, err := repo.GetByID(ctx, d.id)
+		if err == nil {
+			report.Errors = append(report.Errors, RowError{
+				Row: d.row, OrderID: d.id,
+				Err: fmt.Errorf("%w: %s", application.ErrOrderAlreadyExists, d.id),
+			})
+			continue
+		}
+		if !errors.Is(err, application.ErrOrderNotFound) {
+			return report, err
+		}
+		if !options.dryRun {
+			if err := repo.Save(ctx, d.order()); err != nil {
+				return report, err
+			}
+		}
+		report.Orders++
+	}
+	return report, nil
+}
+
+// columns - номера столбцов в файле
+type columns map[string]int
+
+// columnIndex сопоставляет столбцы заголовка с их номерами. Порядок
+// столбцов не важен, лишние столбцы пропускаются.
+func columnIndex(header []string) (columns, error) {
+	index := make(columns, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	var missing []error
+	for _, name := range Header {
+		if _, ok := index[name]; !ok {
+			missing = append(missing, fmt.Errorf("%w: %s", ErrMissingColumn, name))
+		}
+	}
+	return index, errors.Join(missing...)
+}
+
+// row - значения одной строки файла
+type row struct {
+	orderID, customerID, status, currency, createdAt string
+	productID, name, quantity, unitPrice, lineTotal  string
+	vatRate, weightGrams                             string
+}
+
+// row извлекает значения строки; недостающие в конце поля считаются пустыми
+func (c columns) row(record []string) row {
+	field := func(name string) string {
+		if i := c[name]; i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	return row{
+		orderID:     field(ColumnOrderID),
+		customerID:  field(ColumnCustomerID),
+		status:      field(ColumnStatus),
+		currency:    field(ColumnCurrency),
+		createdAt:   field(ColumnCreatedAt),
+		productID:   field(ColumnProductID),
+		name:        field(ColumnName),
+		quantity:    field(ColumnQuantity),
+		unitPrice:   field(ColumnUnitPrice),
+		lineTotal:   field(ColumnLineTotal),
+		vatRate:     field(ColumnVATRate),
+		weightGrams: field(ColumnWeightGrams),
+	}
+}
+
+// isEmptyOrder сообщает, что строка описывает заказ без строк
+func (r row) isEmptyOrder() bool {
+	return r.productID == "" && r.quantity == "" && r.unitPrice == ""
+}
+
+// draft - заказ, собираемый из строк файла
+type draft struct {
+	id         string
+	row        int // первая строка заказа в файле
+	customerID string
+	status     domain.OrderStatus
+	createdAt  time.Time
+	lines      []domain.OrderLine
+	started    bool // поля заказа взяты из первой строки
+	empty      bool // заказ выгружен без строк
+	failed     bool
+}
+
+// add проверяет строку файла и добавляет её к заказу
+func (d *draft) add(r row) error {
+	if r.orderID == "" {
+		return fmt.Errorf("%w: empty %s", ErrInvalidRow, ColumnOrderID)
+	}
+	status, err := domain.ParseOrderStatus(r.status)
+	if err != nil {
+		return err
+	}
+	// Оплаты, доставка и рассрочка в файл не выгружаются, поэтому
+	// оплаченный или возвращённый заказ из файла не восстановить
+	if status != domain.OrderStatusPending && status != domain.OrderStatusExpired {
+		return fmt.Errorf("%w: %s %s cannot be imported", ErrInvalidRow, ColumnStatus, status)
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, r.createdAt)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidRow, ColumnCreatedAt, err)
+	}
+
+	if !d.started {
+		d.customerID, d.status, d.createdAt, d.started = r.customerID, status, createdAt, true
+	} else {
+		switch {
+		case r.customerID != d.customerID:
+			return fmt.Errorf("%w: %s %q differs from %q", ErrInvalidRow, ColumnCustomerID, r.customerID, d.customerID)
+		case status != d.status:
+			return fmt.Errorf("%w: %s %s differs from %s", ErrInvalidRow, ColumnStatus, status, d.status)
+		case !createdAt.Equal(d.createdAt):
+			return fmt.Errorf("%w: %s %s differs from %s", ErrInvalidRow, ColumnCreatedAt, r.createdAt, d.createdAt.Format(time.RFC3339Nano))
+		}
+	}
+
+	if r.isEmptyOrder() {
+		if d.empty || len(d.lines) > 0 {
+			return fmt.Errorf("%w: order without lines must be a single row", ErrInvalidRow)
+		}
+		d.empty = true
+		return nil
+	}
+	if d.empty {
+		return fmt.Errorf("%w: order without lines must be a single row", ErrInvalidRow)
+	}
+	line, err := parseLine(r)
+	if err != nil {
+		return err
+	}
+	d.lines = append(d.lines, line)
+	return nil
+}
+
+// order собирает заказ из проверенных строк
+func (d *draft) order() *domain.Order {
+	return domain.RestoreOrder(domain.OrderSnapshot{
+		ID:         d.id,
+		CustomerID: d.customerID,
+		Lines:      d.lines,
+		Status:     d.status,
+		CreatedAt:  d.createdAt,
+	})
+}
+
+// parseLine проверяет поля строки заказа. Итог строки необязателен, но
+// если указан, должен совпадать с ценой, умноженной на количество.
+func parseLine(r row) (domain.OrderLine, error) {
+	amount, err := parseInt(ColumnUnitPrice, r.unitPrice)
+	if err != nil {
+		return domain.OrderLine{}, err
+	}
+	quantity, err := parseInt(ColumnQuantity, r.quantity)
+	if err != nil {
+		return domain.OrderLine{}, err
+	}
+	price, err := domain.NewMoney(amount, r.currency)
+	if err != nil {
+		return domain.OrderLine{}, err
+	}
+	line, err := domain.NewOrderLine(r.productID, price, int(quantity))
+	if err != nil {
+		return domain.OrderLine{}, err
+	}
+
+	snapshot := line.Snapshot()
+	snapshot.Name = r.name
+	if snapshot.VATRate, err = domain.ParseVATRate(r.vatRate); err != nil {
+		return domain.OrderLine{}, err
+	}
+	if r.weightGrams != "" {
+		weight, err := parseInt(ColumnWeightGrams, r.weightGrams)
+		if err != nil {
+			return domain.OrderLine{}, err
+		}
+		snapshot.WeightGrams = int(weight)
+	}
+	if line, err = domain.RestoreOrderLine(snapshot); err != nil {
+		return domain.OrderLine{}, err
+	}
+
+	if r.lineTotal != "" {
+		total, err := parseInt(ColumnLineTotal, r.lineTotal)
+		if err != nil {
+			return domain.OrderLine{}, err
+		}
+		if total != line.Total().Amount() {
+			return domain.OrderLine{}, fmt.Errorf("%w: %s %d, expected %d", ErrInvalidRow, ColumnLineTotal, total, line.Total().Amount())
+		}
+	}
+	return line, nil
+}
+
+// parseInt разбирает целое число из столбца column
+func parseInt(column, s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s %q is not an integer", ErrInvalidRow, column, s)
+	}
+	return n, nil
+}
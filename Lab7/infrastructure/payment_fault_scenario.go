@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Операции платёжного шлюза
+const (
+	GatewayOperationCharge = "charge"
+	GatewayOperationRefund = "refund"
+)
+
+// FaultKind - вид сбоя, который вносит сценарий
+type FaultKind int
+
+const (
+	// FaultNone - без ошибки; сценарий только добавляет задержку. Нулевое
+	// значение: сценарий без Fault сбоев не вносит.
+	FaultNone FaultKind = iota
+	// FaultPermanent - окончательный отказ (application.ErrPaymentDeclined)
+	FaultPermanent
+	// FaultTransient - временный сбой (application.ErrGatewayUnavailable)
+	FaultTransient
+)
+
+// FaultScenario - правило внесения сбоя в вызовы FakePaymentGateway.
+// Вызов попадает под сценарий, если выполнены все заданные условия;
+// незаданные (нулевые) условия не проверяются.
+type FaultScenario struct {
+	// Name - имя сценария для истории вызовов
+	Name string
+	// Operation - GatewayOperationCharge или GatewayOperationRefund; пусто - любая
+	Operation string
+	// OnCall - номер вызова операции (с 1), на котором срабатывает сценарий
+	OnCall int
+	// OrderIDs - заказы, для которых срабатывает сценарий
+	OrderIDs []string
+	// Methods - виды оплаты, для которых срабатывает сценарий
+	Methods []domain.PaymentMethodKind
+	// AmountAbove - сценарий срабатывает для сумм строго больше порога
+	AmountAbove *int64
+	// Rate - вероятность срабатывания из (0, 1]; 0 - всегда
+	Rate float64
+	// Times - сколько раз может сработать сценарий; 0 - без ограничений
+	Times int
+	// Latency - задержка перед ответом шлюза
+	Latency time.Duration
+	// Fault - вид сбоя; по умолчанию FaultNone
+	Fault FaultKind
+	// Reason - текст ошибки
+	Reason string
+}
+
+// GatewayCall - запись истории вызовов FakePaymentGateway
+type GatewayCall struct {
+	Operation string
+	OrderID   string
+	Method    domain.PaymentMethod
+	Amount    domain.Money
+	// Call - номер вызова операции (с 1)
+	Call int
+	// Attempt - номер попытки операции для этого заказа (с 1)
+	Attempt int
+	// At - время начала вызова
+	At time.Time
+	// Latency - внесённая задержка
+	Latency time.Duration
+	// Scenario - имя сработавшего сценария, внёсшего ошибку
+	Scenario string
+	Err      error
+}
+
+// faultScenarioState - сценарий и число его срабатываний
+type faultScenarioState struct {
+	scenario FaultScenario
+	fired    int
+}
+
+// matches проверяет условия сценария, кроме вероятности
+func (s *faultScenarioState) matches(operation, orderID string, method domain.PaymentMethod, money domain.Money, call int) bool {
+	sc := s.scenario
+	switch {
+	case sc.Times > 0 && s.fired >= sc.Times:
+		return false
+	case sc.Operation != "" && sc.Operation != operation:
+		return false
+	case sc.OnCall > 0 && sc.OnCall != call:
+		return false
+	case len(sc.OrderIDs) > 0 && !slices.Contains(sc.OrderIDs, orderID):
+		return false
+	case len(sc.Methods) > 0 && !slices.Contains(sc.Methods, method.Kind()):
+		return false
+	case sc.AmountAbove != nil && money.Amount() <= *sc.AmountAbove:
+		return false
+	}
+	return true
+}
+
+// faultDecision - итог применения сценариев к вызову
+type faultDecision struct {
+	latency  time.Duration
+	scenario string
+	err      error
+}
+
+// decide применяет сценарии по порядку: задержки всех сработавших
+// сценариев складываются, ошибку определяет первый сработавший сценарий
+// со сбоем. Случайное число выбирается только для сценариев с Rate > 0,
+// поэтому при одном seed последовательность сбоев воспроизводима.
+func decide(states []*faultScenarioState, rng *rand.Rand, operation, orderID string, method domain.PaymentMethod, money domain.Money, call int) faultDecision {
+	var decision faultDecision
+	for _, state := range states {
+		if !state.matches(operation, orderID, method, money, call) {
+			continue
+		}
+		if state.scenario.Rate > 0 && rng.Float64() >= state.scenario.Rate {
+			continue
+		}
+		if state.scenario.Fault != FaultNone && decision.err != nil {
+			continue
+		}
+
+		state.fired++
+		decision.latency += state.scenario.Latency
+		if state.scenario.Fault != FaultNone {
+			decision.scenario = state.scenario.Name
+			decision.err = state.scenario.err()
+		}
+	}
+	return decision
+}
+
+// err возвращает ошибку сценария
+func (sc FaultScenario) err() error {
+	sentinel := application.ErrPaymentDeclined
+	if sc.Fault == FaultTransient {
+		sentinel = application.ErrGatewayUnavailable
+	}
+	if sc.Reason == "" {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %s", sentinel, sc.Reason)
+}
@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errAlreadyStarted - повторный запуск работающей фоновой задачи
+var errAlreadyStarted = errors.New("background job is already started")
+
+// periodic - фоновая горутина, выполняющая run по сигналам таймера или
+// внешнего канала. Общая основа OrderSweeper и BillingScheduler.
+type periodic struct {
+	interval time.Duration
+	ticks    <-chan time.Time // nil - сигналы от таймера с периодом interval
+	run      func(ctx context.Context)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start запускает горутину; она работает до stop или до отмены ctx
+func (p *periodic) start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done != nil {
+		return errAlreadyStarted
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+
+	ticks := p.ticks
+	var ticker *time.Ticker
+	if ticks == nil {
+		ticker = time.NewTicker(p.interval)
+		ticks = ticker.C
+	}
+	go func(cancel context.CancelFunc, done chan struct{}) {
+		// Горутина могла завершиться из-за отмены ctx без stop; состояние
+		// сбрасывается здесь, чтобы задачу можно было запустить снова
+		defer func() {
+			cancel()
+			p.mu.Lock()
+			if p.done == done {
+				p.cancel, p.done = nil, nil
+			}
+			p.mu.Unlock()
+			close(done)
+		}()
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+				p.run(ctx)
+			}
+		}
+	}(p.cancel, p.done)
+	return nil
+}
+
+// stop останавливает горутину и ждёт завершения текущего выполнения run
+func (p *periodic) stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+}
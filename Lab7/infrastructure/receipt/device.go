@@ -0,0 +1,77 @@
+// Package receipt выводит чеки (application.Receipt) в разных форматах.
+//
+// Пакет построен по шаблону «Мост»: формат чека (Output - текст, JSON,
+// HTML) и место назначения (Device - io.Writer, файл, буфер) меняются
+// независимо, и любой формат можно вывести на любое устройство.
+package receipt
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// Device - устройство, на которое выводится готовый чек (Implementor)
+type Device interface {
+	Print(data []byte) error
+}
+
+// WriterDevice - устройство поверх произвольного io.Writer
+type WriterDevice struct {
+	w io.Writer
+}
+
+// NewWriterDevice создаёт устройство, пишущее в w
+func NewWriterDevice(w io.Writer) *WriterDevice {
+	return &WriterDevice{w: w}
+}
+
+// Print записывает чек в io.Writer
+func (d *WriterDevice) Print(data []byte) error {
+	_, err := d.w.Write(data)
+	return err
+}
+
+// FileDevice - устройство, сохраняющее каждый чек в файл.
+// Файл перезаписывается при каждом выводе.
+type FileDevice struct {
+	path string
+}
+
+// NewFileDevice создаёт устройство, пишущее в файл path
+func NewFileDevice(path string) *FileDevice {
+	return &FileDevice{path: path}
+}
+
+// Print сохраняет чек в файл
+func (d *FileDevice) Print(data []byte) error {
+	return os.WriteFile(d.path, data, 0o644)
+}
+
+// BufferDevice - устройство, накапливающее чеки в памяти.
+// Безопасно для конкурентного использования.
+type BufferDevice struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewBufferDevice создаёт пустой буфер
+func NewBufferDevice() *BufferDevice {
+	return &BufferDevice{}
+}
+
+// Print добавляет чек в буфер
+func (d *BufferDevice) Print(data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.buf.Write(data)
+	return err
+}
+
+// String возвращает всё выведенное в буфер
+func (d *BufferDevice) String() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.buf.String()
+}
@@ -0,0 +1,152 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"lab7/application"
+	"text/tabwriter"
+	"time"
+)
+
+// Output - формат чека (абстракция моста)
+type Output interface {
+	Render(receipt application.Receipt) error
+}
+
+// baseOutput - общая часть форматов: устройство вывода
+type baseOutput struct {
+	device Device
+}
+
+// print передаёт готовый чек устройству
+func (b baseOutput) print(data []byte) error {
+	if err := b.device.Print(data); err != nil {
+		return fmt.Errorf("print receipt: %w", err)
+	}
+	return nil
+}
+
+// TextOutput - чек в виде простого текста
+type TextOutput struct {
+	baseOutput
+}
+
+// NewTextOutput создаёт текстовый формат для устройства device
+func NewTextOutput(device Device) *TextOutput {
+	return &TextOutput{baseOutput: baseOutput{device: device}}
+}
+
+// Render выводит чек как выровненный текст
+func (o *TextOutput) Render(r application.Receipt) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Receipt:\t%s\n", r.OrderID)
+	if r.CustomerID != "" {
+		fmt.Fprintf(tw, "Customer:\t%s\n", r.CustomerID)
+	}
+	fmt.Fprintf(tw, "Status:\t%s\n", r.Status)
+	fmt.Fprintf(tw, "Issued:\t%s\n", r.IssuedAt.Format(time.RFC3339))
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "ITEM\tQTY\tPRICE\tTOTAL\tVAT")
+	for _, line := range r.Lines {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s %s\n", lineTitle(line), line.Quantity,
+			r.FormatAmount(line.UnitPrice), r.FormatAmount(line.Total), line.VATRate, r.FormatAmount(line.VAT))
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "Subtotal:\t%s\n", r.FormatAmount(r.Subtotal))
+	if r.Shipping != 0 {
+		fmt.Fprintf(tw, "Shipping:\t%s\n", r.FormatAmount(r.Shipping))
+	}
+	fmt.Fprintf(tw, "Total:\t%s\n", r.FormatAmount(r.Total))
+	for _, tax := range r.Taxes {
+		fmt.Fprintf(tw, "incl. %s:\t%s\n", tax.Rate, r.FormatAmount(tax.Amount))
+	}
+	for _, payment := range r.Payments {
+		fmt.Fprintf(tw, "Paid %s:\t%s\n", payment.Reference, r.FormatAmount(payment.Amount))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return o.print(buf.Bytes())
+}
+
+// JSONOutput - чек в формате JSON
+type JSONOutput struct {
+	baseOutput
+}
+
+// NewJSONOutput создаёт JSON-формат для устройства device
+func NewJSONOutput(device Device) *JSONOutput {
+	return &JSONOutput{baseOutput: baseOutput{device: device}}
+}
+
+// Render выводит чек как JSON-документ
+func (o *JSONOutput) Render(r application.Receipt) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return o.print(append(data, '\n'))
+}
+
+// HTMLOutput - чек в виде HTML-страницы
+type HTMLOutput struct {
+	baseOutput
+}
+
+// NewHTMLOutput создаёт HTML-формат для устройства device
+func NewHTMLOutput(device Device) *HTMLOutput {
+	return &HTMLOutput{baseOutput: baseOutput{device: device}}
+}
+
+// Render выводит чек как HTML-страницу. Текстовые поля экранируются
+// шаблонизатором.
+func (o *HTMLOutput) Render(r application.Receipt) error {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return err
+	}
+	return o.print(buf.Bytes())
+}
+
+// lineTitle возвращает название товара или его ID, если название неизвестно
+func lineTitle(line application.ReceiptLine) string {
+	if line.Name != "" {
+		return line.Name
+	}
+	return line.ProductID
+}
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"title": lineTitle,
+	"rfc3339": func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Receipt {{.OrderID}}</title></head>
+<body>
+<h1>Receipt {{.OrderID}}</h1>
+<p>Status: {{.Status}}<br>Issued: {{rfc3339 .IssuedAt}}{{if .CustomerID}}<br>Customer: {{.CustomerID}}{{end}}</p>
+<table>
+<tr><th>Item</th><th>Qty</th><th>Price</th><th>Total</th><th>VAT</th></tr>
+{{- range .Lines}}
+<tr><td>{{title .}}</td><td>{{.Quantity}}</td><td>{{$.FormatAmount .UnitPrice}}</td><td>{{$.FormatAmount .Total}}</td><td>{{.VATRate}} {{$.FormatAmount .VAT}}</td></tr>
+{{- end}}
+</table>
+<p>Subtotal: {{.FormatAmount .Subtotal}}{{if .Shipping}}<br>Shipping: {{.FormatAmount .Shipping}}{{end}}<br><strong>Total: {{.FormatAmount .Total}}</strong></p>
+<ul>
+{{- range .Taxes}}
+<li>incl. {{.Rate}}: {{$.FormatAmount .Amount}}</li>
+{{- end}}
+{{- range .Payments}}
+<li>Paid {{.Reference}}: {{$.FormatAmount .Amount}}</li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
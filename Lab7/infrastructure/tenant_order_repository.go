@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+)
+
+// TenantOrderRepository - OrderRepository для нескольких магазинов. У
+// каждого магазина своё хранилище, которое выбирается по магазину из
+// контекста (application.WithTenant), поэтому одинаковые идентификаторы
+// заказов разных магазинов не пересекаются, а заказ другого магазина
+// не находится (application.ErrOrderNotFound).
+type TenantOrderRepository struct {
+	mu      sync.Mutex
+	factory func(tenantID string) (application.OrderRepository, error)
+	tenants map[string]application.OrderRepository
+}
+
+// NewTenantOrderRepository создаёт репозиторий, который открывает
+// хранилище магазина через factory при первом обращении к нему
+func NewTenantOrderRepository(factory func(tenantID string) (application.OrderRepository, error)) *TenantOrderRepository {
+	return &TenantOrderRepository{
+		factory: factory,
+		tenants: make(map[string]application.OrderRepository),
+	}
+}
+
+// NewInMemoryTenantOrderRepository создаёт репозиторий, в котором каждый
+// магазин хранит заказы в отдельном InMemoryOrderRepository
+func NewInMemoryTenantOrderRepository() *TenantOrderRepository {
+	return NewTenantOrderRepository(func(string) (application.OrderRepository, error) {
+		return NewInMemoryOrderRepository(), nil
+	})
+}
+
+// GetByID загружает заказ магазина по идентификатору
+func (r *TenantOrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	repo, err := r.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, orderID)
+}
+
+// Save сохраняет заказ в хранилище магазина
+func (r *TenantOrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	repo, err := r.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Save(ctx, order)
+}
+
+// Update атомарно изменяет заказ в хранилище магазина
+func (r *TenantOrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	repo, err := r.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Update(ctx, orderID, fn)
+}
+
+// Find возвращает страницу заказов магазина, удовлетворяющих запросу
+func (r *TenantOrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	repo, err := r.scope(ctx)
+	if err != nil {
+		return application.OrderPage{}, err
+	}
+	return repo.Find(ctx, query)
+}
+
+// scope возвращает хранилище магазина из контекста
+func (r *TenantOrderRepository) scope(ctx context.Context) (application.OrderRepository, error) {
+	tenantID, err := application.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if repo, ok := r.tenants[tenantID]; ok {
+		return repo, nil
+	}
+	repo, err := r.factory(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	r.tenants[tenantID] = repo
+	return repo, nil
+}
@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+)
+
+// TenantPaymentGateway - PaymentGateway для нескольких магазинов. Каждый
+// магазин проводит платежи через свой шлюз, который выбирается по
+// магазину из контекста (application.WithTenant).
+type TenantPaymentGateway struct {
+	mu       sync.RWMutex
+	gateways map[string]application.PaymentGateway
+}
+
+// NewTenantPaymentGateway создаёт шлюз без зарегистрированных магазинов
+func NewTenantPaymentGateway() *TenantPaymentGateway {
+	return &TenantPaymentGateway{gateways: make(map[string]application.PaymentGateway)}
+}
+
+// Register задаёт шлюз магазина tenantID, заменяя прежний
+func (g *TenantPaymentGateway) Register(tenantID string, gateway application.PaymentGateway) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gateways[tenantID] = gateway
+}
+
+// Charge выполняет списание через шлюз магазина
+func (g *TenantPaymentGateway) Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	gateway, err := g.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return gateway.Charge(ctx, orderID, method, money)
+}
+
+// Refund возвращает средства через шлюз магазина
+func (g *TenantPaymentGateway) Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	gateway, err := g.scope(ctx)
+	if err != nil {
+		return err
+	}
+	return gateway.Refund(ctx, orderID, method, money)
+}
+
+// Transactions возвращает выписку шлюза магазина. Шлюз магазина должен
+// реализовывать application.TransactionLog.
+func (g *TenantPaymentGateway) Transactions(ctx context.Context) ([]application.Transaction, error) {
+	gateway, err := g.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log, ok := gateway.(application.TransactionLog)
+	if !ok {
+		tenantID, _ := application.TenantFromContext(ctx)
+		return nil, fmt.Errorf("payment gateway of %s does not list transactions", tenantID)
+	}
+	return log.Transactions(ctx)
+}
+
+// scope возвращает шлюз магазина из контекста
+func (g *TenantPaymentGateway) scope(ctx context.Context) (application.PaymentGateway, error) {
+	tenantID, err := application.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	gateway, ok := g.gateways[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no payment gateway", application.ErrUnknownTenant, tenantID)
+	}
+	return gateway, nil
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// JSONLinesExporter записывает каждый спан отдельной строкой JSON
+type JSONLinesExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesExporter создаёт экспортёр, пишущий в w
+func NewJSONLinesExporter(w io.Writer) *JSONLinesExporter {
+	return &JSONLinesExporter{w: w}
+}
+
+// NewFileExporter открывает файл на дозапись и создаёт экспортёр поверх него.
+// Файл закрывается вызывающим кодом через возвращённый *os.File.
+func NewFileExporter(path string) (*JSONLinesExporter, *os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewJSONLinesExporter(file), file, nil
+}
+
+// Export записывает спан
+func (e *JSONLinesExporter) Export(span SpanData) error {
+	data, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// InMemoryRecorder хранит завершённые спаны в памяти; используется в тестах
+type InMemoryRecorder struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryRecorder создаёт пустой recorder
+func NewInMemoryRecorder() *InMemoryRecorder {
+	return &InMemoryRecorder{}
+}
+
+// Export сохраняет спан
+func (r *InMemoryRecorder) Export(span SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+// Spans возвращает копию завершённых спанов в порядке завершения
+func (r *InMemoryRecorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spansCopy := make([]SpanData, len(r.spans))
+	copy(spansCopy, r.spans)
+	return spansCopy
+}
+
+// Reset удаляет сохранённые спаны
+func (r *InMemoryRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+// Middleware - серверный HTTP-адаптер: продолжает трассу из заголовка
+// traceparent и открывает спан на время обработки запроса
+func Middleware(tracer *Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := Extract(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, "http.server "+r.Method)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.path", r.URL.Path)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttribute("http.status_code", recorder.status)
+		if recorder.status >= http.StatusInternalServerError {
+			span.RecordError(httpStatusError(recorder.status))
+		}
+	})
+}
+
+// Transport - клиентский HTTP-адаптер: открывает спан на время запроса
+// и передаёт его в заголовке traceparent
+type Transport struct {
+	Tracer *Tracer
+	// Base - нижележащий транспорт; по умолчанию http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// RoundTrip выполняет запрос
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := t.Tracer.Start(req.Context(), "http.client "+req.Method)
+	defer span.End()
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", req.URL.Redacted())
+
+	// RoundTripper не должен изменять исходный запрос
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.RecordError(httpStatusError(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// statusRecorder запоминает код ответа обработчика
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// httpStatusError - ошибка для ответов с кодом 5xx
+type httpStatusError int
+
+func (e httpStatusError) Error() string {
+	return "http status " + http.StatusText(int(e))
+}
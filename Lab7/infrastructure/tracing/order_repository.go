@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+)
+
+// OrderRepository - декоратор OrderRepository, открывающий спан на каждое обращение
+type OrderRepository struct {
+	next   application.OrderRepository
+	tracer *Tracer
+}
+
+// NewOrderRepository создаёт трассирующий декоратор репозитория
+func NewOrderRepository(next application.OrderRepository, tracer *Tracer) *OrderRepository {
+	return &OrderRepository{next: next, tracer: tracer}
+}
+
+// GetByID загружает заказ по идентификатору
+func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetByID")
+	defer span.End()
+	span.SetAttribute("order.id", orderID)
+
+	order, err := r.next.GetByID(ctx, orderID)
+	finish(span, err)
+	return order, err
+}
+
+// Save сохраняет заказ
+func (r *OrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.Save")
+	defer span.End()
+	span.SetAttribute("order.id", order.ID())
+	span.SetAttribute("order.status", order.Status().String())
+
+	err := r.next.Save(ctx, order)
+	finish(span, err)
+	return err
+}
+
+// Update атомарно изменяет заказ
+func (r *OrderRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.Update")
+	defer span.End()
+	span.SetAttribute("order.id", orderID)
+
+	err := r.next.Update(ctx, orderID, fn)
+	finish(span, err)
+	return err
+}
+
+// Find возвращает страницу заказов, удовлетворяющих запросу
+func (r *OrderRepository) Find(ctx context.Context, query application.OrderQuery) (application.OrderPage, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.Find")
+	defer span.End()
+
+	page, err := r.next.Find(ctx, query)
+	span.SetAttribute("results", len(page.Orders))
+	finish(span, err)
+	return page, err
+}
+
+// finish записывает в спан код ошибки и саму ошибку
+func finish(span *Span, err error) {
+	if err != nil {
+		span.SetAttribute("error.code", application.ErrorCode(err))
+		span.RecordError(err)
+	}
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"lab7/application"
+	"lab7/domain"
+)
+
+// PaymentGateway - декоратор PaymentGateway, открывающий спан на каждый вызов
+type PaymentGateway struct {
+	next   application.PaymentGateway
+	tracer *Tracer
+}
+
+// NewPaymentGateway создаёт трассирующий декоратор платёжного шлюза
+func NewPaymentGateway(next application.PaymentGateway, tracer *Tracer) *PaymentGateway {
+	return &PaymentGateway{next: next, tracer: tracer}
+}
+
+// Charge выполняет списание средств
+func (g *PaymentGateway) Charge(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	ctx, span := g.tracer.Start(ctx, "PaymentGateway.Charge")
+	defer span.End()
+	setMoneyAttributes(span, orderID, method, money)
+
+	err := g.next.Charge(ctx, orderID, method, money)
+	finish(span, err)
+	return err
+}
+
+// Refund возвращает ранее списанные средства
+func (g *PaymentGateway) Refund(ctx context.Context, orderID string, method domain.PaymentMethod, money domain.Money) error {
+	ctx, span := g.tracer.Start(ctx, "PaymentGateway.Refund")
+	defer span.End()
+	setMoneyAttributes(span, orderID, method, money)
+
+	err := g.next.Refund(ctx, orderID, method, money)
+	finish(span, err)
+	return err
+}
+
+// setMoneyAttributes добавляет атрибуты платежа
+func setMoneyAttributes(span *Span, orderID string, method domain.PaymentMethod, money domain.Money) {
+	span.SetAttribute("order.id", orderID)
+	if !method.IsZero() {
+		span.SetAttribute("payment.method", method.Kind().String())
+	}
+	span.SetAttribute("payment.amount", money.Amount())
+	span.SetAttribute("payment.currency", money.Currency())
+}
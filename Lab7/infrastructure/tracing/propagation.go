@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader - заголовок W3C Trace Context
+const TraceparentHeader = "traceparent"
+
+// ErrInvalidTraceparent - заголовок traceparent не соответствует формату
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+const flagSampled = 0x01
+
+// FormatTraceparent форматирует SpanContext как значение traceparent версии 00
+func FormatTraceparent(sc SpanContext) string {
+	flags := byte(0)
+	if sc.Sampled {
+		flags = flagSampled
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent разбирает значение заголовка traceparent
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	version, err := decodeHex(parts[0], 1)
+	if err != nil || version[0] == 0xff {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	// Версия 00 состоит ровно из четырёх частей; более новые версии
+	// могут добавлять поля в конец
+	if version[0] == 0 && len(parts) != 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	traceID, err := decodeHex(parts[1], 16)
+	if err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	spanID, err := decodeHex(parts[2], 8)
+	if err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	var sc SpanContext
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&flagSampled != 0
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex декодирует строку из строчных шестнадцатеричных символов длиной size байт
+func decodeHex(s string, size int) ([]byte, error) {
+	if len(s) != size*2 || strings.ToLower(s) != s {
+		return nil, ErrInvalidTraceparent
+	}
+	return hex.DecodeString(s)
+}
+
+// Inject записывает текущий спан контекста в заголовки
+func Inject(ctx context.Context, header http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		header.Set(TraceparentHeader, FormatTraceparent(sc))
+	}
+}
+
+// Extract возвращает контекст с удалённым родительским спаном из заголовков.
+// Некорректный заголовок игнорируется, и трасса начинается заново.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	return ContextWithSpanContext(ctx, sc)
+}
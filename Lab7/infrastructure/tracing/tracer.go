@@ -0,0 +1,203 @@
+// Package tracing - лёгкая абстракция трассировки: спаны с атрибутами и
+// ошибками, связь родительских и дочерних спанов через context.Context,
+// экспорт в JSON Lines или в память и распространение контекста по
+// заголовку W3C traceparent.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID - идентификатор трассы (16 байт)
+type TraceID [16]byte
+
+// String возвращает идентификатор в виде 32 шестнадцатеричных символов
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid проверяет, что идентификатор не нулевой
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// SpanID - идентификатор спана (8 байт)
+type SpanID [8]byte
+
+// String возвращает идентификатор в виде 16 шестнадцатеричных символов
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid проверяет, что идентификатор не нулевой
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// SpanContext - идентификаторы спана, передаваемые между процессами
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid проверяет, что оба идентификатора заданы
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Статусы завершённого спана
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// SpanData - завершённый спан, передаваемый экспортёру
+type SpanData struct {
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Duration     time.Duration  `json:"duration_ns"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Exporter - получатель завершённых спанов
+type Exporter interface {
+	Export(span SpanData) error
+}
+
+// Tracer создаёт спаны и передаёт завершённые спаны экспортёру
+type Tracer struct {
+	exporter Exporter
+	now      func() time.Time
+}
+
+// NewTracer создаёт трассировщик с указанным экспортёром
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter, now: time.Now}
+}
+
+// Start открывает спан. Если в ctx уже есть спан (локальный или
+// полученный из traceparent), новый спан становится его дочерним.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	sc := SpanContext{SpanID: newSpanID(), Sampled: true}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+	}
+
+	span := &Span{
+		tracer:     t,
+		context:    sc,
+		parent:     parent.SpanID,
+		name:       name,
+		start:      t.now(),
+		attributes: make(map[string]any),
+	}
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+// Span - выполняемая операция в трассе
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+	parent  SpanID
+	name    string
+	start   time.Time
+
+	mu         sync.Mutex
+	attributes map[string]any
+	err        error
+	ended      bool
+}
+
+// SpanContext возвращает идентификаторы спана
+func (s *Span) SpanContext() SpanContext { return s.context }
+
+// SetAttribute добавляет атрибут спана
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// RecordError отмечает спан как завершившийся ошибкой
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End завершает спан и передаёт его экспортёру; повторные вызовы игнорируются
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+
+	end := s.tracer.now()
+	data := SpanData{
+		TraceID:    s.context.TraceID.String(),
+		SpanID:     s.context.SpanID.String(),
+		Name:       s.name,
+		Start:      s.start,
+		End:        end,
+		Duration:   end.Sub(s.start),
+		Attributes: make(map[string]any, len(s.attributes)),
+		Status:     StatusOK,
+	}
+	if s.parent.IsValid() {
+		data.ParentSpanID = s.parent.String()
+	}
+	for key, value := range s.attributes {
+		data.Attributes[key] = value
+	}
+	if s.err != nil {
+		data.Status = StatusError
+		data.Error = s.err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.context.Sampled {
+		// Ошибка экспорта не должна влиять на трассируемую операцию
+		_ = s.tracer.exporter.Export(data)
+	}
+}
+
+// spanContextKey - ключ SpanContext в контексте
+type spanContextKey struct{}
+
+// ContextWithSpanContext возвращает контекст с текущим спаном
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext возвращает текущий спан контекста или пустое значение
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"lab7/application"
+)
+
+// PayOrderUseCase - декоратор use-case оплаты, открывающий корневой спан Execute
+type PayOrderUseCase struct {
+	next   application.PayOrderExecutor
+	tracer *Tracer
+}
+
+// NewPayOrderUseCase создаёт трассирующий декоратор use-case оплаты
+func NewPayOrderUseCase(next application.PayOrderExecutor, tracer *Tracer) *PayOrderUseCase {
+	return &PayOrderUseCase{next: next, tracer: tracer}
+}
+
+// Execute выполняет оплату заказа
+func (uc *PayOrderUseCase) Execute(ctx context.Context, orderID string) (application.PayOrderResult, error) {
+	return uc.ExecuteCommand(ctx, application.PayOrderCommand{OrderID: orderID})
+}
+
+// ExecuteCommand выполняет оплату заказа частями. Спан называется так же,
+// как для Execute: это одна и та же операция.
+func (uc *PayOrderUseCase) ExecuteCommand(ctx context.Context, cmd application.PayOrderCommand) (application.PayOrderResult, error) {
+	ctx, span := uc.tracer.Start(ctx, "PayOrderUseCase.Execute")
+	defer span.End()
+	span.SetAttribute("order.id", cmd.OrderID)
+
+	result, err := uc.next.ExecuteCommand(ctx, cmd)
+	if result.Currency != "" {
+		span.SetAttribute("payment.amount", result.Amount)
+		span.SetAttribute("payment.currency", result.Currency)
+	}
+	finish(span, err)
+	return result, err
+}
+
+// RefundOrderUseCase - декоратор use-case возврата, открывающий корневой спан Execute
+type RefundOrderUseCase struct {
+	next   application.RefundOrderExecutor
+	tracer *Tracer
+}
+
+// NewRefundOrderUseCase создаёт трассирующий декоратор use-case возврата
+func NewRefundOrderUseCase(next application.RefundOrderExecutor, tracer *Tracer) *RefundOrderUseCase {
+	return &RefundOrderUseCase{next: next, tracer: tracer}
+}
+
+// Execute выполняет возврат средств за заказ
+func (uc *RefundOrderUseCase) Execute(ctx context.Context, orderID string) (application.RefundOrderResult, error) {
+	ctx, span := uc.tracer.Start(ctx, "RefundOrderUseCase.Execute")
+	defer span.End()
+	span.SetAttribute("order.id", orderID)
+
+	result, err := uc.next.Execute(ctx, orderID)
+	if result.Currency != "" {
+		span.SetAttribute("payment.amount", result.Amount)
+		span.SetAttribute("payment.currency", result.Currency)
+	}
+	finish(span, err)
+	return result, err
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"lab7/application"
 	"lab7/domain"
@@ -8,7 +9,9 @@ import (
 )
 
 func main() {
-	fmt.Println("=== Lab7: Architecture, Layers, and DDD-lite ===\n")
+	fmt.Print("=== Lab7: Architecture, Layers, and DDD-lite ===\n\n")
+
+	ctx := context.Background()
 
 	// Создаём инфраструктуру
 	repo := infrastructure.NewInMemoryOrderRepository()
@@ -34,7 +37,7 @@ func main() {
 	order.AddLine(line3)
 
 	// Сохраняем заказ
-	repo.Save(order)
+	repo.Save(ctx, order)
 
 	// Выводим информацию о заказе
 	fmt.Printf("Order ID: %s\n", order.ID())
@@ -52,7 +55,7 @@ func main() {
 
 	// Оплачиваем заказ
 	fmt.Println("Paying order...")
-	result, err := payOrderUseCase.Execute("order-123")
+	result, err := payOrderUseCase.Execute(ctx, "order-123")
 
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
@@ -62,7 +65,7 @@ func main() {
 	fmt.Printf("Result: %s\n", result.Message)
 
 	// Проверяем статус заказа после оплаты
-	paidOrder, _ := repo.GetByID("order-123")
+	paidOrder, _ := repo.GetByID(ctx, "order-123")
 	fmt.Printf("Order status after payment: %s\n", paidOrder.Status())
 
 	// Пытаемся добавить новую строку в оплаченный заказ
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/audit"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// auditedFlow создаёт, дополняет, оплачивает и возвращает заказ через
+// репозиторий с журналом
+func auditedFlow(t *testing.T, log *audit.Log) {
+	t.Helper()
+	repo := audit.NewOrderRepository(infrastructure.NewInMemoryOrderRepository(), log)
+	gateway := infrastructure.NewFakePaymentGateway()
+	customer := application.WithActor(t.Context(), "alice")
+	finance := application.WithRequestID(application.WithActor(t.Context(), "bob"), "req-7")
+
+	if _, err := application.NewCreateOrderUseCase(repo).Execute(customer, application.CreateOrderCommand{OrderID: "order-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	addLine := application.NewAddOrderLineUseCase(repo)
+	if _, err := addLine.Execute(customer, application.AddOrderLineCommand{OrderID: "order-1", ProductID: "product-1", UnitPrice: 1500, Currency: "RUB", Quantity: 2}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := application.NewPayOrderUseCase(repo, gateway).Execute(customer, "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := application.NewRefundOrderUseCase(repo, gateway).Execute(finance, "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Повторное сохранение без изменений в журнал не попадает
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if err := repo.Save(t.Context(), order); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+// TestAudit_RecordsOrderChanges проверяет записи журнала по жизненному циклу заказа
+func TestAudit_RecordsOrderChanges(t *testing.T) {
+	at := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	log, err := audit.NewLog(t.Context(), audit.NewMemoryStore(), audit.WithClock(func() time.Time { return at }))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	auditedFlow(t, log)
+
+	entries, err := log.Entries(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := []struct {
+		action audit.Action
+		actor  string
+		status string
+	}{
+		{audit.ActionCreated, "alice", "PENDING"},
+		{audit.ActionLinesChanged, "alice", "PENDING"},
+		{audit.ActionPaid, "alice", "PAID"},
+		{audit.ActionRefunded, "bob", "REFUNDED"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Seq != uint64(i+1) || e.Action != w.action || e.Actor != w.actor || e.After.Status != w.status || !e.At.Equal(at) {
+			t.Errorf("entry %d: expected %s by %s -> %s, got %+v", i+1, w.action, w.actor, w.status, e)
+		}
+	}
+	if entries[0].Before != nil || entries[0].PrevHash != "" {
+		t.Errorf("expected first entry without before state and previous hash, got %+v", entries[0])
+	}
+	if entries[1].Before == nil || len(entries[1].Before.Lines) != 0 || len(entries[1].After.Lines) != 1 || entries[1].After.Total != 3000 {
+		t.Errorf("expected lines change from none to one, got %+v", entries[1])
+	}
+	if entries[3].RequestID != "req-7" || entries[3].PrevHash != entries[2].Hash {
+		t.Errorf("expected chained refund entry with request id, got %+v", entries[3])
+	}
+	if err := log.Verify(t.Context()); err != nil {
+		t.Errorf("expected intact log, got: %v", err)
+	}
+}
+
+// TestAudit_VerifyDetectsTampering проверяет обнаружение изменённых,
+// удалённых и переставленных записей
+func TestAudit_VerifyDetectsTampering(t *testing.T) {
+	store := audit.NewMemoryStore()
+	log, _ := audit.NewLog(t.Context(), store)
+	auditedFlow(t, log)
+	entries, _ := store.Entries(t.Context())
+	if err := audit.Verify(entries); err != nil {
+		t.Fatalf("expected intact log, got: %v", err)
+	}
+
+	cases := map[string]func([]audit.Entry) []audit.Entry{
+		"modified actor": func(e []audit.Entry) []audit.Entry {
+			e[3].Actor = "alice"
+			return e
+		},
+		"modified snapshot": func(e []audit.Entry) []audit.Entry {
+			after := *e[2].After
+			after.Total = 1
+			e[2].After = &after
+			return e
+		},
+		"removed entry": func(e []audit.Entry) []audit.Entry {
+			return append(e[:1], e[2:]...)
+		},
+		"swapped entries": func(e []audit.Entry) []audit.Entry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			tampered := tamper(append([]audit.Entry(nil), entries...))
+			if err := audit.Verify(tampered); !errors.Is(err, audit.ErrTampered) {
+				t.Errorf("expected ErrTampered, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestAudit_FileStore проверяет продолжение цепочки после повторного
+// открытия файла и обнаружение правок в файле
+func TestAudit_FileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	open := func() (*audit.Log, *audit.FileStore, error) {
+		store, err := audit.NewFileStore(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		log, err := audit.NewLog(t.Context(), store)
+		return log, store, err
+	}
+
+	log, _, err := open()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	auditedFlow(t, log)
+
+	// После повторного открытия записи продолжают цепочку
+	log, store, err := open()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	view := application.OrderView{ID: "order-2", Status: domain.OrderStatusPending.String()}
+	entry, err := log.Record(context.Background(), audit.ActionCreated, "order-2", nil, &view)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if entry.Seq != 5 || entry.Actor != audit.ActorSystem {
+		t.Errorf("expected entry 5 by system, got %+v", entry)
+	}
+	if err := log.Verify(t.Context()); err != nil {
+		t.Fatalf("expected intact log, got: %v", err)
+	}
+	entries, _ := store.Entries(t.Context())
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+
+	// Удаление последней записи не нарушает цепочку, но журнал помнит свою голову
+	os.WriteFile(path, bytes.Join(lines[:4], nil), 0o644)
+	if err := log.Verify(t.Context()); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("expected ErrTampered for a truncated log, got: %v", err)
+	}
+
+	// Правка суммы в записи об оплате
+	edited := strings.Replace(string(data), `"total":3000`, `"total":300`, 1)
+	os.WriteFile(path, []byte(edited), 0o644)
+	if err := log.Verify(t.Context()); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("expected ErrTampered for an edited entry, got: %v", err)
+	}
+	if _, _, err := open(); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("expected NewLog to reject a tampered file, got: %v", err)
+	}
+}
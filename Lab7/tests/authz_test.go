@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/authz"
+	"testing"
+)
+
+// Пользователи для проверок доступа
+var (
+	alice   = application.Principal{ID: "alice", Roles: []application.Role{application.RoleCustomer}}
+	bob     = application.Principal{ID: "bob", Roles: []application.Role{application.RoleCustomer}}
+	support = application.Principal{ID: "sam", Roles: []application.Role{application.RoleSupport}}
+	finance = application.Principal{ID: "fiona", Roles: []application.Role{application.RoleFinance}}
+	admin   = application.Principal{ID: "root", Roles: []application.Role{application.RoleAdmin}}
+)
+
+// assertDenied проверяет, что err - типизированный отказ в доступе
+func assertDenied(t *testing.T, err error, permission application.Permission) {
+	t.Helper()
+	var denied *application.AccessDeniedError
+	if !errors.As(err, &denied) || !errors.Is(err, application.ErrForbidden) {
+		t.Fatalf("expected AccessDeniedError, got: %v", err)
+	}
+	if code := application.ErrorCode(err); code != application.ErrorCodeForbidden {
+		t.Errorf("expected %s error code, got %s", application.ErrorCodeForbidden, code)
+	}
+	if denied.Permission != permission {
+		t.Errorf("expected denial of %s, got %s", permission, denied.Permission)
+	}
+}
+
+// TestAuthz_PayAndRefund проверяет права на оплату и возврат
+func TestAuthz_PayAndRefund(t *testing.T) {
+	repo, gateway, payUseCase := setupTestEnvironment()
+	saveCustomerOrder(t, repo, "order-alice", "alice")
+	saveCustomerOrder(t, repo, "order-bob", "bob")
+	policy := application.DefaultPolicy()
+	pay := authz.NewPayOrderUseCase(payUseCase, repo, policy)
+	refund := authz.NewRefundOrderUseCase(application.NewRefundOrderUseCase(repo, gateway), repo, policy)
+
+	// Клиент оплачивает только свой заказ
+	_, err := pay.Execute(application.WithPrincipal(t.Context(), alice), "order-bob")
+	assertDenied(t, err, application.PermissionPayOrder)
+	if len(gateway.GetPayments()) != 0 {
+		t.Fatal("expected no charge for a denied payment")
+	}
+	if _, err := pay.Execute(application.WithPrincipal(t.Context(), alice), "order-alice"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Поддержка не проводит платежи, клиент не делает возвраты
+	_, err = pay.Execute(application.WithPrincipal(t.Context(), support), "order-bob")
+	assertDenied(t, err, application.PermissionPayOrder)
+	_, err = refund.Execute(application.WithPrincipal(t.Context(), alice), "order-alice")
+	assertDenied(t, err, application.PermissionRefundOrder)
+
+	// Возврат выполняют финансы
+	if _, err := refund.Execute(application.WithPrincipal(t.Context(), finance), "order-alice"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(gateway.GetRefunds()) != 1 {
+		t.Errorf("expected one refund, got %d", len(gateway.GetRefunds()))
+	}
+
+	// Без пользователя в контексте операции отклоняются
+	_, err = pay.Execute(t.Context(), "order-bob")
+	if !errors.Is(err, application.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got: %v", err)
+	}
+	if code := application.ErrorCode(err); code != application.ErrorCodeUnauthenticated {
+		t.Errorf("expected %s error code, got %s", application.ErrorCodeUnauthenticated, code)
+	}
+
+	// Администратору разрешено всё
+	if _, err := pay.Execute(application.WithPrincipal(t.Context(), admin), "order-bob"); err != nil {
+		t.Errorf("expected admin to pay, got: %v", err)
+	}
+}
+
+// TestAuthz_PayCommand проверяет права на оплату частями: проверка не
+// обходится через ExecuteCommand
+func TestAuthz_PayCommand(t *testing.T) {
+	repo, gateway, payUseCase := setupTestEnvironment()
+	saveCustomerOrder(t, repo, "order-bob", "bob")
+	pay := authz.NewPayOrderUseCase(payUseCase, repo, application.DefaultPolicy())
+	cmd := application.PayOrderCommand{OrderID: "order-bob", Legs: []application.PaymentLegCommand{
+		{Method: "CARD", Reference: "tok_1", Amount: 6000},
+		{Method: "WALLET", Reference: "wallet-1", Amount: 4000},
+	}}
+
+	_, err := pay.ExecuteCommand(application.WithPrincipal(t.Context(), alice), cmd)
+	assertDenied(t, err, application.PermissionPayOrder)
+	if len(gateway.GetPayments()) != 0 {
+		t.Fatal("expected no charge for a denied payment")
+	}
+	if _, err := pay.ExecuteCommand(t.Context(), cmd); !errors.Is(err, application.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got: %v", err)
+	}
+	if _, err := pay.ExecuteCommand(application.WithPrincipal(t.Context(), bob), cmd); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(gateway.GetPayments()) != 2 {
+		t.Errorf("expected 2 charges, got %d", len(gateway.GetPayments()))
+	}
+}
+
+// TestAuthz_OrderRepository проверяет права на чтение, изменение и поиск заказов
+func TestAuthz_OrderRepository(t *testing.T) {
+	raw := infrastructure.NewInMemoryOrderRepository()
+	saveCustomerOrder(t, raw, "order-alice", "alice")
+	saveCustomerOrder(t, raw, "order-bob", "bob")
+	repo := authz.NewOrderRepository(raw, application.DefaultPolicy())
+	asAlice := application.WithPrincipal(t.Context(), alice)
+
+	if _, err := repo.GetByID(asAlice, "order-alice"); err != nil {
+		t.Errorf("expected own order, got: %v", err)
+	}
+	_, err := repo.GetByID(asAlice, "order-bob")
+	assertDenied(t, err, application.PermissionReadOrder)
+	if _, err := repo.GetByID(t.Context(), "order-alice"); !errors.Is(err, application.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got: %v", err)
+	}
+
+	// Клиент видит в поиске только свои заказы
+	page, err := repo.Find(asAlice, application.OrderQuery{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(page.Orders) != 1 || page.Orders[0].ID() != "order-alice" {
+		t.Errorf("expected only alice's order, got %d orders", len(page.Orders))
+	}
+	_, err = repo.Find(asAlice, application.OrderQuery{CustomerID: "bob"})
+	assertDenied(t, err, application.PermissionListOrders)
+	page, _ = repo.Find(application.WithPrincipal(t.Context(), finance), application.OrderQuery{})
+	if len(page.Orders) != 2 {
+		t.Errorf("expected finance to see all orders, got %d", len(page.Orders))
+	}
+
+	// Клиент не может создать заказ от имени другого клиента или изменить чужой
+	err = repo.Save(asAlice, domain.NewOrderForCustomer("order-new", "bob"))
+	assertDenied(t, err, application.PermissionWriteOrder)
+	bobsOrder, _ := raw.GetByID(t.Context(), "order-bob")
+	assertDenied(t, repo.Save(asAlice, bobsOrder), application.PermissionWriteOrder)
+	if err := repo.Save(asAlice, domain.NewOrderForCustomer("order-new", "alice")); err != nil {
+		t.Errorf("expected own order to be created, got: %v", err)
+	}
+
+	// Финансы не изменяют заказы, поддержка изменяет любые
+	assertDenied(t, repo.Save(application.WithPrincipal(t.Context(), finance), bobsOrder), application.PermissionWriteOrder)
+	if err := repo.Save(application.WithPrincipal(t.Context(), support), bobsOrder); err != nil {
+		t.Errorf("expected support to modify any order, got: %v", err)
+	}
+}
+
+// TestAuthz_LayeredWiring проверяет use-case, построенные поверх
+// authz.OrderRepository: разрешённый возврат сохраняется без права на
+// изменение заказа, а прямое изменение финансам по-прежнему запрещено
+func TestAuthz_LayeredWiring(t *testing.T) {
+	raw := infrastructure.NewInMemoryOrderRepository()
+	saveCustomerOrder(t, raw, "order-alice", "alice")
+	saveCustomerOrder(t, raw, "order-bob", "bob")
+	policy := application.DefaultPolicy()
+	repo := authz.NewOrderRepository(raw, policy)
+	gateway := infrastructure.NewFakePaymentGateway()
+	pay := authz.NewPayOrderUseCase(application.NewPayOrderUseCase(repo, gateway), repo, policy)
+	refund := authz.NewRefundOrderUseCase(application.NewRefundOrderUseCase(repo, gateway), repo, policy)
+	asFinance := application.WithPrincipal(t.Context(), finance)
+
+	if _, err := pay.Execute(application.WithPrincipal(t.Context(), alice), "order-alice"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := refund.Execute(asFinance, "order-alice"); err != nil {
+		t.Fatalf("expected finance to refund through the decorated repository, got: %v", err)
+	}
+	if status := orderStatus(t, raw, "order-alice"); status != domain.OrderStatusRefunded {
+		t.Errorf("expected order to be REFUNDED, got %s", status)
+	}
+
+	_, err := refund.Execute(application.WithPrincipal(t.Context(), alice), "order-alice")
+	assertDenied(t, err, application.PermissionRefundOrder)
+	bobsOrder, _ := raw.GetByID(t.Context(), "order-bob")
+	assertDenied(t, repo.Save(asFinance, bobsOrder), application.PermissionWriteOrder)
+}
+
+// TestAuthz_CustomPolicy проверяет политику с собственными разрешениями
+func TestAuthz_CustomPolicy(t *testing.T) {
+	policy := application.NewPolicy().Grant(application.RoleSupport, application.ScopeAny, application.PermissionRefundOrder)
+	order := domain.NewOrderForCustomer("order-1", "alice")
+
+	if err := policy.Authorize(support, application.PermissionRefundOrder, order); err != nil {
+		t.Errorf("expected granted refund, got: %v", err)
+	}
+	assertDenied(t, policy.Authorize(support, application.PermissionReadOrder, order), application.PermissionReadOrder)
+	assertDenied(t, policy.Authorize(alice, application.PermissionReadOrder, order), application.PermissionReadOrder)
+
+	// Разрешения нескольких ролей объединяются
+	both := application.Principal{ID: "alice", Roles: []application.Role{application.RoleCustomer, application.RoleSupport}}
+	if policy.Scope(both, application.PermissionRefundOrder) != application.ScopeAny {
+		t.Error("expected refund scope from the support role")
+	}
+}
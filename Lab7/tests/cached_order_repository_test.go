@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedRepository - хранилище, в котором GetByID ждёт открытия gate и
+// считает обращения
+type gatedRepository struct {
+	*infrastructure.InMemoryOrderRepository
+	gate  chan struct{}
+	loads atomic.Int64
+}
+
+func (r *gatedRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	r.loads.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.InMemoryOrderRepository.GetByID(ctx, orderID)
+}
+
+// newGatedRepository создаёт хранилище с заказами order-1 ... order-3
+func newGatedRepository(t *testing.T) *gatedRepository {
+	t.Helper()
+	repo := &gatedRepository{InMemoryOrderRepository: infrastructure.NewInMemoryOrderRepository()}
+	for _, id := range orderIDs(3) {
+		saveOrderWithLines(t, repo.InMemoryOrderRepository, id, stockLine{"product-1", 1})
+	}
+	return repo
+}
+
+// TestCachedOrderRepository_HitsAndIsolation проверяет попадания и изоляцию копий
+func TestCachedOrderRepository_HitsAndIsolation(t *testing.T) {
+	backend := newGatedRepository(t)
+	cache := infrastructure.NewCachedOrderRepository(backend, 10)
+
+	first, err := cache.GetByID(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Изменение полученного заказа не попадает в кеш
+	line, _ := domain.NewOrderLine("product-2", rub(500), 1)
+	first.AddLine(line)
+
+	second, err := cache.GetByID(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(second.Lines()) != 1 {
+		t.Errorf("expected cached order to be isolated from caller changes, got %d lines", len(second.Lines()))
+	}
+	if first == second {
+		t.Error("expected a fresh copy on every hit")
+	}
+
+	if _, err := cache.GetByID(t.Context(), "missing"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got: %v", err)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Loads != 2 || backend.loads.Load() != 2 {
+		t.Errorf("unexpected stats %+v with %d loads", stats, backend.loads.Load())
+	}
+	if stats.HitRatio() < 0.33 || stats.HitRatio() > 0.34 {
+		t.Errorf("expected hit ratio 1/3, got %f", stats.HitRatio())
+	}
+}
+
+// TestCachedOrderRepository_SaveInvalidates проверяет сброс заказа при сохранении
+func TestCachedOrderRepository_SaveInvalidates(t *testing.T) {
+	cache := infrastructure.NewCachedOrderRepository(newGatedRepository(t), 10)
+
+	order, _ := cache.GetByID(t.Context(), "order-1")
+	order.Pay()
+	if err := cache.Save(t.Context(), order); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	reloaded, _ := cache.GetByID(t.Context(), "order-1")
+	if !reloaded.IsPaid() {
+		t.Error("expected saved state after invalidation")
+	}
+	if stats := cache.Stats(); stats.Misses != 2 || stats.Hits != 0 {
+		t.Errorf("expected a miss after save, got %+v", stats)
+	}
+}
+
+// TestCachedOrderRepository_TTLAndLRU проверяет устаревание и вытеснение записей
+func TestCachedOrderRepository_TTLAndLRU(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	backend := newGatedRepository(t)
+	cache := infrastructure.NewCachedOrderRepository(backend, 2,
+		infrastructure.CacheTTL(time.Minute),
+		infrastructure.CacheClock(func() time.Time { return now }))
+
+	get := func(id string) {
+		t.Helper()
+		if _, err := cache.GetByID(t.Context(), id); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	get("order-1")
+	get("order-2")
+	get("order-1") // order-1 использован позже order-2
+	get("order-3") // вытесняет order-2
+	if cache.Len() != 2 || cache.Stats().Evictions != 1 {
+		t.Fatalf("expected 2 cached orders and 1 eviction, got %d and %+v", cache.Len(), cache.Stats())
+	}
+	loads := backend.loads.Load()
+	get("order-1")
+	if backend.loads.Load() != loads {
+		t.Error("expected order-1 to stay cached")
+	}
+	get("order-2")
+	if backend.loads.Load() != loads+1 {
+		t.Error("expected evicted order-2 to be loaded again")
+	}
+
+	now = now.Add(time.Minute)
+	loads = backend.loads.Load()
+	get("order-2")
+	if backend.loads.Load() != loads+1 {
+		t.Error("expected expired order to be loaded again")
+	}
+}
+
+// TestCachedOrderRepository_SingleFlight проверяет, что одновременные
+// промахи загружают заказ один раз
+func TestCachedOrderRepository_SingleFlight(t *testing.T) {
+	backend := newGatedRepository(t)
+	backend.gate = make(chan struct{})
+	cache := infrastructure.NewCachedOrderRepository(backend, 10)
+
+	const readers = 20
+	orders := make([]*domain.Order, readers)
+	var wg sync.WaitGroup
+	for i := range readers {
+		wg.Go(func() {
+			order, err := cache.GetByID(t.Context(), "order-1")
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+			orders[i] = order
+		})
+	}
+	for cache.Stats().Misses < readers {
+		time.Sleep(time.Millisecond)
+	}
+	close(backend.gate)
+	wg.Wait()
+
+	if backend.loads.Load() != 1 || cache.Stats().Loads != 1 {
+		t.Errorf("expected a single load, got %d", backend.loads.Load())
+	}
+	for i := 1; i < readers; i++ {
+		if orders[i] == nil || orders[i] == orders[0] {
+			t.Fatalf("expected every reader to get its own copy")
+		}
+	}
+}
+
+// TestCachedOrderRepository_SaveDuringLoad проверяет, что результат
+// загрузки, начатой до сохранения, не попадает в кеш
+func TestCachedOrderRepository_SaveDuringLoad(t *testing.T) {
+	backend := newGatedRepository(t)
+	backend.gate = make(chan struct{})
+	cache := infrastructure.NewCachedOrderRepository(backend, 10)
+
+	// Читатель с отменённым контекстом перестаёт ждать, загрузка продолжается
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() {
+		_, err := cache.GetByID(ctx, "order-1")
+		done <- err
+	}()
+	for backend.loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+
+	order, _ := backend.InMemoryOrderRepository.GetByID(t.Context(), "order-1")
+	order.Pay()
+	if err := cache.Save(t.Context(), order); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	close(backend.gate)
+
+	for {
+		reloaded, err := cache.GetByID(t.Context(), "order-1")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if reloaded.IsPaid() {
+			break
+		}
+		// Устаревшая загрузка могла ещё не завершиться; она не кешируется
+		if backend.loads.Load() > 10 {
+			t.Fatal("expected the paid order to be loaded")
+		}
+	}
+	if cached, _ := cache.GetByID(t.Context(), "order-1"); !cached.IsPaid() {
+		t.Error("expected the cache to hold the saved state")
+	}
+}
+
+// TestCachedOrderRepository_Tenants проверяет, что заказы разных магазинов
+// с одинаковым идентификатором не смешиваются в кеше
+func TestCachedOrderRepository_Tenants(t *testing.T) {
+	cache := infrastructure.NewCachedOrderRepository(infrastructure.NewInMemoryTenantOrderRepository(), 10)
+	shopA := application.WithTenant(t.Context(), "shop-a")
+	shopB := application.WithTenant(t.Context(), "shop-b")
+
+	for ctx, quantity := range map[context.Context]int{shopA: 1, shopB: 2} {
+		order := domain.NewOrder("order-1")
+		line, _ := domain.NewOrderLine("product-1", rub(1000), quantity)
+		order.AddLine(line)
+		if err := cache.Save(ctx, order); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+
+	// Заказ магазина A в кеше не отдаётся магазину B
+	for _, ctx := range []context.Context{shopA, shopB, shopA, shopB} {
+		cache.GetByID(ctx, "order-1")
+	}
+	a, _ := cache.GetByID(shopA, "order-1")
+	b, _ := cache.GetByID(shopB, "order-1")
+	if a.Lines()[0].Quantity() != 1 || b.Lines()[0].Quantity() != 2 {
+		t.Errorf("expected quantity 1 for shop-a and 2 for shop-b, got %d and %d", a.Lines()[0].Quantity(), b.Lines()[0].Quantity())
+	}
+	if stats := cache.Stats(); stats.Loads != 2 || stats.Hits != 4 {
+		t.Errorf("expected one load per tenant, got %+v", stats)
+	}
+
+	// Сохранение в магазине B сбрасывает только его запись
+	b.Pay()
+	if err := cache.Save(shopB, b); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected shop-a entry to stay cached, got %d entries", cache.Len())
+	}
+	if order, _ := cache.GetByID(shopA, "order-1"); order.Status() != domain.OrderStatusPending {
+		t.Errorf("expected shop-a order to stay PENDING, got %s", order.Status())
+	}
+	if order, _ := cache.GetByID(shopB, "order-1"); order.Status() != domain.OrderStatusPaid {
+		t.Errorf("expected shop-b order to be PAID, got %s", order.Status())
+	}
+}
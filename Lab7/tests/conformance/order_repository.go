@@ -0,0 +1,425 @@
+// Package conformance содержит наборы проверок, которым должна
+// удовлетворять любая реализация портов application.OrderRepository и
+// application.PaymentGateway. Набор получает фабрику, создающую новый
+// пустой экземпляр адаптера, и запускает каждую проверку подтестом.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+// OrderRepositoryFactory создаёт новый пустой репозиторий
+type OrderRepositoryFactory func(t *testing.T) application.OrderRepository
+
+// SuiteOption - необязательная настройка набора проверок
+type SuiteOption func(*suiteConfig)
+
+// suiteConfig - настройки набора проверок
+type suiteConfig struct {
+	wrap func(ctx context.Context) context.Context
+}
+
+// WithContext задаёт контекст обращений к адаптеру: например, с магазином
+// (application.WithTenant) или пользователем (application.WithPrincipal)
+func WithContext(wrap func(ctx context.Context) context.Context) SuiteOption {
+	return func(c *suiteConfig) {
+		c.wrap = wrap
+	}
+}
+
+// context возвращает контекст обращений для теста t
+func (c suiteConfig) context(t *testing.T) context.Context {
+	if c.wrap == nil {
+		return t.Context()
+	}
+	return c.wrap(t.Context())
+}
+
+// RunOrderRepositorySuite проверяет контракт application.OrderRepository
+func RunOrderRepositorySuite(t *testing.T, factory OrderRepositoryFactory, opts ...SuiteOption) {
+	var cfg suiteConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	t.Run("NotFound", func(t *testing.T) { testRepositoryNotFound(t, cfg.context(t), factory(t)) })
+	t.Run("RoundTrip", func(t *testing.T) { testRepositoryRoundTrip(t, cfg.context(t), factory(t)) })
+	t.Run("StatusRoundTrip", func(t *testing.T) { testRepositoryStatusRoundTrip(t, cfg.context(t), factory(t)) })
+	t.Run("Overwrite", func(t *testing.T) { testRepositoryOverwrite(t, cfg.context(t), factory(t)) })
+	t.Run("SaveIsolation", func(t *testing.T) { testRepositorySaveIsolation(t, cfg.context(t), factory(t)) })
+	t.Run("GetIsolation", func(t *testing.T) { testRepositoryGetIsolation(t, cfg.context(t), factory(t)) })
+	t.Run("FindByCustomer", func(t *testing.T) { testRepositoryFindByCustomer(t, cfg.context(t), factory(t)) })
+	t.Run("FindIsolation", func(t *testing.T) { testRepositoryFindIsolation(t, cfg.context(t), factory(t)) })
+	t.Run("ConcurrentAccess", func(t *testing.T) { testRepositoryConcurrentAccess(t, cfg.context(t), factory(t)) })
+	t.Run("Update", func(t *testing.T) { testRepositoryUpdate(t, cfg.context(t), factory(t)) })
+	t.Run("ConcurrentUpdate", func(t *testing.T) { testRepositoryConcurrentUpdate(t, cfg.context(t), factory(t)) })
+}
+
+// newOrder создаёт заказ с одной строкой на amount RUB
+func newOrder(t *testing.T, id string, amount int64) *domain.Order {
+	t.Helper()
+	order := domain.NewOrder(id)
+	addLine(t, order, "product-1", amount)
+	return order
+}
+
+// addLine добавляет в заказ строку с количеством 1
+func addLine(t *testing.T, order *domain.Order, productID string, amount int64) {
+	t.Helper()
+	money, err := domain.NewMoney(amount, "RUB")
+	if err != nil {
+		t.Fatalf("NewMoney: %v", err)
+	}
+	line, err := domain.NewOrderLine(productID, money, 1)
+	if err != nil {
+		t.Fatalf("NewOrderLine: %v", err)
+	}
+	if err := order.AddLine(line); err != nil {
+		t.Fatalf("AddLine: %v", err)
+	}
+}
+
+// save сохраняет заказ, прерывая тест при ошибке
+func save(t *testing.T, ctx context.Context, repo application.OrderRepository, order *domain.Order) {
+	t.Helper()
+	if err := repo.Save(ctx, order); err != nil {
+		t.Fatalf("Save(%s): %v", order.ID(), err)
+	}
+}
+
+// load загружает заказ, прерывая тест при ошибке
+func load(t *testing.T, ctx context.Context, repo application.OrderRepository, id string) *domain.Order {
+	t.Helper()
+	order, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID(%s): %v", id, err)
+	}
+	return order
+}
+
+// assertSameOrder сравнивает состояние двух заказов
+func assertSameOrder(t *testing.T, expected, actual *domain.Order) {
+	t.Helper()
+	want, got := expected.Snapshot(), actual.Snapshot()
+	if got.ID != want.ID || got.Status != want.Status || got.CustomerID != want.CustomerID {
+		t.Errorf("expected %s/%s/%q, got %s/%s/%q", want.ID, want.Status, want.CustomerID, got.ID, got.Status, got.CustomerID)
+	}
+	if got.Delivery != want.Delivery || !got.Shipping.Equals(want.Shipping) {
+		t.Errorf("%s: expected delivery %+v shipping %s, got %+v shipping %s", want.ID, want.Delivery, want.Shipping, got.Delivery, got.Shipping)
+	}
+	if fmt.Sprint(got.Payments) != fmt.Sprint(want.Payments) {
+		t.Errorf("%s: expected payments %v, got %v", want.ID, want.Payments, got.Payments)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("%s: expected created_at %v, got %v", want.ID, want.CreatedAt, got.CreatedAt)
+	}
+	if !got.PayBy.Equal(want.PayBy) {
+		t.Errorf("%s: expected pay_by %v, got %v", want.ID, want.PayBy, got.PayBy)
+	}
+	if len(got.Installments) != len(want.Installments) {
+		t.Fatalf("%s: expected %d installments, got %d", want.ID, len(want.Installments), len(got.Installments))
+	}
+	for i, w := range want.Installments {
+		g := got.Installments[i]
+		if g.Number != w.Number || !g.DueAt.Equal(w.DueAt) || !g.Amount.Equals(w.Amount) || !g.PaidAt.Equal(w.PaidAt) {
+			t.Errorf("%s: installment %d: expected %+v, got %+v", want.ID, i, w, g)
+		}
+	}
+	if len(got.Lines) != len(want.Lines) {
+		t.Fatalf("%s: expected %d lines, got %d", want.ID, len(want.Lines), len(got.Lines))
+	}
+	for i := range want.Lines {
+		w, g := want.Lines[i].Snapshot(), got.Lines[i].Snapshot()
+		if g.ProductID != w.ProductID || g.Name != w.Name || !g.Price.Equals(w.Price) || g.Quantity != w.Quantity ||
+			g.WeightGrams != w.WeightGrams || g.VATRate != w.VATRate {
+			t.Errorf("%s: line %d: expected %+v, got %+v", want.ID, i, w, g)
+		}
+	}
+}
+
+func testRepositoryNotFound(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got %v", err)
+	}
+
+	save(t, ctx, repo, newOrder(t, "order-1", 100))
+	if _, err := repo.GetByID(ctx, "order-2"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound for another id, got %v", err)
+	}
+}
+
+func testRepositoryRoundTrip(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	order := domain.NewOrderForCustomer("order-1", "customer-1")
+	addLine(t, order, "product-1", 10000)
+	addLine(t, order, "product-2", 2550)
+	product, err := domain.NewProduct("product-3", "Catalog product", money(t, 990, "RUB"))
+	if err != nil {
+		t.Fatalf("NewProduct: %v", err)
+	}
+	product.SetWeight(1500)
+	product.SetVATRate(domain.VAT10)
+	line, err := domain.NewOrderLineFromProduct(product, 3)
+	if err != nil {
+		t.Fatalf("NewOrderLineFromProduct: %v", err)
+	}
+	order.AddLine(line)
+	address, err := domain.NewAddress("RU", "MOW", "Moscow", "101000", "Tverskaya 1")
+	if err != nil {
+		t.Fatalf("NewAddress: %v", err)
+	}
+	delivery, _ := domain.NewDelivery(address, domain.DeliveryMethodCourier)
+	order.SetDelivery(delivery)
+	if err := order.QuoteShipping(domain.NewFlatRateShipping(money(t, 500, "RUB"))); err != nil {
+		t.Fatalf("QuoteShipping: %v", err)
+	}
+	save(t, ctx, repo, order)
+
+	assertSameOrder(t, order, load(t, ctx, repo, "order-1"))
+}
+
+func testRepositoryStatusRoundTrip(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	pending := newOrder(t, "pending", 100)
+	paid := newOrder(t, "paid", 100)
+	paid.Pay()
+	refunded := newOrder(t, "refunded", 100)
+	refunded.Pay()
+	refunded.Refund()
+	split := newOrder(t, "split", 100)
+	card, err := domain.NewCardToken("tok_visa_4242")
+	if err != nil {
+		t.Fatalf("NewCardToken: %v", err)
+	}
+	if err := split.PayWith([]domain.PaymentLeg{
+		{Method: card, Amount: money(t, 70, "RUB")},
+		{Method: domain.CashOnDelivery(), Amount: money(t, 30, "RUB")},
+	}, time.Now()); err != nil {
+		t.Fatalf("PayWith: %v", err)
+	}
+	refunding := newOrder(t, "refunding", 100)
+	if err := refunding.PayWith([]domain.PaymentLeg{
+		{Method: card, Amount: money(t, 60, "RUB")},
+		{Amount: money(t, 40, "RUB")},
+	}, time.Now()); err != nil {
+		t.Fatalf("PayWith: %v", err)
+	}
+	if err := refunding.MarkPaymentRefunded(1); err != nil {
+		t.Fatalf("MarkPaymentRefunded: %v", err)
+	}
+	deadline := pending.CreatedAt().Add(time.Hour)
+	pending.SetPaymentDeadline(deadline)
+	expired := newOrder(t, "expired", 100)
+	expired.SetPaymentDeadline(deadline)
+	if err := expired.Expire(deadline); err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+	partial := newOrder(t, "partial", 100)
+	firstDue := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if err := partial.ScheduleInstallments([]time.Time{firstDue, firstDue.AddDate(0, 1, 0), firstDue.AddDate(0, 2, 0)}); err != nil {
+		t.Fatalf("ScheduleInstallments: %v", err)
+	}
+	if _, err := partial.PayInstallment(card, firstDue); err != nil {
+		t.Fatalf("PayInstallment: %v", err)
+	}
+
+	orders := []*domain.Order{pending, paid, refunded, split, refunding, expired, partial}
+	for _, order := range orders {
+		save(t, ctx, repo, order)
+	}
+	for _, order := range orders {
+		assertSameOrder(t, order, load(t, ctx, repo, order.ID()))
+	}
+}
+
+func testRepositoryOverwrite(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	order := newOrder(t, "order-1", 100)
+	save(t, ctx, repo, order)
+
+	addLine(t, order, "product-2", 200)
+	order.Pay()
+	save(t, ctx, repo, order)
+
+	assertSameOrder(t, order, load(t, ctx, repo, "order-1"))
+}
+
+func testRepositorySaveIsolation(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	order := newOrder(t, "order-1", 100)
+	save(t, ctx, repo, order)
+	stored := order.Snapshot()
+
+	// Изменения сохранённого экземпляра не должны попадать в хранилище
+	addLine(t, order, "product-2", 200)
+	order.Pay()
+
+	assertSameOrder(t, domain.RestoreOrder(stored), load(t, ctx, repo, "order-1"))
+}
+
+func testRepositoryGetIsolation(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	save(t, ctx, repo, newOrder(t, "order-1", 100))
+
+	loaded := load(t, ctx, repo, "order-1")
+	addLine(t, loaded, "product-2", 200)
+	loaded.Pay()
+
+	reloaded := load(t, ctx, repo, "order-1")
+	if reloaded.Status() != domain.OrderStatusPending || len(reloaded.Lines()) != 1 {
+		t.Errorf("expected stored order to stay PENDING with 1 line, got %s with %d lines",
+			reloaded.Status(), len(reloaded.Lines()))
+	}
+}
+
+func testRepositoryFindByCustomer(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	for _, owner := range [][2]string{{"order-1", "alice"}, {"order-2", "bob"}, {"order-3", "alice"}, {"order-4", ""}} {
+		order := domain.NewOrderForCustomer(owner[0], owner[1])
+		addLine(t, order, "product-1", 100)
+		save(t, ctx, repo, order)
+	}
+
+	page, err := repo.Find(ctx, application.OrderQuery{CustomerID: "alice"})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	var ids []string
+	for _, order := range page.Orders {
+		ids = append(ids, order.ID())
+	}
+	if fmt.Sprint(ids) != "[order-1 order-3]" {
+		t.Errorf("expected [order-1 order-3], got %v", ids)
+	}
+}
+
+func testRepositoryFindIsolation(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	save(t, ctx, repo, newOrder(t, "order-1", 100))
+	save(t, ctx, repo, newOrder(t, "order-2", 200))
+
+	page, err := repo.Find(ctx, application.OrderQuery{})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(page.Orders) != 2 {
+		t.Fatalf("expected 2 orders, got %d", len(page.Orders))
+	}
+	for _, order := range page.Orders {
+		order.Pay()
+	}
+
+	for _, id := range []string{"order-1", "order-2"} {
+		if status := load(t, ctx, repo, id).Status(); status != domain.OrderStatusPending {
+			t.Errorf("%s: expected PENDING after modifying Find result, got %s", id, status)
+		}
+	}
+}
+
+func testRepositoryConcurrentAccess(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	const workers = 8
+	const ordersPerWorker = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*ordersPerWorker)
+	for w := range workers {
+		wg.Go(func() {
+			for i := range ordersPerWorker {
+				id := fmt.Sprintf("order-%d-%d", w, i)
+				order := domain.NewOrder(id)
+				money, _ := domain.NewMoney(int64(100*(i+1)), "RUB")
+				line, _ := domain.NewOrderLine("product-1", money, 1)
+				order.AddLine(line)
+				if err := repo.Save(ctx, order); err != nil {
+					errs <- err
+					continue
+				}
+				loaded, err := repo.GetByID(ctx, id)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				loaded.Pay()
+				if err := repo.Save(ctx, loaded); err != nil {
+					errs <- err
+				}
+				if _, err := repo.Find(ctx, application.OrderQuery{Limit: 5}); err != nil {
+					errs <- err
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent operation failed: %v", err)
+	}
+
+	page, err := repo.Find(ctx, application.OrderQuery{
+		Statuses: []domain.OrderStatus{domain.OrderStatusPaid},
+		Limit:    application.MaxOrderQueryLimit,
+	})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(page.Orders) != workers*ordersPerWorker {
+		t.Errorf("expected %d paid orders, got %d", workers*ordersPerWorker, len(page.Orders))
+	}
+}
+
+func testRepositoryUpdate(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	err := repo.Update(ctx, "missing", func(*domain.Order) error { return nil })
+	if !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got: %v", err)
+	}
+
+	save(t, ctx, repo, newOrder(t, "order-1", 1000))
+	if err := repo.Update(ctx, "order-1", func(order *domain.Order) error {
+		return order.Pay()
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if status := load(t, ctx, repo, "order-1").Status(); status != domain.OrderStatusPaid {
+		t.Errorf("expected PAID after Update, got %s", status)
+	}
+
+	// Ошибка fn отменяет изменение
+	failure := errors.New("rejected")
+	err = repo.Update(ctx, "order-1", func(order *domain.Order) error {
+		order.Refund()
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected fn error, got: %v", err)
+	}
+	if status := load(t, ctx, repo, "order-1").Status(); status != domain.OrderStatusPaid {
+		t.Errorf("expected PAID after failed Update, got %s", status)
+	}
+}
+
+func testRepositoryConcurrentUpdate(t *testing.T, ctx context.Context, repo application.OrderRepository) {
+	const workers = 8
+	save(t, ctx, repo, domain.NewOrder("order-1"))
+
+	// Каждый Update добавляет строку; потерянное обновление уменьшит их число
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := range workers {
+		wg.Go(func() {
+			errs <- repo.Update(ctx, "order-1", func(order *domain.Order) error {
+				money, _ := domain.NewMoney(100, "RUB")
+				line, _ := domain.NewOrderLine(fmt.Sprintf("product-%d", w), money, 1)
+				return order.AddLine(line)
+			})
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent Update failed: %v", err)
+		}
+	}
+	if lines := len(load(t, ctx, repo, "order-1").Lines()); lines != workers {
+		t.Errorf("expected %d lines after concurrent updates, got %d", workers, lines)
+	}
+}
@@ -0,0 +1,163 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"sync"
+	"testing"
+)
+
+// PaymentGatewayHarness - шлюз и средства наблюдения за ним
+type PaymentGatewayHarness struct {
+	Gateway application.PaymentGateway
+	// Charged возвращает успешные списания по заказу
+	Charged func(orderID string) []domain.Money
+	// Refunded возвращает успешные возвраты по заказу
+	Refunded func(orderID string) []domain.Money
+	// Decline переводит шлюз в режим отказов; nil, если адаптер этого не умеет
+	Decline func()
+}
+
+// PaymentGatewayFactory создаёт новый шлюз без операций
+type PaymentGatewayFactory func(t *testing.T) PaymentGatewayHarness
+
+// RunPaymentGatewaySuite проверяет контракт application.PaymentGateway
+func RunPaymentGatewaySuite(t *testing.T, factory PaymentGatewayFactory) {
+	t.Run("Charge", func(t *testing.T) { testGatewayCharge(t, factory(t)) })
+	t.Run("Refund", func(t *testing.T) { testGatewayRefund(t, factory(t)) })
+	t.Run("Decline", func(t *testing.T) { testGatewayDecline(t, factory(t)) })
+	t.Run("CanceledContext", func(t *testing.T) { testGatewayCanceledContext(t, factory(t)) })
+	t.Run("SplitCharges", func(t *testing.T) { testGatewaySplitCharges(t, factory(t)) })
+	t.Run("ConcurrentCharges", func(t *testing.T) { testGatewayConcurrentCharges(t, factory(t)) })
+}
+
+// money создаёт сумму, прерывая тест при ошибке
+func money(t *testing.T, amount int64, currency string) domain.Money {
+	t.Helper()
+	m, err := domain.NewMoney(amount, currency)
+	if err != nil {
+		t.Fatalf("NewMoney: %v", err)
+	}
+	return m
+}
+
+// assertAmounts сравнивает списки сумм
+func assertAmounts(t *testing.T, what string, expected, actual []domain.Money) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d %s, got %d", len(expected), what, len(actual))
+	}
+	for i := range expected {
+		if !actual[i].Equals(expected[i]) {
+			t.Errorf("%s %d: expected %s, got %s", what, i, expected[i], actual[i])
+		}
+	}
+}
+
+func testGatewayCharge(t *testing.T, h PaymentGatewayHarness) {
+	rub, usd := money(t, 12345, "RUB"), money(t, 500, "USD")
+	if err := h.Gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub); err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if err := h.Gateway.Charge(t.Context(), "order-2", domain.PaymentMethod{}, usd); err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+
+	assertAmounts(t, "charges", []domain.Money{rub}, h.Charged("order-1"))
+	assertAmounts(t, "charges", []domain.Money{usd}, h.Charged("order-2"))
+	assertAmounts(t, "refunds", nil, h.Refunded("order-1"))
+}
+
+func testGatewayRefund(t *testing.T, h PaymentGatewayHarness) {
+	amount := money(t, 10000, "RUB")
+	if err := h.Gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, amount); err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if err := h.Gateway.Refund(t.Context(), "order-1", domain.PaymentMethod{}, amount); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	assertAmounts(t, "refunds", []domain.Money{amount}, h.Refunded("order-1"))
+	assertAmounts(t, "charges", []domain.Money{amount}, h.Charged("order-1"))
+}
+
+func testGatewaySplitCharges(t *testing.T, h PaymentGatewayHarness) {
+	card, err := domain.NewCardToken("tok_visa_4242")
+	if err != nil {
+		t.Fatalf("NewCardToken: %v", err)
+	}
+	wallet, err := domain.NewWallet("wallet-1")
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	// Заказ оплачивается несколькими списаниями разными способами
+	first, second := money(t, 7000, "RUB"), money(t, 3000, "RUB")
+	if err := h.Gateway.Charge(t.Context(), "order-1", card, first); err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if err := h.Gateway.Charge(t.Context(), "order-1", wallet, second); err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if err := h.Gateway.Refund(t.Context(), "order-1", wallet, second); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	assertAmounts(t, "charges", []domain.Money{first, second}, h.Charged("order-1"))
+	assertAmounts(t, "refunds", []domain.Money{second}, h.Refunded("order-1"))
+}
+
+func testGatewayDecline(t *testing.T, h PaymentGatewayHarness) {
+	if h.Decline == nil {
+		t.Skip("adapter cannot be configured to decline")
+	}
+	h.Decline()
+
+	amount := money(t, 10000, "RUB")
+	if err := h.Gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, amount); err == nil {
+		t.Error("expected declined charge to fail")
+	}
+	if err := h.Gateway.Refund(t.Context(), "order-1", domain.PaymentMethod{}, amount); err == nil {
+		t.Error("expected declined refund to fail")
+	}
+	assertAmounts(t, "charges", nil, h.Charged("order-1"))
+	assertAmounts(t, "refunds", nil, h.Refunded("order-1"))
+}
+
+func testGatewayCanceledContext(t *testing.T, h PaymentGatewayHarness) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	amount := money(t, 10000, "RUB")
+	if err := h.Gateway.Charge(ctx, "order-1", domain.PaymentMethod{}, amount); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from Charge, got %v", err)
+	}
+	if err := h.Gateway.Refund(ctx, "order-1", domain.PaymentMethod{}, amount); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from Refund, got %v", err)
+	}
+	assertAmounts(t, "charges", nil, h.Charged("order-1"))
+	assertAmounts(t, "refunds", nil, h.Refunded("order-1"))
+}
+
+func testGatewayConcurrentCharges(t *testing.T, h PaymentGatewayHarness) {
+	const orders = 50
+
+	var wg sync.WaitGroup
+	for i := range orders {
+		wg.Go(func() {
+			amount, _ := domain.NewMoney(int64(100*(i+1)), "RUB")
+			if err := h.Gateway.Charge(t.Context(), fmt.Sprintf("order-%d", i), domain.PaymentMethod{}, amount); err != nil {
+				t.Errorf("Charge: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	for i := range orders {
+		expected := money(t, int64(100*(i+1)), "RUB")
+		assertAmounts(t, "charges", []domain.Money{expected}, h.Charged(fmt.Sprintf("order-%d", i)))
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/audit"
+	"lab7/infrastructure/authz"
+	"lab7/infrastructure/logging"
+	"lab7/infrastructure/tracing"
+	"lab7/tests/conformance"
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+// TestConformance_InMemoryOrderRepository проверяет контракт in-memory репозитория
+func TestConformance_InMemoryOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		return infrastructure.NewInMemoryOrderRepository()
+	})
+}
+
+// TestConformance_FileOrderRepository проверяет контракт файлового репозитория
+func TestConformance_FileOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		repo, err := infrastructure.NewFileOrderRepository(filepath.Join(t.TempDir(), "orders.json"))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return repo
+	})
+}
+
+// TestConformance_DecoratedOrderRepository проверяет, что декораторы не нарушают контракт
+func TestConformance_DecoratedOrderRepository(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tracer := tracing.NewTracer(tracing.NewInMemoryRecorder())
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		return tracing.NewOrderRepository(logging.NewOrderRepository(infrastructure.NewInMemoryOrderRepository(), logger), tracer)
+	})
+}
+
+// TestConformance_AuditedOrderRepository проверяет, что журнал изменений не нарушает контракт
+func TestConformance_AuditedOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		log, err := audit.NewLog(t.Context(), audit.NewMemoryStore())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return audit.NewOrderRepository(infrastructure.NewInMemoryOrderRepository(), log)
+	})
+}
+
+// TestConformance_CachedOrderRepository проверяет, что кеш не нарушает контракт
+func TestConformance_CachedOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		return infrastructure.NewCachedOrderRepository(infrastructure.NewInMemoryOrderRepository(), 4)
+	})
+}
+
+// TestConformance_TenantOrderRepository проверяет контракт репозитория магазинов
+// для магазина из контекста
+func TestConformance_TenantOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		return infrastructure.NewInMemoryTenantOrderRepository()
+	}, conformance.WithContext(func(ctx context.Context) context.Context {
+		return application.WithTenant(ctx, "shop-1")
+	}))
+}
+
+// TestConformance_AuthzOrderRepository проверяет, что проверка прав не нарушает
+// контракт для пользователя, которому разрешено всё
+func TestConformance_AuthzOrderRepository(t *testing.T) {
+	conformance.RunOrderRepositorySuite(t, func(t *testing.T) application.OrderRepository {
+		return authz.NewOrderRepository(infrastructure.NewInMemoryOrderRepository(), application.DefaultPolicy())
+	}, conformance.WithContext(func(ctx context.Context) context.Context {
+		return application.WithPrincipal(ctx, admin)
+	}))
+}
+
+// TestConformance_FakePaymentGateway проверяет контракт фейкового шлюза
+func TestConformance_FakePaymentGateway(t *testing.T) {
+	conformance.RunPaymentGatewaySuite(t, func(t *testing.T) conformance.PaymentGatewayHarness {
+		gateway := infrastructure.NewFakePaymentGateway()
+		return conformance.PaymentGatewayHarness{
+			Gateway:  gateway,
+			Charged:  func(orderID string) []domain.Money { return amountsFor(gateway.GetPayments(), orderID) },
+			Refunded: func(orderID string) []domain.Money { return amountsFor(gateway.GetRefunds(), orderID) },
+			Decline:  func() { gateway.SetShouldFail(true, "declined") },
+		}
+	})
+}
+
+// amountsFor выбирает суммы операций по заказу
+func amountsFor(records []infrastructure.PaymentRecord, orderID string) []domain.Money {
+	var amounts []domain.Money
+	for _, record := range records {
+		if record.OrderID == orderID {
+			amounts = append(amounts, record.Amount)
+		}
+	}
+	return amounts
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"path/filepath"
+	"testing"
+)
+
+// setupCustomerEnvironment создаёт use-case оплаты с проверкой клиентов
+func setupCustomerEnvironment(t *testing.T) (*infrastructure.InMemoryOrderRepository, *infrastructure.InMemoryCustomerRepository, *infrastructure.FakePaymentGateway, *application.PayOrderUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryOrderRepository()
+	customers := infrastructure.NewInMemoryCustomerRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	useCase := application.NewPayOrderUseCase(repo, gateway, application.WithCustomers(customers))
+
+	contact, err := domain.NewContactInfo("Alice", "alice@example.com", "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	customer, _ := domain.NewCustomer("alice", contact)
+	customers.Save(t.Context(), customer)
+	return repo, customers, gateway, useCase
+}
+
+// saveCustomerOrder сохраняет заказ клиента на 100.00 RUB
+func saveCustomerOrder(t *testing.T, repo *infrastructure.InMemoryOrderRepository, id, customerID string) {
+	t.Helper()
+	order := domain.NewOrderForCustomer(id, customerID)
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 1)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+}
+
+// TestCustomer_ContactValidation проверяет правила контактных данных
+func TestCustomer_ContactValidation(t *testing.T) {
+	tests := []struct {
+		name, email, phone string
+		valid              bool
+	}{
+		{"Alice", "alice@example.com", "", true},
+		{"Bob", "", "+7 900 000-00-00", true},
+		{"", "alice@example.com", "", false},
+		{"Alice", "", "", false},
+		{"Alice", "not-an-email", "", false},
+		{"Alice", "Alice <alice@example.com>", "", false},
+	}
+	for _, tt := range tests {
+		_, err := domain.NewContactInfo(tt.name, tt.email, tt.phone)
+		if tt.valid && err != nil {
+			t.Errorf("%q/%q/%q: expected no error, got: %v", tt.name, tt.email, tt.phone, err)
+		}
+		if !tt.valid && !errors.Is(err, domain.ErrInvalidContact) {
+			t.Errorf("%q/%q/%q: expected ErrInvalidContact, got: %v", tt.name, tt.email, tt.phone, err)
+		}
+	}
+
+	if _, err := domain.NewCustomer("", domain.ContactInfo{}); !errors.Is(err, domain.ErrEmptyCustomerID) {
+		t.Errorf("expected ErrEmptyCustomerID, got: %v", err)
+	}
+}
+
+// TestPayOrder_BlockedCustomer проверяет отказ в оплате заблокированному клиенту
+func TestPayOrder_BlockedCustomer(t *testing.T) {
+	repo, customers, gateway, useCase := setupCustomerEnvironment(t)
+	saveCustomerOrder(t, repo, "order-1", "alice")
+
+	customer, _ := customers.GetByID(t.Context(), "alice")
+	customer.Block()
+	customers.Save(t.Context(), customer)
+
+	_, err := useCase.Execute(t.Context(), "order-1")
+	if !errors.Is(err, domain.ErrCustomerBlocked) {
+		t.Fatalf("expected ErrCustomerBlocked, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeCustomerBlocked {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeCustomerBlocked, application.ErrorCode(err))
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+
+	// После разблокировки оплата проходит
+	customer.Activate()
+	customers.Save(t.Context(), customer)
+	if _, err := useCase.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error after activation, got: %v", err)
+	}
+}
+
+// TestPayOrder_UnknownCustomer проверяет оплату заказа несуществующего клиента
+func TestPayOrder_UnknownCustomer(t *testing.T) {
+	repo, _, _, useCase := setupCustomerEnvironment(t)
+	saveCustomerOrder(t, repo, "order-1", "mallory")
+	saveCustomerOrder(t, repo, "order-2", "")
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); !errors.Is(err, application.ErrCustomerNotFound) {
+		t.Errorf("expected ErrCustomerNotFound, got: %v", err)
+	}
+	// Заказы без владельца оплачиваются как прежде
+	if _, err := useCase.Execute(t.Context(), "order-2"); err != nil {
+		t.Errorf("expected no error for order without customer, got: %v", err)
+	}
+}
+
+// TestCreateOrder_RequiresExistingCustomer проверяет привязку заказа к клиенту
+func TestCreateOrder_RequiresExistingCustomer(t *testing.T) {
+	repo, customers, _, _ := setupCustomerEnvironment(t)
+	createUseCase := application.NewCreateOrderUseCase(repo, application.RequireCustomers(customers))
+
+	view, err := createUseCase.Execute(t.Context(), application.CreateOrderCommand{OrderID: "order-1", CustomerID: "alice"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if view.CustomerID != "alice" {
+		t.Errorf("expected customer alice, got %q", view.CustomerID)
+	}
+
+	_, err = createUseCase.Execute(t.Context(), application.CreateOrderCommand{OrderID: "order-2", CustomerID: "mallory"})
+	if !errors.Is(err, application.ErrCustomerNotFound) {
+		t.Errorf("expected ErrCustomerNotFound, got: %v", err)
+	}
+	if _, err := repo.GetByID(t.Context(), "order-2"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected order-2 not to be created, got: %v", err)
+	}
+}
+
+// TestListOrders_ByCustomer проверяет выборку заказов клиента, в том числе после перезагрузки файла
+func TestListOrders_ByCustomer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	fileRepo, _ := infrastructure.NewFileOrderRepository(path)
+	createUseCase := application.NewCreateOrderUseCase(fileRepo)
+	for _, cmd := range []application.CreateOrderCommand{
+		{OrderID: "order-1", CustomerID: "alice"},
+		{OrderID: "order-2", CustomerID: "bob"},
+		{OrderID: "order-3", CustomerID: "alice"},
+	} {
+		if _, err := createUseCase.Execute(t.Context(), cmd); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+
+	reopened, _ := infrastructure.NewFileOrderRepository(path)
+	result, err := application.NewListOrdersUseCase(reopened).Execute(t.Context(), application.ListOrdersRequest{CustomerID: "alice"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Orders) != 2 || result.Orders[0].ID != "order-1" || result.Orders[1].ID != "order-3" {
+		t.Errorf("expected order-1 and order-3, got %+v", result.Orders)
+	}
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+	"time"
+)
+
+// rub создаёт сумму в рублях
+func rub(amount int64) domain.Money {
+	money, _ := domain.NewMoney(amount, "RUB")
+	return money
+}
+
+// chargeOutcomes выполняет списания и возвращает их ошибки
+func chargeOutcomes(t *testing.T, gateway *infrastructure.FakePaymentGateway, n int) []error {
+	t.Helper()
+	outcomes := make([]error, n)
+	for i := range outcomes {
+		outcomes[i] = gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub(100))
+	}
+	return outcomes
+}
+
+// TestFakeGateway_FailNthCall проверяет сбой только на N-м вызове
+func TestFakeGateway_FailNthCall(t *testing.T) {
+	gateway := infrastructure.NewFakePaymentGateway()
+	gateway.SetScenarios(infrastructure.FaultScenario{
+		Name: "third charge", Operation: infrastructure.GatewayOperationCharge, OnCall: 3, Fault: infrastructure.FaultTransient,
+	})
+
+	outcomes := chargeOutcomes(t, gateway, 4)
+	for i, err := range outcomes {
+		if i == 2 {
+			if !errors.Is(err, application.ErrGatewayUnavailable) {
+				t.Errorf("call 3: expected transient error, got %v", err)
+			}
+		} else if err != nil {
+			t.Errorf("call %d: expected no error, got %v", i+1, err)
+		}
+	}
+	if len(gateway.GetPayments()) != 3 {
+		t.Errorf("expected 3 payments, got %d", len(gateway.GetPayments()))
+	}
+
+	// Возврат считается отдельно и под сценарий не попадает
+	if err := gateway.Refund(t.Context(), "order-1", domain.PaymentMethod{}, rub(100)); err != nil {
+		t.Errorf("expected refund to succeed, got %v", err)
+	}
+}
+
+// TestFakeGateway_OrderAndAmountFilters проверяет сбои по заказам и порогу суммы
+func TestFakeGateway_OrderAndAmountFilters(t *testing.T) {
+	threshold := int64(50000)
+	gateway := infrastructure.NewFakePaymentGateway()
+	gateway.SetScenarios(
+		infrastructure.FaultScenario{Name: "blocked", OrderIDs: []string{"order-2"}, Fault: infrastructure.FaultPermanent, Reason: "card blocked"},
+		infrastructure.FaultScenario{Name: "limit", AmountAbove: &threshold, Fault: infrastructure.FaultPermanent, Reason: "limit exceeded"},
+	)
+
+	tests := []struct {
+		orderID string
+		amount  int64
+		reason  string
+	}{
+		{"order-1", 10000, ""},
+		{"order-2", 10000, "blocked"},
+		{"order-3", 50000, ""},
+		{"order-3", 50001, "limit"},
+	}
+	for _, tt := range tests {
+		err := gateway.Charge(t.Context(), tt.orderID, domain.PaymentMethod{}, rub(tt.amount))
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%s/%d: expected no error, got %v", tt.orderID, tt.amount, err)
+			}
+			continue
+		}
+		if !errors.Is(err, application.ErrPaymentDeclined) {
+			t.Errorf("%s/%d: expected permanent error, got %v", tt.orderID, tt.amount, err)
+		}
+	}
+
+	calls := gateway.GetCalls()
+	if calls[1].Scenario != "blocked" || calls[3].Scenario != "limit" {
+		t.Errorf("unexpected scenarios in history: %q, %q", calls[1].Scenario, calls[3].Scenario)
+	}
+}
+
+// TestFakeGateway_TransientThenSuccess проверяет ограничение числа срабатываний
+// и нумерацию попыток в истории
+func TestFakeGateway_TransientThenSuccess(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	gateway := infrastructure.NewFakePaymentGateway()
+	gateway.SetClock(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	gateway.SetScenarios(infrastructure.FaultScenario{Name: "flaky", Times: 2, Fault: infrastructure.FaultTransient})
+
+	outcomes := chargeOutcomes(t, gateway, 3)
+	gateway.Charge(t.Context(), "order-2", domain.PaymentMethod{}, rub(100))
+
+	if outcomes[0] == nil || outcomes[1] == nil || outcomes[2] != nil {
+		t.Fatalf("expected two failures then success, got %v", outcomes)
+	}
+
+	calls := gateway.GetCalls()
+	expected := []struct {
+		orderID string
+		call    int
+		attempt int
+	}{
+		{"order-1", 1, 1}, {"order-1", 2, 2}, {"order-1", 3, 3}, {"order-2", 4, 1},
+	}
+	for i, e := range expected {
+		c := calls[i]
+		if c.OrderID != e.orderID || c.Call != e.call || c.Attempt != e.attempt {
+			t.Errorf("call %d: expected %+v, got %s/%d/%d", i, e, c.OrderID, c.Call, c.Attempt)
+		}
+		if !c.At.Equal(start.Add(time.Duration(i+1) * time.Second)) {
+			t.Errorf("call %d: unexpected timestamp %v", i, c.At)
+		}
+	}
+}
+
+// TestFakeGateway_SeededRandomFailures проверяет воспроизводимость случайных сбоев
+func TestFakeGateway_SeededRandomFailures(t *testing.T) {
+	run := func(seed uint64) []bool {
+		gateway := infrastructure.NewFakePaymentGateway()
+		gateway.SetSeed(seed)
+		gateway.SetScenarios(infrastructure.FaultScenario{Name: "random", Rate: 0.3, Fault: infrastructure.FaultTransient})
+		failed := make([]bool, 200)
+		for i, err := range chargeOutcomes(t, gateway, len(failed)) {
+			failed[i] = err != nil
+		}
+		return failed
+	}
+
+	first, second := run(42), run(42)
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("call %d: outcomes differ for the same seed", i)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures < 40 || failures > 80 {
+		t.Errorf("expected about 60 failures out of 200, got %d", failures)
+	}
+}
+
+// TestFakeGateway_Latency проверяет задержку и её прерывание отменой контекста
+func TestFakeGateway_Latency(t *testing.T) {
+	gateway := infrastructure.NewFakePaymentGateway()
+	gateway.SetScenarios(infrastructure.FaultScenario{Name: "slow", Latency: 20 * time.Millisecond})
+
+	begin := time.Now()
+	if err := gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub(100)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 20*time.Millisecond {
+		t.Errorf("expected latency of at least 20ms, got %v", elapsed)
+	}
+
+	gateway.SetScenarios(infrastructure.FaultScenario{Name: "hang", Latency: time.Minute})
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := gateway.Charge(ctx, "order-1", domain.PaymentMethod{}, rub(100)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if len(gateway.GetPayments()) != 1 {
+		t.Errorf("expected canceled charge not to be recorded, got %d payments", len(gateway.GetPayments()))
+	}
+}
+
+// TestPayOrder_FaultErrorCodes проверяет, что код ошибки оплаты различает
+// временный сбой и окончательный отказ шлюза
+func TestPayOrder_FaultErrorCodes(t *testing.T) {
+	for fault, want := range map[infrastructure.FaultKind]string{
+		infrastructure.FaultTransient: application.ErrorCodeGatewayDown,
+		infrastructure.FaultPermanent: application.ErrorCodeDeclined,
+	} {
+		repo, gateway, useCase := setupTestEnvironment()
+		saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+		gateway.SetScenarios(infrastructure.FaultScenario{Name: "fault", Fault: fault})
+
+		_, err := useCase.Execute(t.Context(), "order-1")
+		if !errors.Is(err, application.ErrPaymentFailed) {
+			t.Fatalf("expected ErrPaymentFailed, got: %v", err)
+		}
+		if code := application.ErrorCode(err); code != want {
+			t.Errorf("expected error code %s, got %s", want, code)
+		}
+	}
+}
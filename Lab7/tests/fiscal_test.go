@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"lab7/domain"
+	"lab7/infrastructure/fiscal"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fiscalCompany - реквизиты продавца в тестах фискальных документов
+var fiscalCompany = fiscal.Company{
+	INN:            "7707083893",
+	SNO:            "osn",
+	Email:          "shop@example.com",
+	PaymentAddress: "https://shop.example.com",
+}
+
+// newFiscalOrder создаёт заказ с товарами по разным ставкам НДС и доставкой
+func newFiscalOrder(t *testing.T) *domain.Order {
+	t.Helper()
+	order := domain.NewOrder("order-1")
+	for _, p := range []struct {
+		id, name string
+		price    int64
+		quantity int
+		vat      domain.VATRate
+	}{
+		{"book", `Книга "Go"`, 33000, 1, domain.VAT10},
+		{"lamp", "Лампа <LED>", 12000, 2, domain.VAT20},
+	} {
+		product, _ := domain.NewProduct(p.id, p.name, rub(p.price))
+		product.SetVATRate(p.vat)
+		line, _ := domain.NewOrderLineFromProduct(product, p.quantity)
+		if err := order.AddLine(line); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	address, _ := domain.NewAddress("RU", "", "Moscow", "", "Tverskaya 1")
+	delivery, _ := domain.NewDelivery(address, domain.DeliveryMethodCourier)
+	order.SetDelivery(delivery)
+	if err := order.QuoteShipping(domain.NewFlatRateShipping(rub(30000))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return order
+}
+
+// TestFiscalDocument_Sell проверяет документ прихода и его детерминированный JSON
+func TestFiscalDocument_Sell(t *testing.T) {
+	order := newFiscalOrder(t)
+	card, _ := domain.NewCardToken("tok_visa_4242")
+	gift, _ := domain.NewGiftCardPayment("gift-1")
+	err := order.PayWith([]domain.PaymentLeg{
+		{Method: gift, Amount: rub(7000)},
+		{Method: card, Amount: rub(50000)},
+		{Method: domain.CashOnDelivery(), Amount: rub(30000)},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	now := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	generator := fiscal.NewGenerator(fiscalCompany,
+		fiscal.WithClock(func() time.Time { return now }),
+		fiscal.ShippingVAT(domain.VAT20))
+	doc, err := generator.Generate(order, fiscal.Client{Email: "buyer@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	data, err := fiscal.Marshal(doc)
+	if err != nil {
+		t.Fatalf("expected valid document, got: %v", err)
+	}
+	want := `{"external_id":"order-1","operation":"sell","timestamp":"01.06.2024 12:30:00","receipt":{` +
+		`"client":{"email":"buyer@example.com"},` +
+		`"company":{"inn":"7707083893","sno":"osn","email":"shop@example.com","payment_address":"https://shop.example.com"},` +
+		`"items":[` +
+		`{"name":"Книга \"Go\"","price":330.00,"quantity":1,"sum":330.00,"payment_method":"full_payment","payment_object":"commodity","vat":{"type":"vat10","sum":30.00}},` +
+		`{"name":"Лампа <LED>","price":120.00,"quantity":2,"sum":240.00,"payment_method":"full_payment","payment_object":"commodity","vat":{"type":"vat20","sum":40.00}},` +
+		`{"name":"Доставка","price":300.00,"quantity":1,"sum":300.00,"payment_method":"full_payment","payment_object":"service","vat":{"type":"vat20","sum":50.00}}],` +
+		`"payments":[{"type":0,"sum":300.00},{"type":1,"sum":500.00},{"type":2,"sum":70.00}],` +
+		`"vats":[{"type":"vat10","sum":30.00},{"type":"vat20","sum":90.00}],` +
+		`"total":870.00}}`
+	if string(data) != want {
+		t.Errorf("unexpected document:\n got: %s\nwant: %s", data, want)
+	}
+
+	// Повторная сериализация даёт те же байты
+	again, _ := fiscal.Marshal(doc)
+	if string(again) != string(data) {
+		t.Error("expected deterministic serialization")
+	}
+}
+
+// TestFiscalDocument_SellRefund проверяет документ возврата прихода
+func TestFiscalDocument_SellRefund(t *testing.T) {
+	order := newFiscalOrder(t)
+	order.Pay()
+	if _, err := order.Refund(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	doc, err := fiscal.NewGenerator(fiscalCompany).Generate(order, fiscal.Client{Phone: "+79991234567"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if doc.Operation != fiscal.OperationSellRefund {
+		t.Errorf("expected sell_refund, got %s", doc.Operation)
+	}
+	if len(doc.Receipt.Payments) != 1 || doc.Receipt.Payments[0] != (fiscal.Payment{Type: fiscal.PaymentTypeElectronic, Sum: 87000}) {
+		t.Errorf("expected single electronic payment, got %+v", doc.Receipt.Payments)
+	}
+	if _, err := fiscal.Marshal(doc); err != nil {
+		t.Errorf("expected valid document, got: %v", err)
+	}
+}
+
+// TestFiscalDocument_PartiallyPaidRefund проверяет возврат заказа, по
+// рассрочке которого внесена только часть платежей
+func TestFiscalDocument_PartiallyPaidRefund(t *testing.T) {
+	order := newFiscalOrder(t)
+	start := time.Now().Add(24 * time.Hour)
+	if err := order.ScheduleInstallments([]time.Time{start, start.Add(24 * time.Hour), start.Add(48 * time.Hour)}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	card, _ := domain.NewCardToken("tok_visa_4242")
+	if _, err := order.PayInstallment(card, time.Now()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	paid, err := order.Refund()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	doc, err := fiscal.NewGenerator(fiscalCompany).Generate(order, fiscal.Client{Email: "buyer@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := fiscal.Marshal(doc); err != nil {
+		t.Fatalf("expected valid document, got: %v", err)
+	}
+	for _, item := range doc.Receipt.Items {
+		if item.PaymentMethod != fiscal.PaymentMethodPartialPayment {
+			t.Errorf("%s: expected partial_payment, got %s", item.Name, item.PaymentMethod)
+		}
+	}
+	want := []fiscal.Payment{
+		{Type: fiscal.PaymentTypeElectronic, Sum: fiscal.Amount(paid.Amount())},
+		{Type: fiscal.PaymentTypeCredit, Sum: 87000 - fiscal.Amount(paid.Amount())},
+	}
+	if len(doc.Receipt.Payments) != 2 || doc.Receipt.Payments[0] != want[0] || doc.Receipt.Payments[1] != want[1] {
+		t.Errorf("expected paid amount and credit for the rest %+v, got %+v", want, doc.Receipt.Payments)
+	}
+
+	// Полностью внесённая рассрочка - полный расчёт без постоплаты
+	order = newFiscalOrder(t)
+	order.ScheduleInstallments([]time.Time{start, start.Add(24 * time.Hour)})
+	for range 2 {
+		order.PayInstallment(card, time.Now())
+	}
+	doc, err = fiscal.NewGenerator(fiscalCompany).Generate(order, fiscal.Client{Email: "buyer@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if doc.Receipt.Items[0].PaymentMethod != fiscal.PaymentMethodFullPayment || len(doc.Receipt.Payments) != 1 {
+		t.Errorf("expected full payment, got %+v", doc.Receipt)
+	}
+}
+
+// TestFiscalDocument_Rejections проверяет отказ для неоплаченных и не рублёвых заказов
+func TestFiscalDocument_Rejections(t *testing.T) {
+	generator := fiscal.NewGenerator(fiscalCompany)
+	client := fiscal.Client{Email: "buyer@example.com"}
+
+	if _, err := generator.Generate(newFiscalOrder(t), client); !errors.Is(err, domain.ErrOrderNotPaid) {
+		t.Errorf("expected ErrOrderNotPaid, got: %v", err)
+	}
+
+	order := domain.NewOrder("order-usd")
+	price, _ := domain.NewMoney(1000, "USD")
+	line, _ := domain.NewOrderLine("product-1", price, 1)
+	order.AddLine(line)
+	order.Pay()
+	if _, err := generator.Generate(order, client); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got: %v", err)
+	}
+}
+
+// TestFiscalDocument_SchemaViolations проверяет, что схема и сверка сумм
+// находят испорченные документы
+func TestFiscalDocument_SchemaViolations(t *testing.T) {
+	order := newFiscalOrder(t)
+	order.Pay()
+	valid, err := fiscal.NewGenerator(fiscalCompany).Generate(order, fiscal.Client{Email: "buyer@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(doc *fiscal.Document)
+		want   string
+	}{
+		{"bad INN", func(doc *fiscal.Document) { doc.Receipt.Company.INN = "123" }, "$.receipt.company.inn"},
+		{"no client contact", func(doc *fiscal.Document) { doc.Receipt.Client = fiscal.Client{} }, "$.receipt.client"},
+		{"unknown tax system", func(doc *fiscal.Document) { doc.Receipt.Company.SNO = "flat" }, "$.receipt.company.sno"},
+		{"empty item name", func(doc *fiscal.Document) { doc.Receipt.Items[0].Name = "" }, "$.receipt.items[0].name"},
+		{"unknown VAT type", func(doc *fiscal.Document) { doc.Receipt.Items[1].VAT.Type = "vat18" }, "$.receipt.items[1].vat.type"},
+		{"total mismatch", func(doc *fiscal.Document) { doc.Receipt.Total++ }, "receipt.total"},
+		{"no items", func(doc *fiscal.Document) { doc.Receipt.Items = []fiscal.Item{} }, "$.receipt.items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid
+			doc.Receipt.Items = append([]fiscal.Item(nil), valid.Receipt.Items...)
+			tt.modify(&doc)
+			_, err := fiscal.Marshal(doc)
+			if !errors.Is(err, fiscal.ErrInvalidDocument) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected ErrInvalidDocument at %s, got: %v", tt.want, err)
+			}
+		})
+	}
+
+	// Лишнее поле отклоняется при проверке готового JSON
+	data, _ := fiscal.Marshal(valid)
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	raw["extra"] = true
+	tampered, _ := json.Marshal(raw)
+	if err := fiscal.Validate(tampered); !errors.Is(err, fiscal.ErrInvalidDocument) {
+		t.Errorf("expected ErrInvalidDocument, got: %v", err)
+	}
+}
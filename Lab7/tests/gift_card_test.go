@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// giftCardNow - текущее время в тестах подарочных карт
+var giftCardNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// setupGiftCardEnvironment создаёт заказ на 100.00 RUB, подарочную карту
+// с балансом balance и use-case оплаты с картами
+func setupGiftCardEnvironment(t *testing.T, balance int64) (*infrastructure.InMemoryOrderRepository, *infrastructure.InMemoryGiftCardRepository, *infrastructure.FakePaymentGateway, *application.PayOrderUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryOrderRepository()
+	giftCards := infrastructure.NewInMemoryGiftCardRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+
+	card, err := domain.NewGiftCard("gift-1", rub(balance), giftCardNow.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	giftCards.Save(t.Context(), card)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 10})
+
+	useCase := application.NewPayOrderUseCase(repo, gateway,
+		application.WithGiftCards(giftCards),
+		application.WithClock(func() time.Time { return giftCardNow }))
+	return repo, giftCards, gateway, useCase
+}
+
+// giftCardBalance возвращает остаток на карте в минимальных единицах
+func giftCardBalance(t *testing.T, giftCards *infrastructure.InMemoryGiftCardRepository, id string) int64 {
+	t.Helper()
+	card, err := giftCards.GetByID(t.Context(), id)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return card.Balance().Amount()
+}
+
+// TestGiftCard_Redeem проверяет правила списания с подарочной карты
+func TestGiftCard_Redeem(t *testing.T) {
+	card, _ := domain.NewGiftCard("gift-1", rub(5000), giftCardNow.Add(time.Hour))
+
+	if err := card.Redeem(rub(5001), giftCardNow); !errors.Is(err, domain.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance, got: %v", err)
+	}
+	usd, _ := domain.NewMoney(100, "USD")
+	if err := card.Redeem(usd, giftCardNow); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got: %v", err)
+	}
+	if err := card.Redeem(rub(5000), giftCardNow); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !card.Balance().Equals(rub(0)) {
+		t.Errorf("expected empty card, got %s", card.Balance())
+	}
+
+	card.Credit(rub(1000))
+	if err := card.Redeem(rub(100), giftCardNow.Add(time.Hour)); !errors.Is(err, domain.ErrGiftCardExpired) {
+		t.Errorf("expected ErrGiftCardExpired, got: %v", err)
+	}
+	if _, err := domain.NewGiftCard("", rub(100), time.Time{}); !errors.Is(err, domain.ErrEmptyGiftCardID) {
+		t.Errorf("expected ErrEmptyGiftCardID, got: %v", err)
+	}
+}
+
+// TestPayOrder_GiftCardCoversTotal проверяет оплату заказа целиком подарочной картой
+func TestPayOrder_GiftCardCoversTotal(t *testing.T) {
+	repo, giftCards, gateway, useCase := setupGiftCardEnvironment(t, 15000)
+
+	if _, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 5000 {
+		t.Errorf("expected 50.00 RUB left on card, got %d", balance)
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+
+	// Возврат зачисляет деньги обратно на карту
+	refund := application.NewRefundOrderUseCase(repo, gateway, application.RestoreGiftCards(giftCards))
+	if _, err := refund.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 15000 {
+		t.Errorf("expected 150.00 RUB after refund, got %d", balance)
+	}
+}
+
+// TestPayOrder_GiftCardThenGateway проверяет списание остатка через шлюз
+func TestPayOrder_GiftCardThenGateway(t *testing.T) {
+	repo, giftCards, gateway, useCase := setupGiftCardEnvironment(t, 3000)
+
+	_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{
+		OrderID:    "order-1",
+		GiftCardID: "gift-1",
+		Legs:       []application.PaymentLegCommand{{Method: "CARD", Reference: "tok_visa_4242", Amount: 7000}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 0 {
+		t.Errorf("expected empty card, got %d", balance)
+	}
+	payments := gateway.GetPayments()
+	if len(payments) != 1 || !payments[0].Amount.Equals(rub(7000)) {
+		t.Errorf("expected 70.00 RUB charged by card, got %+v", payments)
+	}
+
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	legs := order.Payments()
+	if len(legs) != 2 || legs[0].Method.Kind() != domain.PaymentMethodGiftCard || !legs[0].Amount.Equals(rub(3000)) {
+		t.Errorf("expected gift card leg first, got %v", legs)
+	}
+}
+
+// TestPayOrder_GiftCardRestoredOnFailure проверяет возврат баланса при отказе шлюза
+func TestPayOrder_GiftCardRestoredOnFailure(t *testing.T) {
+	repo, giftCards, gateway, useCase := setupGiftCardEnvironment(t, 3000)
+	gateway.SetShouldFail(true, "card declined")
+
+	_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-1"})
+	if !errors.Is(err, application.ErrPaymentFailed) {
+		t.Fatalf("expected ErrPaymentFailed, got: %v", err)
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 3000 {
+		t.Errorf("expected balance to be restored to 30.00 RUB, got %d", balance)
+	}
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if order.Status() != domain.OrderStatusPending {
+		t.Errorf("expected order to stay PENDING, got %s", order.Status())
+	}
+}
+
+// TestPayOrder_ExpiredGiftCard проверяет отказ для просроченной карты
+func TestPayOrder_ExpiredGiftCard(t *testing.T) {
+	_, giftCards, gateway, useCase := setupGiftCardEnvironment(t, 15000)
+	expired, _ := domain.NewGiftCard("gift-old", rub(15000), giftCardNow.Add(-time.Second))
+	giftCards.Save(t.Context(), expired)
+
+	_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-old"})
+	if !errors.Is(err, domain.ErrGiftCardExpired) {
+		t.Fatalf("expected ErrGiftCardExpired, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeGiftCardExpired {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeGiftCardExpired, application.ErrorCode(err))
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+
+	_, err = useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-missing"})
+	if !errors.Is(err, application.ErrGiftCardNotFound) {
+		t.Errorf("expected ErrGiftCardNotFound, got: %v", err)
+	}
+}
+
+// TestPayOrder_GiftCardsNotAccepted проверяет отказ, когда оплата картами
+// не подключена
+func TestPayOrder_GiftCardsNotAccepted(t *testing.T) {
+	repo, gateway, useCase := setupTestEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+
+	_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-1"})
+	if !errors.Is(err, application.ErrGiftCardsNotAccepted) || errors.Is(err, application.ErrGiftCardNotFound) {
+		t.Fatalf("expected ErrGiftCardsNotAccepted, got: %v", err)
+	}
+	if code := application.ErrorCode(err); code != application.ErrorCodeNoGiftCards {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeNoGiftCards, code)
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+}
+
+// TestPayOrder_GiftCardConcurrentRedeem проверяет, что параллельные оплаты
+// не уводят баланс карты в минус
+func TestPayOrder_GiftCardConcurrentRedeem(t *testing.T) {
+	const orders = 20
+	repo, giftCards, _, useCase := setupGiftCardEnvironment(t, 50000)
+	for i := range orders {
+		saveOrderWithLines(t, repo, fmt.Sprintf("order-%d", i), stockLine{"product-1", 10})
+	}
+
+	var paid, declined atomic.Int32
+	var wg sync.WaitGroup
+	for i := range orders {
+		wg.Go(func() {
+			_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{
+				OrderID: fmt.Sprintf("order-%d", i),
+				Legs:    []application.PaymentLegCommand{{Method: "GIFT_CARD", Reference: "gift-1", Amount: 10000}},
+			})
+			switch {
+			case err == nil:
+				paid.Add(1)
+			case errors.Is(err, domain.ErrInsufficientBalance):
+				declined.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if paid.Load() != 5 || declined.Load() != orders-5 {
+		t.Errorf("expected 5 paid and %d declined, got %d and %d", orders-5, paid.Load(), declined.Load())
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 0 {
+		t.Errorf("expected empty card, got %d", balance)
+	}
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+	"time"
+)
+
+// TestSplitAmount проверяет деление суммы без потерь с остатком на последней части
+func TestSplitAmount(t *testing.T) {
+	parts, err := domain.SplitAmount(rub(1000), 3)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := []int64{333, 333, 334}
+	var sum int64
+	for i, part := range parts {
+		if part.Amount() != want[i] || part.Currency() != "RUB" {
+			t.Errorf("part %d: expected %d RUB, got %s", i, want[i], part)
+		}
+		sum += part.Amount()
+	}
+	if sum != 1000 {
+		t.Errorf("expected parts to sum to 1000, got %d", sum)
+	}
+
+	if _, err := domain.SplitAmount(rub(2), 3); !errors.Is(err, domain.ErrInvalidInstallmentPlan) {
+		t.Errorf("expected ErrInvalidInstallmentPlan for amount smaller than parts, got: %v", err)
+	}
+	if _, err := domain.SplitAmount(rub(100), 0); !errors.Is(err, domain.ErrInvalidInstallmentPlan) {
+		t.Errorf("expected ErrInvalidInstallmentPlan for zero parts, got: %v", err)
+	}
+}
+
+// TestOrder_ScheduleInstallments проверяет правила назначения графика
+func TestOrder_ScheduleInstallments(t *testing.T) {
+	due := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+	order := domain.NewOrder("order-1")
+	line, _ := domain.NewOrderLine("product-1", rub(1001), 1)
+	order.AddLine(line)
+
+	if err := order.ScheduleInstallments([]time.Time{due}); !errors.Is(err, domain.ErrInvalidInstallmentPlan) {
+		t.Errorf("expected ErrInvalidInstallmentPlan for a single payment, got: %v", err)
+	}
+	if err := order.ScheduleInstallments([]time.Time{due, due}); !errors.Is(err, domain.ErrInvalidInstallmentPlan) {
+		t.Errorf("expected ErrInvalidInstallmentPlan for non-increasing dates, got: %v", err)
+	}
+	if err := order.ScheduleInstallments([]time.Time{due, due.AddDate(0, 1, 0)}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if installments := order.Installments(); len(installments) != 2 || installments[0].Amount.Amount() != 500 || installments[1].Amount.Amount() != 501 {
+		t.Errorf("expected 5.00 + 5.01, got %+v", installments)
+	}
+
+	// Заказ по графику не оплачивается целиком
+	if err := order.Pay(); !errors.Is(err, domain.ErrInstallmentsScheduled) {
+		t.Errorf("expected ErrInstallmentsScheduled, got: %v", err)
+	}
+
+	// Изменение состава заказа сбрасывает график, так как меняется итог
+	order.AddLine(line)
+	if len(order.Installments()) != 0 {
+		t.Error("expected AddLine to clear installments")
+	}
+	if _, err := order.PayInstallment(domain.PaymentMethod{}, due); !errors.Is(err, domain.ErrInstallmentsNotScheduled) {
+		t.Errorf("expected ErrInstallmentsNotScheduled, got: %v", err)
+	}
+}
+
+// setupInstallments создаёт заказ на 100.00 RUB с графиком из трёх
+// ежемесячных платежей и use-case их оплаты
+func setupInstallments(t *testing.T, now *time.Time) (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, *application.PayInstallmentUseCase) {
+	t.Helper()
+	repo, gateway, _ := setupTestEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 10})
+
+	schedule := application.NewScheduleInstallmentsUseCase(repo)
+	view, err := schedule.Execute(t.Context(), application.ScheduleInstallmentsCommand{
+		OrderID:  "order-1",
+		Count:    3,
+		FirstDue: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(view.Installments) != 3 {
+		t.Fatalf("expected 3 installments, got %+v", view.Installments)
+	}
+	return repo, gateway, application.NewPayInstallmentUseCase(repo, gateway, application.InstallmentClock(func() time.Time { return *now }))
+}
+
+// TestPayInstallment_PartiallyPaidThenPaid проверяет переход
+// PENDING -> PARTIALLY_PAID -> PAID и списание каждого платежа
+func TestPayInstallment_PartiallyPaidThenPaid(t *testing.T) {
+	now := time.Date(2025, time.January, 20, 12, 0, 0, 0, time.UTC)
+	repo, gateway, useCase := setupInstallments(t, &now)
+
+	wantStatus := []domain.OrderStatus{domain.OrderStatusPartiallyPaid, domain.OrderStatusPartiallyPaid, domain.OrderStatusPaid}
+	wantAmount := []int64{3333, 3333, 3334}
+	for i := range 3 {
+		result, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1", Method: "CARD", Reference: "tok_visa_4242"})
+		if err != nil {
+			t.Fatalf("installment %d: expected no error, got: %v", i+1, err)
+		}
+		if result.Number != i+1 || result.Amount != wantAmount[i] || result.Remaining != 2-i {
+			t.Errorf("installment %d: unexpected result %+v", i+1, result)
+		}
+		if status := orderStatus(t, repo, "order-1"); status != wantStatus[i] {
+			t.Errorf("installment %d: expected %s, got %s", i+1, wantStatus[i], status)
+		}
+		now = now.AddDate(0, 1, 0)
+	}
+
+	payments := gateway.GetPayments()
+	if len(payments) != 3 {
+		t.Fatalf("expected 3 gateway charges, got %d", len(payments))
+	}
+	for i, p := range payments {
+		if p.Amount.Amount() != wantAmount[i] || p.Method.Kind() != domain.PaymentMethodCard {
+			t.Errorf("charge %d: unexpected %+v", i, p)
+		}
+	}
+
+	if _, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"}); !errors.Is(err, domain.ErrOrderAlreadyPaid) {
+		t.Errorf("expected ErrOrderAlreadyPaid, got: %v", err)
+	}
+
+	// Чек по оплаченному в рассрочку заказу перечисляет все платежи
+	receipt, err := application.NewGetReceiptUseCase(repo).Execute(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(receipt.Payments) != 3 || receipt.Total != 10000 {
+		t.Errorf("expected 3 payments totalling 10000, got %+v", receipt)
+	}
+}
+
+// TestPayInstallment_DeclinedIsNotRecorded проверяет, что отклонённый
+// платёж не отмечается внесённым и его можно повторить
+func TestPayInstallment_DeclinedIsNotRecorded(t *testing.T) {
+	now := time.Date(2025, time.January, 20, 12, 0, 0, 0, time.UTC)
+	repo, gateway, useCase := setupInstallments(t, &now)
+
+	gateway.SetShouldFail(true, "card declined")
+	if _, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"}); !errors.Is(err, application.ErrPaymentFailed) {
+		t.Fatalf("expected ErrPaymentFailed, got: %v", err)
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusPending {
+		t.Errorf("expected PENDING after declined installment, got %s", status)
+	}
+
+	gateway.SetShouldFail(false, "")
+	result, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"})
+	if err != nil || result.Number != 1 {
+		t.Fatalf("expected first installment on retry, got %+v, %v", result, err)
+	}
+}
+
+// TestRefund_PartiallyPaid проверяет возврат только внесённых платежей
+func TestRefund_PartiallyPaid(t *testing.T) {
+	now := time.Date(2025, time.January, 20, 12, 0, 0, 0, time.UTC)
+	repo, gateway, useCase := setupInstallments(t, &now)
+	if _, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	result, err := application.NewRefundOrderUseCase(repo, gateway).Execute(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Amount != 3333 {
+		t.Errorf("expected refund of the paid installment 3333, got %d", result.Amount)
+	}
+	if refunds := gateway.GetRefunds(); len(refunds) != 1 || refunds[0].Amount.Amount() != 3333 {
+		t.Errorf("expected one refund of 3333, got %+v", refunds)
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusRefunded {
+		t.Errorf("expected REFUNDED, got %s", status)
+	}
+}
+
+// TestReportOverdueInstallments проверяет отчёт о платежах, не внесённых в срок
+func TestReportOverdueInstallments(t *testing.T) {
+	now := time.Date(2025, time.January, 20, 12, 0, 0, 0, time.UTC)
+	repo, _, useCase := setupInstallments(t, &now)
+	if _, err := useCase.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Заказ без графика в отчёт не попадает
+	saveOrderWithLines(t, repo, "order-2", stockLine{"product-1", 1})
+
+	// Второй платёж (28 февраля) просрочен, третий (31 марта) ещё нет
+	reportAt := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	report := application.NewReportOverdueInstallmentsUseCase(repo, application.OverdueClock(func() time.Time { return reportAt }))
+	overdue, err := report.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(overdue) != 1 {
+		t.Fatalf("expected 1 overdue installment, got %+v", overdue)
+	}
+	want := time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)
+	if got := overdue[0]; got.OrderID != "order-1" || got.Number != 2 || !got.DueAt.Equal(want) || got.Amount != 3333 {
+		t.Errorf("unexpected overdue installment %+v", got)
+	}
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setupInventoryEnvironment создаёт use-case оплаты с резервированием товаров
+func setupInventoryEnvironment() (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, *infrastructure.InMemoryInventory, *application.PayOrderUseCase) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	inventory := infrastructure.NewInMemoryInventory(time.Minute)
+	useCase := application.NewPayOrderUseCase(repo, gateway, application.WithInventory(inventory))
+	return repo, gateway, inventory, useCase
+}
+
+// stockLine - строка тестового заказа
+type stockLine struct {
+	productID string
+	quantity  int
+}
+
+// saveOrderWithLines сохраняет заказ со строками по 10.00 RUB
+func saveOrderWithLines(t *testing.T, repo *infrastructure.InMemoryOrderRepository, id string, lines ...stockLine) {
+	t.Helper()
+	order := domain.NewOrder(id)
+	price, _ := domain.NewMoney(1000, "RUB")
+	for _, l := range lines {
+		line, _ := domain.NewOrderLine(l.productID, price, l.quantity)
+		order.AddLine(line)
+	}
+	repo.Save(t.Context(), order)
+}
+
+// TestInventory_CommitOnSuccess проверяет списание товара после оплаты
+func TestInventory_CommitOnSuccess(t *testing.T) {
+	repo, _, inventory, useCase := setupInventoryEnvironment()
+	inventory.SetStock("laptop", 5)
+	inventory.SetStock("mouse", 5)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"laptop", 2}, stockLine{"mouse", 1}, stockLine{"laptop", 1})
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if inventory.OnHand("laptop") != 2 || inventory.OnHand("mouse") != 4 {
+		t.Errorf("expected 2 laptops and 4 mice left, got %d and %d", inventory.OnHand("laptop"), inventory.OnHand("mouse"))
+	}
+	if inventory.Available("laptop") != 2 {
+		t.Errorf("expected no active reservations, got available %d", inventory.Available("laptop"))
+	}
+}
+
+// TestInventory_InsufficientStock проверяет отказ без списания средств
+func TestInventory_InsufficientStock(t *testing.T) {
+	repo, gateway, inventory, useCase := setupInventoryEnvironment()
+	inventory.SetStock("laptop", 5)
+	inventory.SetStock("mouse", 1)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"laptop", 1}, stockLine{"mouse", 2})
+
+	_, err := useCase.Execute(t.Context(), "order-1")
+	if !errors.Is(err, application.ErrInsufficientStock) {
+		t.Fatalf("expected ErrInsufficientStock, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeOutOfStock {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeOutOfStock, application.ErrorCode(err))
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+	// Резервирование выполняется целиком: ноутбук тоже не должен быть занят
+	if inventory.Available("laptop") != 5 {
+		t.Errorf("expected laptop stock to stay available, got %d", inventory.Available("laptop"))
+	}
+}
+
+// TestInventory_ReleaseOnPaymentFailure проверяет снятие резерва при отказе шлюза
+func TestInventory_ReleaseOnPaymentFailure(t *testing.T) {
+	repo, gateway, inventory, useCase := setupInventoryEnvironment()
+	inventory.SetStock("laptop", 1)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"laptop", 1})
+	gateway.SetShouldFail(true, "insufficient funds")
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); !errors.Is(err, application.ErrPaymentFailed) {
+		t.Fatalf("expected ErrPaymentFailed, got: %v", err)
+	}
+	if inventory.Available("laptop") != 1 || inventory.OnHand("laptop") != 1 {
+		t.Errorf("expected reservation to be released, got available %d, on hand %d",
+			inventory.Available("laptop"), inventory.OnHand("laptop"))
+	}
+	stored, _ := repo.GetByID(t.Context(), "order-1")
+	if stored.Status() != domain.OrderStatusPending {
+		t.Errorf("expected order to stay PENDING, got %s", stored.Status())
+	}
+}
+
+// TestInventory_UndoOnSaveFailure проверяет, что оплата, которую не
+// удалось сохранить, отменяется: деньги и баланс карты возвращаются, а
+// списанный товар - на склад
+func TestInventory_UndoOnSaveFailure(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	inventory := infrastructure.NewInMemoryInventory(time.Hour)
+	inventory.SetStock("laptop", 1)
+	giftCards := infrastructure.NewInMemoryGiftCardRepository()
+	card, _ := domain.NewGiftCard("gift-1", rub(400), giftCardNow.AddDate(1, 0, 0))
+	giftCards.Save(t.Context(), card)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"laptop", 1})
+
+	saveErr := errors.New("disk full")
+	useCase := application.NewPayOrderUseCase(&failingSaveRepository{InMemoryOrderRepository: repo, err: saveErr}, gateway,
+		application.WithInventory(inventory),
+		application.WithGiftCards(giftCards),
+		application.WithClock(func() time.Time { return giftCardNow }))
+	_, err := useCase.ExecuteCommand(t.Context(), application.PayOrderCommand{OrderID: "order-1", GiftCardID: "gift-1"})
+	if !errors.Is(err, saveErr) {
+		t.Fatalf("expected save error, got: %v", err)
+	}
+
+	refunds := gateway.GetRefunds()
+	if len(gateway.GetPayments()) != 1 || len(refunds) != 1 || refunds[0].Amount.Amount() != 600 {
+		t.Errorf("expected the 6.00 RUB charge to be refunded, got %+v", refunds)
+	}
+	if balance := giftCardBalance(t, giftCards, "gift-1"); balance != 400 {
+		t.Errorf("expected gift card balance to be restored, got %d", balance)
+	}
+	if inventory.OnHand("laptop") != 1 || inventory.Available("laptop") != 1 {
+		t.Errorf("expected stock to be returned, got on hand %d, available %d",
+			inventory.OnHand("laptop"), inventory.Available("laptop"))
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusPending {
+		t.Errorf("expected order to stay PENDING, got %s", status)
+	}
+}
+
+// TestInventory_ReservationExpires проверяет истечение резерва по TTL
+func TestInventory_ReservationExpires(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	inventory := infrastructure.NewInMemoryInventory(10 * time.Minute)
+	inventory.SetClock(func() time.Time { return now })
+	inventory.SetStock("laptop", 1)
+
+	items := []application.StockItem{{ProductID: "laptop", Quantity: 1}}
+	if err := inventory.Reserve(t.Context(), "order-1", items); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := inventory.Reserve(t.Context(), "order-2", items); !errors.Is(err, application.ErrInsufficientStock) {
+		t.Fatalf("expected ErrInsufficientStock while reserved, got: %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := inventory.Reserve(t.Context(), "order-2", items); err != nil {
+		t.Fatalf("expected expired reservation to free stock, got: %v", err)
+	}
+	if err := inventory.Commit(t.Context(), "order-1"); !errors.Is(err, application.ErrReservationNotFound) {
+		t.Errorf("expected ErrReservationNotFound for expired reservation, got: %v", err)
+	}
+}
+
+// TestInventory_ConcurrentOrders проверяет, что склад не продаёт больше остатка
+func TestInventory_ConcurrentOrders(t *testing.T) {
+	const stock, orders = 10, 40
+	repo, _, inventory, useCase := setupInventoryEnvironment()
+	inventory.SetStock("laptop", stock)
+	for i := range orders {
+		saveOrderWithLines(t, repo, fmt.Sprintf("order-%d", i), stockLine{"laptop", 1})
+	}
+
+	var paid, outOfStock atomic.Int32
+	var wg sync.WaitGroup
+	for i := range orders {
+		wg.Go(func() {
+			_, err := useCase.Execute(t.Context(), fmt.Sprintf("order-%d", i))
+			switch {
+			case err == nil:
+				paid.Add(1)
+			case errors.Is(err, application.ErrInsufficientStock):
+				outOfStock.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if paid.Load() != stock || outOfStock.Load() != orders-stock {
+		t.Errorf("expected %d paid and %d out of stock, got %d and %d", stock, orders-stock, paid.Load(), outOfStock.Load())
+	}
+	if inventory.OnHand("laptop") != 0 {
+		t.Errorf("expected empty stock, got %d", inventory.OnHand("laptop"))
+	}
+}
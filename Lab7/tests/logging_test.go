@@ -0,0 +1,273 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/logging"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// readLogEntries разбирает JSON-записи slog из буфера
+func readLogEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// findLogEntry возвращает первую запись операции operation
+func findLogEntry(t *testing.T, entries []map[string]any, operation string) map[string]any {
+	t.Helper()
+	for _, entry := range entries {
+		if entry[logging.KeyOperation] == operation {
+			return entry
+		}
+	}
+	t.Fatalf("no log entry for %s in %v", operation, entries)
+	return nil
+}
+
+// setupLoggedEnvironment собирает use-case оплаты с логирующими декораторами
+func setupLoggedEnvironment(buf *bytes.Buffer) (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, application.PayOrderExecutor) {
+	logger := slog.New(logging.NewRedactingHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	useCase := application.NewPayOrderUseCase(
+		logging.NewOrderRepository(repo, logger),
+		logging.NewPaymentGateway(gateway, logger))
+	return repo, gateway, logging.NewPayOrderUseCase(useCase, logger)
+}
+
+// TestLogging_SuccessfulPayment проверяет атрибуты записей успешной оплаты
+func TestLogging_SuccessfulPayment(t *testing.T) {
+	var buf bytes.Buffer
+	repo, _, useCase := setupLoggedEnvironment(&buf)
+
+	order := domain.NewOrder("order-1")
+	money, _ := domain.NewMoney(12500, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 2)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+
+	ctx := application.WithRequestID(t.Context(), "req-42")
+	if _, err := useCase.Execute(ctx, "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	entries := readLogEntries(t, &buf)
+	for _, operation := range []string{"repository.get", "gateway.charge", "repository.save", "pay_order"} {
+		entry := findLogEntry(t, entries, operation)
+		if entry[logging.KeyRequestID] != "req-42" {
+			t.Errorf("%s: expected request_id req-42, got %v", operation, entry[logging.KeyRequestID])
+		}
+		if entry[logging.KeyOrderID] != "order-1" {
+			t.Errorf("%s: expected order_id order-1, got %v", operation, entry[logging.KeyOrderID])
+		}
+		if entry[logging.KeyOutcome] != logging.OutcomeSuccess || entry[logging.KeyErrorCode] != application.ErrorCodeNone {
+			t.Errorf("%s: expected successful outcome, got %v/%v", operation, entry[logging.KeyOutcome], entry[logging.KeyErrorCode])
+		}
+		if _, ok := entry[logging.KeyDuration]; !ok {
+			t.Errorf("%s: expected duration attribute", operation)
+		}
+	}
+
+	pay := findLogEntry(t, entries, "pay_order")
+	if pay[logging.KeyAmount] != float64(25000) || pay[logging.KeyCurrency] != "RUB" {
+		t.Errorf("expected amount 25000 RUB, got %v %v", pay[logging.KeyAmount], pay[logging.KeyCurrency])
+	}
+	if pay["level"] != "INFO" {
+		t.Errorf("expected INFO level, got %v", pay["level"])
+	}
+}
+
+// TestLogging_FailureCarriesErrorCode проверяет код ошибки в записях неудачной оплаты
+func TestLogging_FailureCarriesErrorCode(t *testing.T) {
+	var buf bytes.Buffer
+	repo, gateway, useCase := setupLoggedEnvironment(&buf)
+
+	order := domain.NewOrder("order-1")
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 1)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+	gateway.SetShouldFail(true, "card 4111111111111111 declined")
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); err == nil {
+		t.Fatal("expected payment error")
+	}
+	if _, err := useCase.Execute(t.Context(), "order-404"); err == nil {
+		t.Fatal("expected not found error")
+	}
+
+	entries := readLogEntries(t, &buf)
+	var codes []string
+	for _, entry := range entries {
+		if entry[logging.KeyOperation] == "pay_order" {
+			codes = append(codes, entry[logging.KeyErrorCode].(string))
+			if entry[logging.KeyOutcome] != logging.OutcomeFailure {
+				t.Errorf("expected failure outcome, got %v", entry[logging.KeyOutcome])
+			}
+		}
+	}
+	// Сумма отклонённой оплаты тоже попадает в запись
+	if pay := findLogEntry(t, entries, "pay_order"); pay[logging.KeyAmount] != float64(10000) || pay[logging.KeyCurrency] != "RUB" {
+		t.Errorf("expected amount 10000 RUB on failure, got %v %v", pay[logging.KeyAmount], pay[logging.KeyCurrency])
+	}
+	expected := []string{application.ErrorCodePaymentFailed, application.ErrorCodeNotFound}
+	if strings.Join(codes, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected error codes %v, got %v", expected, codes)
+	}
+
+	// Номер карты из текста ошибки шлюза не должен попасть в лог
+	if strings.Contains(buf.String(), "4111111111111111") {
+		t.Error("card number leaked into logs")
+	}
+	charge := findLogEntry(t, entries, "gateway.charge")
+	if !strings.Contains(charge[logging.KeyError].(string), "************1111") {
+		t.Errorf("expected masked card number, got %v", charge[logging.KeyError])
+	}
+}
+
+// TestRedactingHandler_SensitiveKeys проверяет скрытие чувствительных атрибутов
+func TestRedactingHandler_SensitiveKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewRedactingHandler(slog.NewJSONHandler(&buf, nil))).
+		With(slog.String("email", "user@example.com"))
+
+	logger.Info("customer updated",
+		slog.String("customer_id", "c-1"),
+		slog.Group("payment", slog.String("card_token", "tok_secret"), slog.String("method", "card")),
+		slog.Any("cause", errors.New("pan 5500000000000004 rejected")))
+
+	output := buf.String()
+	for _, secret := range []string{"user@example.com", "tok_secret", "5500000000000004"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("sensitive value %q leaked: %s", secret, output)
+		}
+	}
+	for _, kept := range []string{"c-1", `"method":"card"`, logging.RedactedValue} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("expected %q in output: %s", kept, output)
+		}
+	}
+}
+
+// TestRedactingHandler_SeparatedCardNumbers проверяет маскирование номеров
+// карт, записанных группами через пробел или дефис
+func TestRedactingHandler_SeparatedCardNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewRedactingHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.Info("card 4111 1111 1111 1111 declined",
+		slog.String("note", "retry with 5500-0000-0000-0004"),
+		slog.String("created_at", "2025-03-01"))
+
+	output := buf.String()
+	for _, secret := range []string{"4111 1111 1111 1111", "5500-0000-0000-0004"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("card number %q leaked: %s", secret, output)
+		}
+	}
+	for _, kept := range []string{"**** **** **** 1111", "****-****-****-0004", "2025-03-01"} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("expected %q in output: %s", kept, output)
+		}
+	}
+}
+
+// TestLogging_OrderUseCases проверяет записи декораторов остальных use-case
+func TestLogging_OrderUseCases(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo, _, payUseCase := setupTestEnvironment()
+	create := logging.NewCreateOrderUseCase(application.NewCreateOrderUseCase(repo), logger)
+	addLine := logging.NewAddOrderLineUseCase(application.NewAddOrderLineUseCase(repo), logger)
+	get := logging.NewGetOrderUseCase(application.NewGetOrderUseCase(repo), logger)
+	list := logging.NewListOrdersUseCase(application.NewListOrdersUseCase(repo), logger)
+	batch := logging.NewPayOrdersBatchUseCase(application.NewPayOrdersBatchUseCase(payUseCase), logger)
+
+	ctx := application.WithRequestID(t.Context(), "req-7")
+	if _, err := create.Execute(ctx, application.CreateOrderCommand{OrderID: "order-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cmd := application.AddOrderLineCommand{OrderID: "order-1", ProductID: "product-1", UnitPrice: 1500, Currency: "RUB", Quantity: 2}
+	if _, err := addLine.Execute(ctx, cmd); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	get.Execute(ctx, "order-404")
+	list.Execute(ctx, application.ListOrdersRequest{})
+	if _, err := batch.Execute(ctx, []string{"order-1"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	entries := readLogEntries(t, &buf)
+	for _, operation := range []string{"create_order", "add_order_line", "get_order", "list_orders", "pay_orders_batch"} {
+		if entry := findLogEntry(t, entries, operation); entry[logging.KeyRequestID] != "req-7" {
+			t.Errorf("%s: expected request_id req-7, got %v", operation, entry[logging.KeyRequestID])
+		}
+	}
+	if line := findLogEntry(t, entries, "add_order_line"); line[logging.KeyAmount] != float64(3000) || line[logging.KeyCurrency] != "RUB" {
+		t.Errorf("expected order total 3000 RUB, got %v %v", line[logging.KeyAmount], line[logging.KeyCurrency])
+	}
+	if get := findLogEntry(t, entries, "get_order"); get[logging.KeyErrorCode] != application.ErrorCodeNotFound || get["level"] != "WARN" {
+		t.Errorf("expected WARN entry with %s, got %v", application.ErrorCodeNotFound, get)
+	}
+	if list := findLogEntry(t, entries, "list_orders"); list["results"] != float64(1) || list["level"] != "DEBUG" {
+		t.Errorf("expected DEBUG entry with 1 result, got %v", list)
+	}
+	outcomes, _ := findLogEntry(t, entries, "pay_orders_batch")["outcomes"].(map[string]any)
+	if outcomes[string(application.BatchPaid)] != float64(1) {
+		t.Errorf("expected one paid order in batch outcomes, got %v", outcomes)
+	}
+}
+
+// TestLogging_Tenant проверяет, что записи содержат магазин из контекста
+func TestLogging_Tenant(t *testing.T) {
+	var buf bytes.Buffer
+	repo, _, useCase := setupLoggedEnvironment(&buf)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+
+	ctx := application.WithTenant(t.Context(), "shop-a")
+	if _, err := useCase.Execute(ctx, "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	entry := findLogEntry(t, readLogEntries(t, &buf), "pay_order")
+	if entry[logging.KeyTenant] != "shop-a" {
+		t.Errorf("expected tenant shop-a, got %v", entry[logging.KeyTenant])
+	}
+}
+
+// TestLogging_SplitPayment проверяет, что оплата частями проходит через
+// декоратор так же, как оплата целиком
+func TestLogging_SplitPayment(t *testing.T) {
+	var buf bytes.Buffer
+	repo, _, useCase := setupLoggedEnvironment(&buf)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 3})
+
+	cmd := application.PayOrderCommand{OrderID: "order-1", Legs: []application.PaymentLegCommand{
+		{Method: "CARD", Reference: "tok_1", Amount: 2000},
+		{Method: "WALLET", Reference: "wallet-1", Amount: 1000},
+	}}
+	if _, err := useCase.ExecuteCommand(t.Context(), cmd); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	pay := findLogEntry(t, readLogEntries(t, &buf), "pay_order")
+	if pay[logging.KeyOrderID] != "order-1" || pay[logging.KeyAmount] != float64(3000) {
+		t.Errorf("expected pay_order entry for 3000 of order-1, got %v", pay)
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"io"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupMetricsEnvironment собирает use-case оплаты с декораторами метрик
+func setupMetricsEnvironment(t *testing.T, opts metrics.Options) (*metrics.Registry, *infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, application.PayOrderExecutor) {
+	t.Helper()
+	registry := metrics.NewRegistry()
+	collector, err := metrics.NewCollector(registry, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	useCase := application.NewPayOrderUseCase(
+		metrics.NewOrderRepository(repo, collector),
+		metrics.NewPaymentGateway(gateway, collector))
+	return registry, repo, gateway, metrics.NewPayOrderUseCase(useCase, collector)
+}
+
+// scrape запрашивает метрики через HTTP-обработчик
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("expected content type %q, got %q", metrics.ContentType, ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+// TestMetrics_PaymentOutcomes проверяет счётчики исходов оплаты и вызовов шлюза
+func TestMetrics_PaymentOutcomes(t *testing.T) {
+	registry, repo, gateway, useCase := setupMetricsEnvironment(t, metrics.Options{})
+
+	for _, id := range []string{"order-1", "order-2"} {
+		order := domain.NewOrder(id)
+		money, _ := domain.NewMoney(10000, "RUB")
+		line, _ := domain.NewOrderLine("product-1", money, 1)
+		order.AddLine(line)
+		repo.Save(t.Context(), order)
+	}
+
+	useCase.Execute(t.Context(), "order-1")
+	useCase.Execute(t.Context(), "order-1") // повторная оплата
+	gateway.SetShouldFail(true, "insufficient funds")
+	useCase.Execute(t.Context(), "order-2")
+	useCase.Execute(t.Context(), "order-404")
+
+	body := scrape(t, registry)
+	expected := []string{
+		"# TYPE orders_use_case_executions_total counter",
+		`orders_use_case_executions_total{use_case="pay_order",outcome="success",error_code="none"} 1`,
+		`orders_use_case_executions_total{use_case="pay_order",outcome="failure",error_code="already_paid"} 1`,
+		`orders_use_case_executions_total{use_case="pay_order",outcome="failure",error_code="payment_failed"} 1`,
+		`orders_use_case_executions_total{use_case="pay_order",outcome="failure",error_code="order_not_found"} 1`,
+		`orders_gateway_calls_total{operation="charge",outcome="success",error_code="none"} 1`,
+		`orders_gateway_calls_total{operation="charge",outcome="failure",error_code="internal"} 1`,
+		`orders_repository_operations_total{operation="get",outcome="success"} 3`,
+		`orders_repository_operations_total{operation="get",outcome="failure"} 1`,
+		`orders_repository_operations_total{operation="save",outcome="success"} 1`,
+		"# TYPE orders_use_case_duration_seconds histogram",
+		`orders_use_case_duration_seconds_bucket{use_case="pay_order",le="+Inf"} 4`,
+		`orders_use_case_duration_seconds_count{use_case="pay_order"} 4`,
+		`orders_gateway_call_duration_seconds_count{operation="charge"} 2`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, body)
+		}
+	}
+}
+
+// TestMetrics_ConfigurableBuckets проверяет пользовательские границы гистограмм
+func TestMetrics_ConfigurableBuckets(t *testing.T) {
+	registry := metrics.NewRegistry()
+	histogram, err := registry.NewHistogramVec("test_latency_seconds", "Test latency.", []float64{0.1, 1}, "op")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	histogram.Observe(0.05, "a")
+	histogram.Observe(0.1, "a")
+	histogram.Observe(0.5, "a")
+	histogram.Observe(3, "a")
+
+	body := scrape(t, registry)
+	expected := `# HELP test_latency_seconds Test latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{op="a",le="0.1"} 2
+test_latency_seconds_bucket{op="a",le="1"} 3
+test_latency_seconds_bucket{op="a",le="+Inf"} 4
+test_latency_seconds_sum{op="a"} 3.65
+test_latency_seconds_count{op="a"} 4
+`
+	if body != expected {
+		t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", body, expected)
+	}
+
+	if _, err := registry.NewHistogramVec("bad_buckets", "Bad.", []float64{1, 0.5}); err == nil {
+		t.Error("expected error for unsorted buckets")
+	}
+	if _, err := registry.NewCounterVec("test_latency_seconds", "Duplicate."); err == nil {
+		t.Error("expected error for duplicate metric name")
+	}
+}
+
+// TestMetrics_LabelEscaping проверяет экранирование значений меток
+func TestMetrics_LabelEscaping(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter, _ := registry.NewCounterVec("test_total", "Help with \\ backslash.", "value")
+	counter.Inc("quote \" and\nnewline")
+
+	body := scrape(t, registry)
+	if !strings.Contains(body, `test_total{value="quote \" and\nnewline"} 1`) {
+		t.Errorf("label value is not escaped:\n%s", body)
+	}
+	if !strings.Contains(body, `# HELP test_total Help with \\ backslash.`) {
+		t.Errorf("help text is not escaped:\n%s", body)
+	}
+}
@@ -0,0 +1,345 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+	"time"
+)
+
+// setOverdue задаёт заказу срок оплаты в прошлом и возвращает момент после него
+func setOverdue(t *testing.T, repo *infrastructure.InMemoryOrderRepository, id string) time.Time {
+	t.Helper()
+	order, err := repo.GetByID(t.Context(), id)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	deadline := order.CreatedAt().Add(30 * time.Minute)
+	if err := order.SetPaymentDeadline(deadline); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	repo.Save(t.Context(), order)
+	return deadline.Add(time.Second)
+}
+
+// orderStatus возвращает текущий статус заказа в репозитории
+func orderStatus(t *testing.T, repo *infrastructure.InMemoryOrderRepository, id string) domain.OrderStatus {
+	t.Helper()
+	order, err := repo.GetByID(t.Context(), id)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return order.Status()
+}
+
+// TestOrder_PayAfterDeadline проверяет, что заказ с истёкшим сроком нельзя
+// оплатить, даже если его ещё не перевели в EXPIRED
+func TestOrder_PayAfterDeadline(t *testing.T) {
+	order := domain.NewOrder("order-1")
+	line, _ := domain.NewOrderLine("product-1", rub(100), 1)
+	order.AddLine(line)
+	deadline := order.CreatedAt().Add(time.Hour)
+	order.SetPaymentDeadline(deadline)
+
+	if err := order.PayWith(nil, deadline); !errors.Is(err, domain.ErrOrderExpired) {
+		t.Errorf("expected ErrOrderExpired, got: %v", err)
+	}
+	if order.Status() != domain.OrderStatusPending {
+		t.Errorf("expected PENDING, got %s", order.Status())
+	}
+	if err := order.PayWith(nil, deadline.Add(-time.Second)); err != nil {
+		t.Errorf("expected payment before deadline to succeed, got: %v", err)
+	}
+}
+
+// TestOrder_Expire проверяет правила перевода заказа в EXPIRED
+func TestOrder_Expire(t *testing.T) {
+	order := domain.NewOrder("order-1")
+	line, _ := domain.NewOrderLine("product-1", rub(100), 1)
+	order.AddLine(line)
+
+	// Без срока оплаты заказ не просрочивается
+	if err := order.Expire(order.CreatedAt().AddDate(1, 0, 0)); !errors.Is(err, domain.ErrOrderNotOverdue) {
+		t.Errorf("expected ErrOrderNotOverdue, got: %v", err)
+	}
+
+	deadline := order.CreatedAt().Add(time.Hour)
+	order.SetPaymentDeadline(deadline)
+	if order.IsOverdue(deadline.Add(-time.Second)) {
+		t.Error("expected order not to be overdue before deadline")
+	}
+	if err := order.Expire(deadline); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if order.Status() != domain.OrderStatusExpired {
+		t.Errorf("expected EXPIRED, got %s", order.Status())
+	}
+
+	if err := order.Pay(); !errors.Is(err, domain.ErrOrderExpired) {
+		t.Errorf("expected ErrOrderExpired, got: %v", err)
+	}
+	if err := order.AddLine(line); !errors.Is(err, domain.ErrOrderNotModifiable) {
+		t.Errorf("expected ErrOrderNotModifiable, got: %v", err)
+	}
+	if err := order.Expire(deadline); !errors.Is(err, domain.ErrOrderExpired) {
+		t.Errorf("expected ErrOrderExpired, got: %v", err)
+	}
+
+	paid := domain.NewOrder("order-2")
+	paid.AddLine(line)
+	paid.SetPaymentDeadline(deadline)
+	paid.Pay()
+	if paid.IsOverdue(deadline) {
+		t.Error("expected paid order not to be overdue")
+	}
+	if err := paid.Expire(deadline); !errors.Is(err, domain.ErrOrderAlreadyPaid) {
+		t.Errorf("expected ErrOrderAlreadyPaid, got: %v", err)
+	}
+}
+
+// TestCreateOrder_PaymentDeadline проверяет срок оплаты новых заказов
+func TestCreateOrder_PaymentDeadline(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	useCase := application.NewCreateOrderUseCase(repo, application.PaymentDeadline(30*time.Minute))
+
+	view, err := useCase.Execute(t.Context(), application.CreateOrderCommand{OrderID: "order-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if view.PayBy == nil || !view.PayBy.Equal(view.CreatedAt.Add(30*time.Minute)) {
+		t.Errorf("expected deadline 30m after creation, got %v", view.PayBy)
+	}
+}
+
+// TestExpireOrders_ReleasesStock проверяет, что проход переводит в EXPIRED
+// только просроченные неоплаченные заказы и снимает их резервы
+func TestExpireOrders_ReleasesStock(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	inventory := infrastructure.NewInMemoryInventory(time.Hour)
+	inventory.SetStock("product-1", 10)
+
+	saveOrderWithLines(t, repo, "overdue", stockLine{"product-1", 3})
+	saveOrderWithLines(t, repo, "no-deadline", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "paid", stockLine{"product-1", 1})
+	now := setOverdue(t, repo, "overdue")
+	setOverdue(t, repo, "paid")
+	paid, _ := repo.GetByID(t.Context(), "paid")
+	paid.Pay()
+	repo.Save(t.Context(), paid)
+
+	if err := inventory.Reserve(t.Context(), "overdue", []application.StockItem{{ProductID: "product-1", Quantity: 3}}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	useCase := application.NewExpireOrdersUseCase(repo,
+		application.ReleaseStock(inventory),
+		application.ExpireClock(func() time.Time { return now }))
+	result, err := useCase.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Expired) != 1 || result.Expired[0] != "overdue" {
+		t.Errorf("expected only overdue order to expire, got %v", result.Expired)
+	}
+	for id, want := range map[string]domain.OrderStatus{
+		"overdue":     domain.OrderStatusExpired,
+		"no-deadline": domain.OrderStatusPending,
+		"paid":        domain.OrderStatusPaid,
+	} {
+		if got := orderStatus(t, repo, id); got != want {
+			t.Errorf("%s: expected %s, got %s", id, want, got)
+		}
+	}
+	if available := inventory.Available("product-1"); available != 10 {
+		t.Errorf("expected reservation to be released, got %d available", available)
+	}
+
+	// Повторный проход ничего не меняет
+	if result, _ := useCase.Execute(t.Context()); len(result.Expired) != 0 {
+		t.Errorf("expected no orders on second sweep, got %v", result.Expired)
+	}
+}
+
+// payOnReadRepository - хранилище, в котором заказ оплачивается другим
+// процессом в момент, когда его читают для изменения: сразу после GetByID
+// или перед атомарным Update
+type payOnReadRepository struct {
+	*infrastructure.InMemoryOrderRepository
+}
+
+func (r *payOnReadRepository) GetByID(ctx context.Context, orderID string) (*domain.Order, error) {
+	order, err := r.InMemoryOrderRepository.GetByID(ctx, orderID)
+	if err == nil {
+		r.pay(ctx, orderID)
+	}
+	return order, err
+}
+
+func (r *payOnReadRepository) Update(ctx context.Context, orderID string, fn func(order *domain.Order) error) error {
+	r.pay(ctx, orderID)
+	return r.InMemoryOrderRepository.Update(ctx, orderID, fn)
+}
+
+// pay оплачивает заказ в обход вызывающего
+func (r *payOnReadRepository) pay(ctx context.Context, orderID string) {
+	order, err := r.InMemoryOrderRepository.GetByID(ctx, orderID)
+	if err == nil && order.Pay() == nil {
+		r.InMemoryOrderRepository.Save(ctx, order)
+	}
+}
+
+// TestExpireOrders_ConcurrentPayment проверяет, что заказ, оплаченный во
+// время прохода, не переводится в EXPIRED и сохраняет резерв
+func TestExpireOrders_ConcurrentPayment(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	inventory := infrastructure.NewInMemoryInventory(time.Hour)
+	inventory.SetStock("product-1", 10)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 3})
+	now := setOverdue(t, repo, "order-1")
+	inventory.Reserve(t.Context(), "order-1", []application.StockItem{{ProductID: "product-1", Quantity: 3}})
+
+	useCase := application.NewExpireOrdersUseCase(&payOnReadRepository{repo},
+		application.ReleaseStock(inventory),
+		application.ExpireClock(func() time.Time { return now }))
+	result, err := useCase.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Expired) != 0 {
+		t.Errorf("expected no orders to expire, got %v", result.Expired)
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusPaid {
+		t.Errorf("expected payment to be kept, got %s", status)
+	}
+	if available := inventory.Available("product-1"); available != 7 {
+		t.Errorf("expected reservation to be kept, got %d available", available)
+	}
+}
+
+// TestPayOrder_OverdueOrder проверяет, что просроченный заказ не оплачивается,
+// даже если фоновая проверка до него ещё не дошла
+func TestPayOrder_OverdueOrder(t *testing.T) {
+	repo, gateway, _ := setupTestEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	now := setOverdue(t, repo, "order-1")
+	useCase := application.NewPayOrderUseCase(repo, gateway, application.WithClock(func() time.Time { return now }))
+
+	_, err := useCase.Execute(t.Context(), "order-1")
+	if !errors.Is(err, domain.ErrOrderExpired) {
+		t.Fatalf("expected ErrOrderExpired, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeExpired {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeExpired, application.ErrorCode(err))
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusExpired {
+		t.Errorf("expected EXPIRED to be saved, got %s", status)
+	}
+}
+
+// TestPayOrder_OverdueConcurrentPayment проверяет, что перевод
+// просроченного заказа в EXPIRED не затирает оплату, проведённую другим
+// процессом после чтения заказа
+func TestPayOrder_OverdueConcurrentPayment(t *testing.T) {
+	repo, gateway, _ := setupTestEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	now := setOverdue(t, repo, "order-1")
+	useCase := application.NewPayOrderUseCase(&payOnReadRepository{repo}, gateway, application.WithClock(func() time.Time { return now }))
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); !errors.Is(err, domain.ErrOrderAlreadyPaid) {
+		t.Fatalf("expected ErrOrderAlreadyPaid, got: %v", err)
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusPaid {
+		t.Errorf("expected payment to be kept, got %s", status)
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+}
+
+// TestOrderSweeper проверяет фоновые проходы и остановку OrderSweeper
+func TestOrderSweeper(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	now := setOverdue(t, repo, "order-1")
+
+	ticks := make(chan time.Time)
+	sweeps := make(chan application.ExpireOrdersResult, 1)
+	sweeper := infrastructure.NewOrderSweeper(
+		application.NewExpireOrdersUseCase(repo, application.ExpireClock(func() time.Time { return now })),
+		time.Hour,
+		infrastructure.SweepTicks(ticks),
+		infrastructure.OnSweep(func(result application.ExpireOrdersResult, err error) {
+			if err != nil {
+				t.Errorf("unexpected sweep error: %v", err)
+			}
+			sweeps <- result
+		}))
+
+	if err := sweeper.Start(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := sweeper.Start(t.Context()); err == nil {
+		t.Error("expected error on second Start")
+	}
+
+	ticks <- now
+	select {
+	case result := <-sweeps:
+		if len(result.Expired) != 1 {
+			t.Errorf("expected one expired order, got %v", result.Expired)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sweep did not run")
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusExpired {
+		t.Errorf("expected EXPIRED, got %s", status)
+	}
+
+	// После Stop горутина завершена и сигналы никто не читает
+	sweeper.Stop()
+	select {
+	case ticks <- now:
+		t.Error("expected sweeper goroutine to be stopped")
+	case <-time.After(20 * time.Millisecond):
+	}
+	sweeper.Stop()
+
+	if err := sweeper.Start(t.Context()); err != nil {
+		t.Errorf("expected restart after Stop, got: %v", err)
+	}
+	sweeper.Stop()
+}
+
+// TestOrderSweeper_RestartAfterCancel проверяет, что после отмены
+// контекста запуска проверку можно запустить снова без Stop
+func TestOrderSweeper_RestartAfterCancel(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	sweeper := infrastructure.NewOrderSweeper(application.NewExpireOrdersUseCase(repo), time.Hour,
+		infrastructure.SweepTicks(make(chan time.Time)))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	if err := sweeper.Start(ctx); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := sweeper.Start(t.Context())
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected restart after context cancellation, got: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sweeper.Stop()
+	sweeper.Stop()
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+var queryBaseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newQueryTestOrder создаёт заказ с заданным временем создания и строками
+func newQueryTestOrder(id string, createdAt time.Time, status domain.OrderStatus, currency string, lines map[string]int64) *domain.Order {
+	products := make([]string, 0, len(lines))
+	for productID := range lines {
+		products = append(products, productID)
+	}
+	sort.Strings(products)
+
+	orderLines := make([]domain.OrderLine, 0, len(lines))
+	for _, productID := range products {
+		price, _ := domain.NewMoney(lines[productID], currency)
+		line, _ := domain.NewOrderLine(productID, price, 1)
+		orderLines = append(orderLines, line)
+	}
+	return domain.RestoreOrder(domain.OrderSnapshot{
+		ID:        id,
+		Lines:     orderLines,
+		Status:    status,
+		CreatedAt: createdAt,
+	})
+}
+
+// collectAll обходит все страницы запроса
+func collectAll(t *testing.T, repo application.OrderRepository, query application.OrderQuery) []string {
+	t.Helper()
+	var ids []string
+	for pages := 0; ; pages++ {
+		if pages > 1000 {
+			t.Fatal("pagination does not terminate")
+		}
+		page, err := repo.Find(t.Context(), query)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		for _, order := range page.Orders {
+			ids = append(ids, order.ID())
+		}
+		if page.NextCursor == "" {
+			return ids
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// TestOrderQuery_Filters проверяет отдельные фильтры запроса
+func TestOrderQuery_Filters(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	repo.Save(t.Context(), newQueryTestOrder("a", queryBaseTime, domain.OrderStatusPending, "RUB", map[string]int64{"laptop": 10000}))
+	repo.Save(t.Context(), newQueryTestOrder("b", queryBaseTime.Add(time.Hour), domain.OrderStatusPaid, "RUB", map[string]int64{"mouse": 500}))
+	repo.Save(t.Context(), newQueryTestOrder("c", queryBaseTime.Add(2*time.Hour), domain.OrderStatusPaid, "USD", map[string]int64{"laptop": 900, "mouse": 100}))
+	repo.Save(t.Context(), newQueryTestOrder("d", queryBaseTime.Add(3*time.Hour), domain.OrderStatusPending, "RUB", nil))
+
+	minTotal, maxTotal := int64(600), int64(10000)
+	tests := []struct {
+		name     string
+		query    application.OrderQuery
+		expected []string
+	}{
+		{"all", application.OrderQuery{}, []string{"a", "b", "c", "d"}},
+		{"pending", application.OrderQuery{Statuses: []domain.OrderStatus{domain.OrderStatusPending}}, []string{"a", "d"}},
+		{"currency", application.OrderQuery{Currency: "RUB"}, []string{"a", "b"}},
+		{"product", application.OrderQuery{ProductID: "mouse"}, []string{"b", "c"}},
+		{"total range", application.OrderQuery{MinTotal: &minTotal, MaxTotal: &maxTotal}, []string{"a", "c"}},
+		{"created range", application.OrderQuery{CreatedFrom: queryBaseTime.Add(time.Hour), CreatedTo: queryBaseTime.Add(3 * time.Hour)}, []string{"b", "c"}},
+		{"combined", application.OrderQuery{Statuses: []domain.OrderStatus{domain.OrderStatusPaid}, ProductID: "laptop"}, []string{"c"}},
+		{"sort by total desc", application.OrderQuery{SortBy: application.SortByTotal, Descending: true}, []string{"a", "c", "b", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := collectAll(t, repo, tt.query)
+			if fmt.Sprint(ids) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+// TestOrderQuery_IndexesFollowUpdates проверяет обновление индексов при сохранении
+func TestOrderQuery_IndexesFollowUpdates(t *testing.T) {
+	repo, _, useCase := setupTestEnvironment()
+	repo.Save(t.Context(), newQueryTestOrder("order-1", queryBaseTime, domain.OrderStatusPending, "RUB", map[string]int64{"laptop": 10000}))
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	pending := collectAll(t, repo, application.OrderQuery{Statuses: []domain.OrderStatus{domain.OrderStatusPending}})
+	paid := collectAll(t, repo, application.OrderQuery{Statuses: []domain.OrderStatus{domain.OrderStatusPaid}})
+	if len(pending) != 0 || len(paid) != 1 {
+		t.Errorf("expected order to move from PENDING to PAID index, got pending=%v paid=%v", pending, paid)
+	}
+}
+
+// TestOrderQuery_PaginationMatchesFullScan сравнивает постраничный обход
+// с полным перебором на случайных данных
+func TestOrderQuery_PaginationMatchesFullScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	repo := infrastructure.NewInMemoryOrderRepository()
+	statuses := []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusRefunded}
+	currencies := []string{"RUB", "USD"}
+	products := []string{"p1", "p2", "p3", "p4"}
+
+	var all []*domain.Order
+	for i := 0; i < 300; i++ {
+		lines := map[string]int64{}
+		for j := rng.Intn(3); j > 0; j-- {
+			lines[products[rng.Intn(len(products))]] = int64(rng.Intn(50)) * 100
+		}
+		order := newQueryTestOrder(fmt.Sprintf("order-%03d", i),
+			queryBaseTime.Add(time.Duration(rng.Intn(100))*time.Minute),
+			statuses[rng.Intn(len(statuses))], currencies[rng.Intn(len(currencies))], lines)
+		repo.Save(t.Context(), order)
+		all = append(all, order)
+	}
+
+	minTotal := int64(2000)
+	queries := []application.OrderQuery{
+		{Limit: 7},
+		{Limit: 7, SortBy: application.SortByCreatedAt},
+		{Limit: 11, SortBy: application.SortByTotal, Descending: true},
+		{Limit: 5, Statuses: []domain.OrderStatus{domain.OrderStatusPaid, domain.OrderStatusRefunded}, SortBy: application.SortByCreatedAt, Descending: true},
+		{Limit: 3, Currency: "USD", ProductID: "p2", MinTotal: &minTotal},
+		{Limit: 9, CreatedFrom: queryBaseTime.Add(20 * time.Minute), CreatedTo: queryBaseTime.Add(60 * time.Minute), SortBy: application.SortByTotal},
+	}
+
+	for i, query := range queries {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			normalized, _ := query.Normalize()
+			var expected []*domain.Order
+			for _, order := range all {
+				if normalized.Matches(order) {
+					expected = append(expected, order)
+				}
+			}
+			sort.Slice(expected, func(a, b int) bool {
+				ka := application.OrderSortKey(expected[a], normalized.SortBy)
+				kb := application.OrderSortKey(expected[b], normalized.SortBy)
+				if ka != kb {
+					return (ka < kb) != normalized.Descending
+				}
+				return (expected[a].ID() < expected[b].ID()) != normalized.Descending
+			})
+			expectedIDs := make([]string, 0, len(expected))
+			for _, order := range expected {
+				expectedIDs = append(expectedIDs, order.ID())
+			}
+
+			ids := collectAll(t, repo, query)
+			if fmt.Sprint(ids) != fmt.Sprint(expectedIDs) {
+				t.Errorf("expected %v, got %v", expectedIDs, ids)
+			}
+		})
+	}
+}
+
+// TestOrderQuery_InvalidQuery проверяет ошибки некорректных запросов
+func TestOrderQuery_InvalidQuery(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	minTotal, maxTotal := int64(10), int64(5)
+
+	queries := []application.OrderQuery{
+		{SortBy: "price"},
+		{Limit: -1},
+		{MinTotal: &minTotal, MaxTotal: &maxTotal},
+		{Cursor: "not a cursor"},
+		{Cursor: application.EncodeCursor(application.OrderCursor{SortBy: application.SortByTotal}), SortBy: application.SortByID},
+	}
+	for _, query := range queries {
+		if _, err := repo.Find(t.Context(), query); !errors.Is(err, application.ErrInvalidQuery) {
+			t.Errorf("expected ErrInvalidQuery for %+v, got: %v", query, err)
+		}
+	}
+}
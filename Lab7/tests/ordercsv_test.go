@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/ordercsv"
+	"strings"
+	"testing"
+)
+
+const csvHeader = "order_id,customer_id,status,currency,created_at,product_id,name,quantity,unit_price,line_total,vat_rate,weight_grams\n"
+
+// TestOrderCSV_RoundTrip проверяет, что выгруженные заказы загружаются
+// обратно без потерь
+func TestOrderCSV_RoundTrip(t *testing.T) {
+	source := infrastructure.NewInMemoryOrderRepository()
+	saveOrderWithLines(t, source, "order-1", stockLine{"product-1", 2}, stockLine{"product-2", 1})
+	saveOrderWithLines(t, source, "order-2")
+	heavy, _ := source.GetByID(t.Context(), "order-2")
+	price, _ := domain.NewMoney(2500, "USD")
+	line, _ := domain.RestoreOrderLine(domain.OrderLineSnapshot{ProductID: "kettle", Name: "Kettle", Price: price, Quantity: 2, WeightGrams: 1200, VATRate: domain.VAT10})
+	heavy.AddLine(line)
+	source.Save(t.Context(), heavy)
+	saveOrderWithLines(t, source, "order-4")
+	saveCustomerOrder(t, source, "order-3", "customer-1")
+	paid, _ := source.GetByID(t.Context(), "order-3")
+	paid.Pay()
+	source.Save(t.Context(), paid)
+
+	var buf bytes.Buffer
+	exported, err := ordercsv.Export(t.Context(), &buf, source, application.OrderQuery{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exported != 4 {
+		t.Errorf("expected 4 exported orders, got %d", exported)
+	}
+	if !strings.HasPrefix(buf.String(), csvHeader) {
+		t.Errorf("unexpected header: %q", strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+
+	target := infrastructure.NewInMemoryOrderRepository()
+	report, err := ordercsv.Import(t.Context(), &buf, target)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if report.Rows != 5 || report.Orders != 3 || len(report.Errors) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	// Оплаченный заказ выгружается, но без оплат обратно не загружается
+	if rowErr := report.Errors[0]; rowErr.OrderID != "order-3" || !errors.Is(rowErr, ordercsv.ErrInvalidRow) {
+		t.Errorf("expected paid order-3 to be rejected, got: %v", rowErr)
+	}
+	if _, err := target.GetByID(t.Context(), "order-3"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected order-3 not to be imported, got: %v", err)
+	}
+	for _, id := range []string{"order-1", "order-2", "order-4"} {
+		want, _ := source.GetByID(t.Context(), id)
+		got, err := target.GetByID(t.Context(), id)
+		if err != nil {
+			t.Fatalf("expected %s to be imported, got: %v", id, err)
+		}
+		if got.Status() != want.Status() || got.CustomerID() != want.CustomerID() || !got.CreatedAt().Equal(want.CreatedAt()) {
+			t.Errorf("%s: expected %s/%s/%v, got %s/%s/%v", id,
+				want.Status(), want.CustomerID(), want.CreatedAt(), got.Status(), got.CustomerID(), got.CreatedAt())
+		}
+		if len(got.Lines()) != len(want.Lines()) {
+			t.Fatalf("%s: expected %d lines, got %d", id, len(want.Lines()), len(got.Lines()))
+		}
+		for i, line := range got.Lines() {
+			if line.Snapshot() != want.Lines()[i].Snapshot() {
+				t.Errorf("%s line %d: expected %+v, got %+v", id, i, want.Lines()[i].Snapshot(), line.Snapshot())
+			}
+		}
+	}
+}
+
+// TestOrderCSV_RowErrors проверяет, что ошибка в строке пропускает только
+// её заказ
+func TestOrderCSV_RowErrors(t *testing.T) {
+	input := csvHeader +
+		"order-1,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,2,1000,2000,VAT20,15\n" +
+		"order-2,,PENDING,,2025-03-01T09:00:00Z,product-1,Pen,1,1000,,,\n" +
+		"order-3,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,0,1000,,,\n" +
+		"order-4,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,1,-5,,,\n" +
+		"order-5,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,1,1000,,,\n" +
+		"order-5,,PAID,RUB,2025-03-01T09:00:00Z,product-2,Cap,1,500,,,\n" +
+		"order-6,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,two,1000,,,\n" +
+		"order-7,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,3,1000,2000,,\n" +
+		"order-8,,REFUNDED,RUB,2025-03-01T09:00:00Z,product-1,Pen,1,1000,,,\n" +
+		"order-9,,EXPIRED,RUB,2025-03-01T09:00:00Z,product-1,Pen,1,1000,,,\n"
+
+	repo := infrastructure.NewInMemoryOrderRepository()
+	report, err := ordercsv.Import(t.Context(), strings.NewReader(input), repo)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if report.Rows != 10 || report.Orders != 2 {
+		t.Errorf("expected 10 rows and 2 orders, got %+v", report)
+	}
+
+	want := []struct {
+		row     int
+		orderID string
+		err     error
+	}{
+		{3, "order-2", domain.ErrEmptyCurrency},
+		{4, "order-3", domain.ErrNonPositiveQuantity},
+		{5, "order-4", domain.ErrNegativeAmount},
+		{7, "order-5", ordercsv.ErrInvalidRow},
+		{8, "order-6", ordercsv.ErrInvalidRow},
+		{9, "order-7", ordercsv.ErrInvalidRow},
+		{10, "order-8", ordercsv.ErrInvalidRow},
+	}
+	if len(report.Errors) != len(want) {
+		t.Fatalf("expected %d row errors, got: %v", len(want), report.Errors)
+	}
+	for i, w := range want {
+		got := report.Errors[i]
+		if got.Row != w.row || got.OrderID != w.orderID || !errors.Is(got, w.err) {
+			t.Errorf("error %d: expected row %d of %s with %v, got: %v", i, w.row, w.orderID, w.err, got)
+		}
+	}
+
+	order, err := repo.GetByID(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected order-1 to be imported, got: %v", err)
+	}
+	line := order.Lines()[0]
+	if line.Name() != "Pen" || line.VATRate() != domain.VAT20 || line.WeightGrams() != 30 {
+		t.Errorf("unexpected line: %+v", line.Snapshot())
+	}
+	if _, err := repo.GetByID(t.Context(), "order-5"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected order-5 to be skipped, got: %v", err)
+	}
+}
+
+// TestOrderCSV_DryRun проверяет, что пробный прогон ничего не сохраняет
+func TestOrderCSV_DryRun(t *testing.T) {
+	input := csvHeader +
+		"order-1,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,2,1000,2000,,\n" +
+		"order-2,,PENDING,RUB,2025-03-01T09:00:00Z,product-1,Pen,1,oops,,,\n"
+
+	repo := infrastructure.NewInMemoryOrderRepository()
+	report, err := ordercsv.Import(t.Context(), strings.NewReader(input), repo, ordercsv.DryRun())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if report.Orders != 1 || len(report.Errors) != 1 {
+		t.Errorf("expected 1 valid order and 1 error, got %+v", report)
+	}
+	if _, err := repo.GetByID(t.Context(), "order-1"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected nothing to be saved, got: %v", err)
+	}
+}
+
+// TestOrderCSV_ExistingOrder проверяет, что загрузка не перезаписывает
+// существующий заказ
+func TestOrderCSV_ExistingOrder(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+
+	input := csvHeader + "order-1,,PENDING,RUB,2025-03-01T09:00:00Z,product-9,Pen,5,1000,,,\n"
+	report, err := ordercsv.Import(t.Context(), strings.NewReader(input), repo)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if report.Orders != 0 || len(report.Errors) != 1 || !errors.Is(report.Errors[0], application.ErrOrderAlreadyExists) {
+		t.Fatalf("expected an already-exists error, got %+v", report)
+	}
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if order.Lines()[0].ProductID() != "product-1" {
+		t.Errorf("expected order-1 to be left intact, got %+v", order.Lines()[0].Snapshot())
+	}
+}
+
+// TestOrderCSV_MissingColumn проверяет отказ загружать файл с неполным
+// заголовком
+func TestOrderCSV_MissingColumn(t *testing.T) {
+	input := "order_id,status,currency\norder-1,PENDING,RUB\n"
+	_, err := ordercsv.Import(t.Context(), strings.NewReader(input), infrastructure.NewInMemoryOrderRepository())
+	if !errors.Is(err, ordercsv.ErrMissingColumn) {
+		t.Errorf("expected ErrMissingColumn, got: %v", err)
+	}
+}
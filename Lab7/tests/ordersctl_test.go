@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/cli"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// runCLI запускает ordersctl с файлом данных dataPath
+func runCLI(t *testing.T, dataPath string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(append([]string{"-data", dataPath}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestOrdersctl_FullLifecycle проверяет создание, оплату и возврат заказа через CLI
+func TestOrdersctl_FullLifecycle(t *testing.T) {
+	dataPath := filepath.Join(t.TempDir(), "orders.json")
+
+	steps := [][]string{
+		{"create", "order-1"},
+		{"add-line", "order-1", "-product", "laptop", "-price", "15000", "-currency", "RUB", "-qty", "2"},
+		{"pay", "order-1"},
+		{"refund", "order-1"},
+	}
+	for _, step := range steps {
+		if code, _, stderr := runCLI(t, dataPath, step...); code != cli.ExitOK {
+			t.Fatalf("%v: expected exit code 0, got %d (%s)", step, code, stderr)
+		}
+	}
+
+	code, stdout, _ := runCLI(t, dataPath, "-output", "json", "show", "order-1")
+	if code != cli.ExitOK {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+
+	var view application.OrderView
+	if err := json.Unmarshal([]byte(stdout), &view); err != nil {
+		t.Fatalf("expected JSON output, got: %v", err)
+	}
+	if view.Status != "REFUNDED" || view.Total != 30000 || view.Currency != "RUB" {
+		t.Errorf("unexpected order view: %+v", view)
+	}
+}
+
+// TestOrdersctl_ExitCodes проверяет коды завершения для разных типов ошибок
+func TestOrdersctl_ExitCodes(t *testing.T) {
+	dataPath := filepath.Join(t.TempDir(), "orders.json")
+	runCLI(t, dataPath, "create", "order-1")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, cli.ExitUsage},
+		{"unknown command", []string{"ship", "order-1"}, cli.ExitUsage},
+		{"missing order id", []string{"pay"}, cli.ExitUsage},
+		{"not found", []string{"show", "order-404"}, cli.ExitNotFound},
+		{"duplicate", []string{"create", "order-1"}, cli.ExitConflict},
+		{"invalid quantity", []string{"add-line", "order-1", "-product", "p", "-price", "100", "-currency", "RUB", "-qty", "0"}, cli.ExitInvalidInput},
+		{"empty order", []string{"pay", "order-1"}, cli.ExitRuleViolation},
+		{"refund unpaid", []string{"refund", "order-1"}, cli.ExitRuleViolation},
+		{"unknown status", []string{"list", "-status", "shipped"}, cli.ExitInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, _ := runCLI(t, dataPath, tt.args...); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+// TestExitCode_Errors проверяет коды завершения для ошибок, которые не
+// воспроизводятся командами ordersctl
+func TestExitCode_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{application.ErrCustomerNotFound, cli.ExitNotFound},
+		{domain.ErrCustomerBlocked, cli.ExitRuleViolation},
+		{application.ErrInsufficientStock, cli.ExitRuleViolation},
+		{application.ErrGiftCardsNotAccepted, cli.ExitRuleViolation},
+		{domain.ErrEmptyCustomerID, cli.ExitInvalidInput},
+		{domain.ErrInvalidContact, cli.ExitInvalidInput},
+		{domain.ErrUnknownCustomerStatus, cli.ExitInvalidInput},
+		{application.ErrGatewayUnavailable, cli.ExitPaymentFailed},
+		{application.ErrReservationNotFound, cli.ExitFailure},
+		{domain.ErrEmptyProductName, cli.ExitInvalidInput},
+		{domain.ErrNegativeWeight, cli.ExitInvalidInput},
+		{domain.ErrDeliveryNotSet, cli.ExitRuleViolation},
+		{domain.ErrEmptyGiftCardID, cli.ExitInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if code := cli.ExitCode(fmt.Errorf("create order: %w", tt.err)); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+// TestOrdersctl_DeclinedPayment проверяет код завершения при отказе шлюза
+func TestOrdersctl_DeclinedPayment(t *testing.T) {
+	dataPath := filepath.Join(t.TempDir(), "orders.json")
+	runCLI(t, dataPath, "create", "order-1")
+	runCLI(t, dataPath, "add-line", "order-1", "-product", "p", "-price", "100", "-currency", "RUB")
+
+	code, _, stderr := runCLI(t, dataPath, "-gateway", "decline", "-decline-reason", "card expired", "pay", "order-1")
+	if code != cli.ExitPaymentFailed {
+		t.Fatalf("expected exit code %d, got %d", cli.ExitPaymentFailed, code)
+	}
+	if !bytes.Contains([]byte(stderr), []byte("card expired")) {
+		t.Errorf("expected decline reason in stderr, got: %q", stderr)
+	}
+
+	// Заказ должен остаться неоплаченным
+	_, stdout, _ := runCLI(t, dataPath, "-output", "json", "show", "order-1")
+	var view application.OrderView
+	json.Unmarshal([]byte(stdout), &view)
+	if view.Status != "PENDING" {
+		t.Errorf("expected PENDING order after declined payment, got %s", view.Status)
+	}
+}
+
+// TestFileOrderRepository_Persistence проверяет, что заказы переживают переоткрытие файла
+func TestFileOrderRepository_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+
+	repo, err := infrastructure.NewFileOrderRepository(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	order := domain.NewOrder("order-1")
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 3)
+	order.AddLine(line)
+	order.Pay()
+	if err := repo.Save(t.Context(), order); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	reopened, err := infrastructure.NewFileOrderRepository(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	loaded, err := reopened.GetByID(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !loaded.IsPaid() {
+		t.Error("expected order to stay paid after reopening")
+	}
+	total, _ := loaded.Total()
+	expected, _ := domain.NewMoney(30000, "RUB")
+	if !total.Equals(expected) {
+		t.Errorf("expected total %s, got %s", expected.String(), total.String())
+	}
+}
+
+// TestFileOrderRepository_FlushFailure проверяет, что заказ, который не
+// удалось записать на диск, не остаётся изменённым в памяти
+func TestFileOrderRepository_FlushFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	repo, err := infrastructure.NewFileOrderRepository(filepath.Join(dir, "orders.json"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := repo.Save(t.Context(), domain.NewOrder("order-1")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Без каталога файл не записать
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 1)
+	order.AddLine(line)
+	if err := repo.Save(t.Context(), order); err == nil {
+		t.Fatal("expected save to fail")
+	}
+	err = repo.Update(t.Context(), "order-1", func(order *domain.Order) error {
+		return order.AddLine(line)
+	})
+	if err == nil {
+		t.Fatal("expected update to fail")
+	}
+	if err := repo.Save(t.Context(), domain.NewOrder("order-2")); err == nil {
+		t.Fatal("expected save of a new order to fail")
+	}
+
+	stored, err := repo.GetByID(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(stored.Lines()) != 0 {
+		t.Errorf("expected order-1 to keep its saved version, got %d lines", len(stored.Lines()))
+	}
+	if _, err := repo.GetByID(t.Context(), "order-2"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected order-2 not to be stored, got: %v", err)
+	}
+	page, _ := repo.Find(t.Context(), application.OrderQuery{})
+	if len(page.Orders) != 1 {
+		t.Errorf("expected one order in search results, got %d", len(page.Orders))
+	}
+}
+
+// TestRefundOrder_GatewayFailure проверяет, что неудачный возврат не меняет заказ
+func TestRefundOrder_GatewayFailure(t *testing.T) {
+	repo, gateway, payUseCase := setupTestEnvironment()
+	refundUseCase := application.NewRefundOrderUseCase(repo, gateway)
+
+	order := domain.NewOrder("order-1")
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 1)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+	payUseCase.Execute(t.Context(), "order-1")
+
+	gateway.SetShouldFail(true, "gateway unavailable")
+	result, err := refundUseCase.Execute(t.Context(), "order-1")
+	if err == nil || result.Success {
+		t.Fatal("expected refund to fail")
+	}
+
+	stored, _ := repo.GetByID(t.Context(), "order-1")
+	if stored.Status() != domain.OrderStatusPaid {
+		t.Errorf("expected order to stay PAID, got %s", stored.Status())
+	}
+	if len(gateway.GetRefunds()) != 0 {
+		t.Errorf("expected no refunds, got %d", len(gateway.GetRefunds()))
+	}
+}
@@ -30,10 +30,10 @@ func TestPayOrder_Success(t *testing.T) {
 	order.AddLine(line2)
 
 	// Сохраняем заказ
-	repo.Save(order)
+	repo.Save(t.Context(), order)
 
 	// Выполняем оплату
-	result, err := useCase.Execute("order-1")
+	result, err := useCase.Execute(t.Context(), "order-1")
 
 	// Проверяем результат
 	if err != nil {
@@ -44,7 +44,7 @@ func TestPayOrder_Success(t *testing.T) {
 	}
 
 	// Проверяем, что заказ оплачен
-	updatedOrder, _ := repo.GetByID("order-1")
+	updatedOrder, _ := repo.GetByID(t.Context(), "order-1")
 	if !updatedOrder.IsPaid() {
 		t.Error("expected order to be paid")
 	}
@@ -67,10 +67,10 @@ func TestPayOrder_EmptyOrder(t *testing.T) {
 
 	// Создаём пустой заказ
 	order := domain.NewOrder("order-2")
-	repo.Save(order)
+	repo.Save(t.Context(), order)
 
 	// Пытаемся оплатить
-	result, err := useCase.Execute("order-2")
+	result, err := useCase.Execute(t.Context(), "order-2")
 
 	// Ожидаем ошибку
 	if err == nil {
@@ -91,10 +91,10 @@ func TestPayOrder_AlreadyPaid(t *testing.T) {
 	line, _ := domain.NewOrderLine("product-1", money, 1)
 	order.AddLine(line)
 	order.Pay() // Оплачиваем
-	repo.Save(order)
+	repo.Save(t.Context(), order)
 
 	// Пытаемся оплатить повторно
-	result, err := useCase.Execute("order-3")
+	result, err := useCase.Execute(t.Context(), "order-3")
 
 	// Ожидаем ошибку
 	if err == nil {
@@ -182,13 +182,13 @@ func TestPayOrder_PaymentGatewayFailure(t *testing.T) {
 	money, _ := domain.NewMoney(10000, "RUB")
 	line, _ := domain.NewOrderLine("product-1", money, 1)
 	order.AddLine(line)
-	repo.Save(order)
+	repo.Save(t.Context(), order)
 
 	// Настраиваем шлюз на ошибку
 	gateway.SetShouldFail(true, "insufficient funds")
 
 	// Пытаемся оплатить
-	result, err := useCase.Execute("order-6")
+	result, err := useCase.Execute(t.Context(), "order-6")
 
 	// Ожидаем ошибку
 	if err == nil {
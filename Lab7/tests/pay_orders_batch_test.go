@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPayOrdersBatch_Report проверяет исходы по заказам и сводный отчёт
+func TestPayOrdersBatch_Report(t *testing.T) {
+	repo, gateway, payOrder := setupTestEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "order-2", stockLine{"product-1", 2})
+	saveOrderWithLines(t, repo, "declined", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "outage", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "paid-before", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "empty")
+	usd := domain.NewOrder("order-usd")
+	price, _ := domain.NewMoney(500, "USD")
+	line, _ := domain.NewOrderLine("product-2", price, 1)
+	usd.AddLine(line)
+	repo.Save(t.Context(), usd)
+	if _, err := payOrder.Execute(t.Context(), "paid-before"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	gateway.SetScenarios(
+		infrastructure.FaultScenario{Name: "declined", OrderIDs: []string{"declined"}, Fault: infrastructure.FaultPermanent, Reason: "card declined"},
+		infrastructure.FaultScenario{Name: "outage", OrderIDs: []string{"outage"}, Fault: infrastructure.FaultTransient})
+
+	batch := application.NewPayOrdersBatchUseCase(payOrder, application.BatchWorkers(3))
+	result, err := batch.Execute(t.Context(), []string{
+		"order-1", "order-2", "order-1", "declined", "outage", "paid-before", "empty", "missing", "order-usd", "order-2",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	want := map[string]application.BatchOutcome{
+		"order-1":     application.BatchPaid,
+		"order-2":     application.BatchPaid,
+		"declined":    application.BatchDeclined,
+		"outage":      application.BatchRetryable,
+		"paid-before": application.BatchAlreadyPaid,
+		"empty":       application.BatchFailed,
+		"missing":     application.BatchFailed,
+		"order-usd":   application.BatchPaid,
+	}
+	if len(result.Payments) != len(want) || result.Duplicates != 2 {
+		t.Fatalf("expected %d payments and 2 duplicates, got %d and %d", len(want), len(result.Payments), result.Duplicates)
+	}
+	for _, payment := range result.Payments {
+		if payment.Outcome != want[payment.OrderID] {
+			t.Errorf("%s: expected %s, got %s (%v)", payment.OrderID, want[payment.OrderID], payment.Outcome, payment.Err)
+		}
+	}
+	if result.Payments[0].OrderID != "order-1" || result.Payments[7].OrderID != "order-usd" {
+		t.Errorf("expected payments in input order, got %+v", result.Payments)
+	}
+	if result.Counts[application.BatchPaid] != 3 || result.Counts[application.BatchFailed] != 2 {
+		t.Errorf("unexpected counts %v", result.Counts)
+	}
+	if result.Charged["RUB"] != 3000 || result.Charged["USD"] != 500 {
+		t.Errorf("expected 3000 RUB and 500 USD charged, got %v", result.Charged)
+	}
+
+	// Каждый заказ списан один раз, несмотря на повторы в пакете
+	charged := make(map[string]int)
+	for _, p := range gateway.GetPayments() {
+		charged[p.OrderID]++
+	}
+	for orderID, n := range charged {
+		if n != 1 {
+			t.Errorf("%s: expected one charge, got %d", orderID, n)
+		}
+	}
+}
+
+// concurrencyProbe - PayOrderExecutor, который замеряет число
+// одновременных вызовов
+type concurrencyProbe struct {
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	calls   map[string]int
+	onCall  func(n int)
+}
+
+func (p *concurrencyProbe) ExecuteCommand(ctx context.Context, cmd application.PayOrderCommand) (application.PayOrderResult, error) {
+	return p.Execute(ctx, cmd.OrderID)
+}
+
+func (p *concurrencyProbe) Execute(ctx context.Context, orderID string) (application.PayOrderResult, error) {
+	p.mu.Lock()
+	p.active++
+	p.maxSeen = max(p.maxSeen, p.active)
+	p.calls[orderID]++
+	n := len(p.calls)
+	p.mu.Unlock()
+	if p.onCall != nil {
+		p.onCall(n)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return application.PayOrderResult{}, err
+	}
+	return application.PayOrderResult{Success: true, Amount: 100, Currency: "RUB"}, nil
+}
+
+// orderIDs возвращает идентификаторы order-1 ... order-n
+func orderIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("order-%d", i+1)
+	}
+	return ids
+}
+
+// TestPayOrdersBatch_WorkerLimit проверяет ограничение числа одновременных оплат
+func TestPayOrdersBatch_WorkerLimit(t *testing.T) {
+	probe := &concurrencyProbe{calls: make(map[string]int)}
+	batch := application.NewPayOrdersBatchUseCase(probe, application.BatchWorkers(3))
+
+	result, err := batch.Execute(t.Context(), orderIDs(30))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if probe.maxSeen > 3 {
+		t.Errorf("expected at most 3 concurrent payments, got %d", probe.maxSeen)
+	}
+	if len(probe.calls) != 30 || result.Counts[application.BatchPaid] != 30 || result.Charged["RUB"] != 3000 {
+		t.Errorf("expected 30 paid orders, got %d calls and %v", len(probe.calls), result.Counts)
+	}
+}
+
+// TestPayOrdersBatch_Cancel проверяет, что после отмены контекста новые
+// заказы не начинаются, а в отчёте получают исход canceled
+func TestPayOrdersBatch_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	probe := &concurrencyProbe{calls: make(map[string]int), onCall: func(n int) {
+		if n == 4 {
+			cancel()
+		}
+	}}
+	batch := application.NewPayOrdersBatchUseCase(probe, application.BatchWorkers(2))
+
+	result, err := batch.Execute(ctx, orderIDs(50))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+	if len(probe.calls) >= 50 {
+		t.Errorf("expected cancellation to stop the batch, got %d calls", len(probe.calls))
+	}
+	if len(result.Payments) != 50 {
+		t.Fatalf("expected an outcome for every order, got %d", len(result.Payments))
+	}
+	if result.Counts[application.BatchCanceled] == 0 || result.Counts[application.BatchPaid]+result.Counts[application.BatchCanceled] != 50 {
+		t.Errorf("unexpected counts %v", result.Counts)
+	}
+	for _, payment := range result.Payments {
+		if payment.Outcome == application.BatchCanceled && probe.calls[payment.OrderID] == 0 && !errors.Is(payment.Err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", payment.OrderID, payment.Err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/cli"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupCatalogEnvironment создаёт use-case добавления строк с каталогом
+func setupCatalogEnvironment(t *testing.T) (*infrastructure.InMemoryOrderRepository, *infrastructure.InMemoryProductCatalog, *application.AddOrderLineUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryOrderRepository()
+	catalog := infrastructure.NewInMemoryProductCatalog()
+	price, _ := domain.NewMoney(15000, "RUB")
+	product, err := domain.NewProduct("laptop", "Laptop Pro", price)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	catalog.Save(t.Context(), product)
+	repo.Save(t.Context(), domain.NewOrder("order-1"))
+	return repo, catalog, application.NewAddOrderLineUseCase(repo, application.WithCatalog(catalog))
+}
+
+// TestAddOrderLine_PriceFromCatalog проверяет, что цена строки берётся из каталога
+func TestAddOrderLine_PriceFromCatalog(t *testing.T) {
+	_, _, useCase := setupCatalogEnvironment(t)
+
+	// Цена из команды игнорируется
+	view, err := useCase.Execute(t.Context(), application.AddOrderLineCommand{
+		OrderID: "order-1", ProductID: "laptop", UnitPrice: 1, Currency: "USD", Quantity: 2,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	line := view.Lines[0]
+	if line.UnitPrice != 15000 || line.Currency != "RUB" || line.Name != "Laptop Pro" || line.Total != 30000 {
+		t.Errorf("expected catalog price and name, got %+v", line)
+	}
+}
+
+// TestAddOrderLine_CatalogRejections проверяет отказ для неизвестных и снятых с продажи товаров
+func TestAddOrderLine_CatalogRejections(t *testing.T) {
+	_, catalog, useCase := setupCatalogEnvironment(t)
+
+	_, err := useCase.Execute(t.Context(), application.AddOrderLineCommand{OrderID: "order-1", ProductID: "phone", Quantity: 1})
+	if !errors.Is(err, application.ErrProductNotFound) {
+		t.Errorf("expected ErrProductNotFound, got: %v", err)
+	}
+
+	product, _ := catalog.GetByID(t.Context(), "laptop")
+	product.Deactivate()
+	catalog.Save(t.Context(), product)
+	_, err = useCase.Execute(t.Context(), application.AddOrderLineCommand{OrderID: "order-1", ProductID: "laptop", Quantity: 1})
+	if !errors.Is(err, domain.ErrProductInactive) {
+		t.Errorf("expected ErrProductInactive, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeProductInactive {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeProductInactive, application.ErrorCode(err))
+	}
+}
+
+// TestAddOrderLine_SnapshotSurvivesPriceChange проверяет, что строка хранит цену на момент добавления
+func TestAddOrderLine_SnapshotSurvivesPriceChange(t *testing.T) {
+	repo, catalog, useCase := setupCatalogEnvironment(t)
+	useCase.Execute(t.Context(), application.AddOrderLineCommand{OrderID: "order-1", ProductID: "laptop", Quantity: 1})
+
+	product, _ := catalog.GetByID(t.Context(), "laptop")
+	newPrice, _ := domain.NewMoney(20000, "RUB")
+	product.ChangePrice(newPrice)
+	catalog.Save(t.Context(), product)
+
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	total, _ := order.Total()
+	if total.Amount() != 15000 || order.Lines()[0].Name() != "Laptop Pro" {
+		t.Errorf("expected line to keep 150.00 RUB and name, got %s %q", total, order.Lines()[0].Name())
+	}
+}
+
+// TestOrdersctl_Catalog проверяет добавление строк из файла каталога через CLI
+func TestOrdersctl_Catalog(t *testing.T) {
+	dir := t.TempDir()
+	dataPath := filepath.Join(dir, "orders.json")
+	catalogPath := filepath.Join(dir, "catalog.json")
+	catalogJSON := `{"products":[
+		{"id":"laptop","name":"Laptop Pro","price":15000,"currency":"RUB"},
+		{"id":"fax","name":"Fax machine","price":500,"currency":"RUB","active":false}
+	]}`
+	if err := os.WriteFile(catalogPath, []byte(catalogJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCLI(t, dataPath, "create", "order-1")
+	if code, _, stderr := runCLI(t, dataPath, "-catalog", catalogPath, "add-line", "order-1", "-product", "laptop", "-qty", "2"); code != cli.ExitOK {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr)
+	}
+	if code, _, _ := runCLI(t, dataPath, "-catalog", catalogPath, "add-line", "order-1", "-product", "fax"); code != cli.ExitRuleViolation {
+		t.Errorf("expected exit code %d for inactive product, got %d", cli.ExitRuleViolation, code)
+	}
+	if code, _, _ := runCLI(t, dataPath, "-catalog", catalogPath, "add-line", "order-1", "-product", "phone"); code != cli.ExitNotFound {
+		t.Errorf("expected exit code %d for unknown product, got %d", cli.ExitNotFound, code)
+	}
+
+	// Название и цена переживают перезагрузку файла заказов
+	repo, _ := infrastructure.NewFileOrderRepository(dataPath)
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	lines := order.Lines()
+	if len(lines) != 1 || lines[0].Name() != "Laptop Pro" || lines[0].Price().Amount() != 15000 {
+		t.Errorf("unexpected lines after reload: %+v", lines)
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/receipt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// receiptNow - время выдачи чеков в тестах
+var receiptNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// setupReceiptEnvironment создаёт оплаченный двумя способами заказ с
+// товарами по разным ставкам НДС и use-case получения чека
+func setupReceiptEnvironment(t *testing.T) (*infrastructure.InMemoryOrderRepository, *application.GetReceiptUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryOrderRepository()
+	order := domain.NewOrder("order-1")
+	for _, p := range []struct {
+		id, name string
+		price    int64
+		quantity int
+		vat      domain.VATRate
+	}{
+		{"book", "Book <Go>", 33000, 1, domain.VAT10},
+		{"lamp", "Lamp", 12000, 2, domain.VAT20},
+		{"cable", "Cable", 5000, 1, domain.VAT20},
+	} {
+		product, _ := domain.NewProduct(p.id, p.name, rub(p.price))
+		if err := product.SetVATRate(p.vat); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		line, _ := domain.NewOrderLineFromProduct(product, p.quantity)
+		order.AddLine(line)
+	}
+
+	card, _ := domain.NewCardToken("tok_visa_4242")
+	wallet, _ := domain.NewWallet("wallet-77")
+	err := order.PayWith([]domain.PaymentLeg{
+		{Method: card, Amount: rub(50000)},
+		{Method: wallet, Amount: rub(12000)},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	repo.Save(t.Context(), order)
+	return repo, application.NewGetReceiptUseCase(repo, application.ReceiptClock(func() time.Time { return receiptNow }))
+}
+
+// TestVATRate_Included проверяет выделение НДС из цены с округлением
+func TestVATRate_Included(t *testing.T) {
+	tests := []struct {
+		rate  domain.VATRate
+		gross int64
+		want  int64
+	}{
+		{domain.VAT20, 12000, 2000},
+		{domain.VAT20, 100, 17}, // 16.67 -> 17
+		{domain.VAT10, 33000, 3000},
+		{domain.VAT10, 5, 0}, // 0.45 -> 0
+		{domain.VAT0, 12000, 0},
+		{domain.VATNone, 12000, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Included(rub(tt.gross)); got.Amount() != tt.want {
+			t.Errorf("%s of %d: expected %d, got %d", tt.rate, tt.gross, tt.want, got.Amount())
+		}
+	}
+
+	if _, err := domain.ParseVATRate("VAT18"); !errors.Is(err, domain.ErrUnknownVATRate) {
+		t.Errorf("expected ErrUnknownVATRate, got: %v", err)
+	}
+}
+
+// TestGetReceipt_Totals проверяет строки, итоги, налоги по ставкам и оплату в чеке
+func TestGetReceipt_Totals(t *testing.T) {
+	_, useCase := setupReceiptEnvironment(t)
+
+	r, err := useCase.Execute(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(r.Lines) != 3 || r.Total != 62000 || r.Currency != "RUB" || !r.IssuedAt.Equal(receiptNow) {
+		t.Fatalf("unexpected receipt: %+v", r)
+	}
+	want := []application.ReceiptTax{
+		{Rate: "VAT10", Base: 33000, Amount: 3000},
+		{Rate: "VAT20", Base: 29000, Amount: 4833},
+	}
+	if len(r.Taxes) != len(want) || r.Taxes[0] != want[0] || r.Taxes[1] != want[1] {
+		t.Errorf("expected taxes %+v, got %+v", want, r.Taxes)
+	}
+	if len(r.Payments) != 2 || r.Payments[0].Reference != "CARD *4242" || r.Payments[1].Amount != 12000 {
+		t.Errorf("unexpected payments: %+v", r.Payments)
+	}
+}
+
+// TestGetReceipt_PendingOrder проверяет, что чек не выдаётся по неоплаченному заказу
+func TestGetReceipt_PendingOrder(t *testing.T) {
+	repo, useCase := setupReceiptEnvironment(t)
+	saveOrderWithLines(t, repo, "order-2", stockLine{"product-1", 1})
+
+	_, err := useCase.Execute(t.Context(), "order-2")
+	if !errors.Is(err, domain.ErrOrderNotPaid) {
+		t.Errorf("expected ErrOrderNotPaid, got: %v", err)
+	}
+	_, err = useCase.Execute(t.Context(), "order-missing")
+	if !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got: %v", err)
+	}
+}
+
+// TestReceiptOutputs проверяет вывод чека во всех форматах
+func TestReceiptOutputs(t *testing.T) {
+	_, useCase := setupReceiptEnvironment(t)
+	r, err := useCase.Execute(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		output func(receipt.Device) receipt.Output
+		want   []string
+	}{
+		{"text", func(d receipt.Device) receipt.Output { return receipt.NewTextOutput(d) },
+			[]string{"Receipt:  order-1", "Book <Go>", "Total:", "620.00 RUB", "incl. VAT20:", "48.33 RUB", "Paid CARD *4242:"}},
+		{"html", func(d receipt.Device) receipt.Output { return receipt.NewHTMLOutput(d) },
+			[]string{"<h1>Receipt order-1</h1>", "Book &lt;Go&gt;", "620.00 RUB", "Paid CARD *4242: 500.00 RUB"}},
+		{"json", func(d receipt.Device) receipt.Output { return receipt.NewJSONOutput(d) },
+			[]string{`"order_id": "order-1"`, `"vat_rate": "VAT10"`, `"reference": "WALLET *t-77"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := receipt.NewBufferDevice()
+			if err := tt.output(device).Render(r); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(device.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, device.String())
+				}
+			}
+		})
+	}
+}
+
+// TestReceiptOutputs_FileDevice проверяет, что формат и устройство независимы:
+// JSON-чек, сохранённый в файл, читается обратно без потерь
+func TestReceiptOutputs_FileDevice(t *testing.T) {
+	_, useCase := setupReceiptEnvironment(t)
+	r, _ := useCase.Execute(t.Context(), "order-1")
+
+	path := filepath.Join(t.TempDir(), "receipt.json")
+	if err := receipt.NewJSONOutput(receipt.NewFileDevice(path)).Render(r); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var got application.Receipt
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected valid JSON, got: %v", err)
+	}
+	if got.Total != r.Total || len(got.Lines) != len(r.Lines) || len(got.Taxes) != len(r.Taxes) {
+		t.Errorf("expected %+v, got %+v", r, got)
+	}
+
+	failing := receipt.NewFileDevice(filepath.Join(t.TempDir(), "missing", "receipt.txt"))
+	if err := receipt.NewTextOutput(failing).Render(r); err == nil {
+		t.Error("expected error for unwritable file")
+	}
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+	"time"
+)
+
+// failingSaveRepository - хранилище, в котором Save отказывает, пока
+// установлен err
+type failingSaveRepository struct {
+	*infrastructure.InMemoryOrderRepository
+	err error
+}
+
+func (r *failingSaveRepository) Save(ctx context.Context, order *domain.Order) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.InMemoryOrderRepository.Save(ctx, order)
+}
+
+// reconcileEnvironment создаёт хранилище и шлюз с часами на час раньше
+// часов сверки, чтобы все операции считались завершёнными
+func reconcileEnvironment() (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, func() time.Time) {
+	at := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	gateway := infrastructure.NewFakePaymentGateway()
+	gateway.SetClock(func() time.Time { return at })
+	return infrastructure.NewInMemoryOrderRepository(), gateway, func() time.Time { return at.Add(time.Hour) }
+}
+
+// TestReconcilePayments_ReportsDiscrepancies проверяет все виды расхождений
+func TestReconcilePayments_ReportsDiscrepancies(t *testing.T) {
+	repo, gateway, clock := reconcileEnvironment()
+	payOrder := application.NewPayOrderUseCase(repo, gateway)
+	refund := application.NewRefundOrderUseCase(repo, gateway)
+	ctx := t.Context()
+
+	// Без расхождений: оплаченный и возвращённый заказы
+	saveOrderWithLines(t, repo, "clean-paid", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "clean-refunded", stockLine{"product-1", 1})
+	payOrder.Execute(ctx, "clean-paid")
+	payOrder.Execute(ctx, "clean-refunded")
+	refund.Execute(ctx, "clean-refunded")
+
+	// Заказ отмечен оплаченным в обход шлюза
+	saveOrderWithLines(t, repo, "not-charged", stockLine{"product-1", 2})
+	order, _ := repo.GetByID(ctx, "not-charged")
+	order.Pay()
+	repo.Save(ctx, order)
+
+	// Списание прошло, а процесс оплаты упал, не сохранив заказ
+	saveOrderWithLines(t, repo, "pending", stockLine{"product-1", 3})
+	gateway.Charge(ctx, "pending", domain.PaymentMethod{}, rub(3000))
+
+	// Повторное списание и списание не той суммы
+	saveOrderWithLines(t, repo, "duplicate", stockLine{"product-1", 1})
+	saveOrderWithLines(t, repo, "mismatch", stockLine{"product-1", 1})
+	payOrder.Execute(ctx, "duplicate")
+	payOrder.Execute(ctx, "mismatch")
+	gateway.Charge(ctx, "duplicate", domain.PaymentMethod{}, rub(1000))
+	gateway.Charge(ctx, "mismatch", domain.PaymentMethod{}, rub(1))
+
+	// Списание за заказ, которого нет
+	gateway.Charge(ctx, "ghost", domain.PaymentMethod{}, rub(500))
+
+	result, err := application.NewReconcilePaymentsUseCase(repo, gateway, application.ReconcileClock(clock)).Execute(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Orders != 7 {
+		t.Errorf("expected 7 reconciled orders, got %d", result.Orders)
+	}
+
+	want := map[string]struct {
+		kind              application.DiscrepancyKind
+		expected, charged int64
+	}{
+		"not-charged": {application.DiscrepancyPaidNotCharged, 2000, 0},
+		"pending":     {application.DiscrepancyChargedPending, 0, 3000},
+		"duplicate":   {application.DiscrepancyDuplicateCharge, 1000, 2000},
+		"mismatch":    {application.DiscrepancyAmountMismatch, 1000, 1001},
+		"ghost":       {application.DiscrepancyUnknownOrder, 0, 500},
+	}
+	if len(result.Discrepancies) != len(want) {
+		t.Fatalf("expected %d discrepancies, got: %+v", len(want), result.Discrepancies)
+	}
+	for _, d := range result.Discrepancies {
+		w, ok := want[d.OrderID]
+		if !ok {
+			t.Errorf("unexpected discrepancy: %+v", d)
+			continue
+		}
+		if d.Kind != w.kind || d.Expected != w.expected || d.Charged != w.charged || d.Currency != "RUB" {
+			t.Errorf("%s: expected %s %d/%d RUB, got %s %d/%d %s", d.OrderID, w.kind, w.expected, w.charged, d.Kind, d.Expected, d.Charged, d.Currency)
+		}
+		if d.Repaired {
+			t.Errorf("%s: expected no repair without AutoRepair", d.OrderID)
+		}
+	}
+	if result.Count(application.DiscrepancyDuplicateCharge) != 1 {
+		t.Errorf("expected 1 duplicate charge, got %d", result.Count(application.DiscrepancyDuplicateCharge))
+	}
+}
+
+// TestReconcilePayments_AutoRepair проверяет исправление безопасных расхождений
+func TestReconcilePayments_AutoRepair(t *testing.T) {
+	repo, gateway, clock := reconcileEnvironment()
+	ctx := t.Context()
+
+	saveOrderWithLines(t, repo, "pending", stockLine{"product-1", 3})
+	gateway.Charge(ctx, "pending", domain.PaymentMethod{}, rub(3000))
+
+	saveOrderWithLines(t, repo, "duplicate", stockLine{"product-1", 1})
+	application.NewPayOrderUseCase(repo, gateway).Execute(ctx, "duplicate")
+	gateway.Charge(ctx, "duplicate", domain.PaymentMethod{}, rub(1000))
+
+	// Расхождение в сумме не исправляется
+	saveOrderWithLines(t, repo, "mismatch", stockLine{"product-1", 1})
+	application.NewPayOrderUseCase(repo, gateway).Execute(ctx, "mismatch")
+	gateway.Charge(ctx, "mismatch", domain.PaymentMethod{}, rub(1000))
+	gateway.Charge(ctx, "mismatch", domain.PaymentMethod{}, rub(1))
+
+	reconcile := application.NewReconcilePaymentsUseCase(repo, gateway,
+		application.ReconcileClock(clock), application.AutoRepair(gateway))
+	result, err := reconcile.Execute(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	repaired := make(map[string]bool)
+	for _, d := range result.Discrepancies {
+		if d.RepairErr != nil {
+			t.Errorf("%s: unexpected repair error: %v", d.OrderID, d.RepairErr)
+		}
+		if d.Repaired {
+			repaired[d.OrderID] = true
+		}
+	}
+	if !repaired["pending"] || !repaired["duplicate"] || repaired["mismatch"] {
+		t.Errorf("expected pending and duplicate to be repaired, got %v", repaired)
+	}
+
+	order, _ := repo.GetByID(ctx, "pending")
+	if order.Status() != domain.OrderStatusPaid {
+		t.Errorf("expected pending order to be marked PAID, got %s", order.Status())
+	}
+	refunds := gateway.GetRefunds()
+	if len(refunds) != 1 || refunds[0].OrderID != "duplicate" || refunds[0].Amount.Amount() != 1000 {
+		t.Errorf("expected the duplicate charge to be refunded, got %+v", refunds)
+	}
+
+	// После исправления остаются только расхождения по заказу mismatch:
+	// повтор не возвращается, пока сумма по заказу не сходится
+	result, err = reconcile.Execute(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, d := range result.Discrepancies {
+		if d.OrderID != "mismatch" || d.Repaired {
+			t.Errorf("expected only unrepaired mismatch discrepancies, got %+v", d)
+		}
+	}
+	if result.Count(application.DiscrepancyAmountMismatch) != 1 {
+		t.Errorf("expected the amount mismatch to remain, got %+v", result.Discrepancies)
+	}
+}
+
+// TestReconcilePayments_SkipsUnsettled проверяет, что недавние операции
+// не сверяются, пока оплата может быть ещё в процессе
+func TestReconcilePayments_SkipsUnsettled(t *testing.T) {
+	repo, gateway, _ := reconcileEnvironment()
+	gateway.SetClock(time.Now)
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub(1000))
+
+	result, err := application.NewReconcilePaymentsUseCase(repo, gateway).Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Discrepancies) != 0 || len(result.Unsettled) != 1 || result.Unsettled[0] != "order-1" {
+		t.Errorf("expected order-1 to be unsettled, got %+v", result)
+	}
+
+	result, _ = application.NewReconcilePaymentsUseCase(repo, gateway, application.SettleDelay(0)).Execute(t.Context())
+	if result.Count(application.DiscrepancyChargedPending) != 1 {
+		t.Errorf("expected charged-but-pending without settle delay, got %+v", result.Discrepancies)
+	}
+}
+
+// TestReconcilePayments_Installments проверяет сверку частично оплаченного
+// заказа, платежи которого совпадают по сумме
+func TestReconcilePayments_Installments(t *testing.T) {
+	repo, gateway, clock := reconcileEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 3})
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	start := time.Now().Add(24 * time.Hour)
+	order.ScheduleInstallments([]time.Time{start, start.Add(24 * time.Hour), start.Add(48 * time.Hour)})
+	repo.Save(t.Context(), order)
+
+	payInstallment := application.NewPayInstallmentUseCase(repo, gateway)
+	for range 2 {
+		if _, err := payInstallment.Execute(t.Context(), application.PayInstallmentCommand{OrderID: "order-1"}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+
+	result, err := application.NewReconcilePaymentsUseCase(repo, gateway, application.ReconcileClock(clock)).Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Orders != 1 || len(result.Discrepancies) != 0 {
+		t.Errorf("expected installments to reconcile cleanly, got %+v", result)
+	}
+}
+
+// TestReconcilePayments_NoRepairAfterDeadline проверяет, что заказ с
+// истёкшим сроком оплаты не переводится в PAID при исправлении
+func TestReconcilePayments_NoRepairAfterDeadline(t *testing.T) {
+	repo, gateway, clock := reconcileEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	order.SetPaymentDeadline(clock().Add(-time.Minute))
+	repo.Save(t.Context(), order)
+	gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub(1000))
+
+	result, err := application.NewReconcilePaymentsUseCase(repo, gateway,
+		application.ReconcileClock(clock), application.AutoRepair(gateway)).Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Discrepancies) != 1 {
+		t.Fatalf("expected one discrepancy, got %+v", result.Discrepancies)
+	}
+	d := result.Discrepancies[0]
+	if d.Kind != application.DiscrepancyChargedPending || d.Repaired || !errors.Is(d.RepairErr, domain.ErrOrderExpired) {
+		t.Errorf("expected unrepaired charged-but-pending with ErrOrderExpired, got %+v", d)
+	}
+	if orderStatus(t, repo, "order-1") != domain.OrderStatusPending {
+		t.Errorf("expected order to stay PENDING, got %s", orderStatus(t, repo, "order-1"))
+	}
+}
+
+// TestReconcilePayments_RepairConcurrentPayment проверяет, что исправление
+// не затирает оплату, проведённую после чтения заказа сверкой
+func TestReconcilePayments_RepairConcurrentPayment(t *testing.T) {
+	repo, gateway, clock := reconcileEnvironment()
+	saveOrderWithLines(t, repo, "order-1", stockLine{"product-1", 1})
+	gateway.Charge(t.Context(), "order-1", domain.PaymentMethod{}, rub(1000))
+
+	result, err := application.NewReconcilePaymentsUseCase(&payOnReadRepository{repo}, gateway,
+		application.ReconcileClock(clock), application.AutoRepair(gateway)).Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Discrepancies) != 1 {
+		t.Fatalf("expected one discrepancy, got %+v", result.Discrepancies)
+	}
+	if d := result.Discrepancies[0]; d.Repaired || !errors.Is(d.RepairErr, domain.ErrOrderAlreadyPaid) {
+		t.Errorf("expected repair to be refused with ErrOrderAlreadyPaid, got %+v", d)
+	}
+	if status := orderStatus(t, repo, "order-1"); status != domain.OrderStatusPaid {
+		t.Errorf("expected concurrent payment to be kept, got %s", status)
+	}
+}
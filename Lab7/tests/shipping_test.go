@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"path/filepath"
+	"testing"
+)
+
+// shipment создаёт данные доставки для расчёта по политике
+func shipment(t *testing.T, country, region string, method domain.DeliveryMethod, weightGrams int, subtotal int64) domain.Shipment {
+	t.Helper()
+	address, err := domain.NewAddress(country, region, "City", "", "Main st. 1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	delivery, err := domain.NewDelivery(address, method)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return domain.Shipment{Delivery: delivery, WeightGrams: weightGrams, Subtotal: rub(subtotal)}
+}
+
+// TestShippingPolicies проверяет расчёт стоимости доставки каждой политикой
+func TestShippingPolicies(t *testing.T) {
+	flat := domain.NewFlatRateShipping(rub(300))
+	byWeight, err := domain.NewWeightBasedShipping(rub(200), rub(50))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	zones := domain.NewZoneTableShipping(map[string]domain.ShippingPolicy{
+		"RU/MOW": domain.NewFlatRateShipping(rub(100)),
+		"RU":     byWeight,
+	}, nil)
+	freeOver := domain.NewFreeOverThresholdShipping(rub(5000), flat)
+
+	tests := []struct {
+		name     string
+		policy   domain.ShippingPolicy
+		shipment domain.Shipment
+		want     int64
+	}{
+		{"flat", flat, shipment(t, "RU", "", domain.DeliveryMethodCourier, 0, 1000), 300},
+		{"weight: empty parcel", byWeight, shipment(t, "RU", "", domain.DeliveryMethodPost, 0, 1000), 200},
+		{"weight: started kilogram", byWeight, shipment(t, "RU", "", domain.DeliveryMethodPost, 1001, 1000), 300},
+		{"weight: exact kilograms", byWeight, shipment(t, "RU", "", domain.DeliveryMethodPost, 3000, 1000), 350},
+		{"zone: region", zones, shipment(t, "RU", "MOW", domain.DeliveryMethodCourier, 2500, 1000), 100},
+		{"zone: country", zones, shipment(t, "ru", "SPE", domain.DeliveryMethodCourier, 2500, 1000), 350},
+		{"free over: below", freeOver, shipment(t, "RU", "", domain.DeliveryMethodCourier, 0, 4999), 300},
+		{"free over: threshold", freeOver, shipment(t, "RU", "", domain.DeliveryMethodCourier, 0, 5000), 0},
+	}
+	for _, tt := range tests {
+		cost, err := tt.policy.Cost(tt.shipment)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", tt.name, err)
+			continue
+		}
+		if !cost.Equals(rub(tt.want)) {
+			t.Errorf("%s: expected %s, got %s", tt.name, rub(tt.want), cost)
+		}
+	}
+
+	_, err = zones.Cost(shipment(t, "KZ", "", domain.DeliveryMethodCourier, 0, 1000))
+	if !errors.Is(err, domain.ErrShippingUnavailable) {
+		t.Errorf("expected ErrShippingUnavailable outside zones, got: %v", err)
+	}
+	if application.ErrorCode(err) != application.ErrorCodeNoShipping {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeNoShipping, application.ErrorCode(err))
+	}
+}
+
+// TestOrder_QuoteShipping проверяет, что доставка входит в итог отдельной суммой
+func TestOrder_QuoteShipping(t *testing.T) {
+	order := domain.NewOrder("order-1")
+	product, _ := domain.NewProduct("kettle", "Kettle", rub(2000))
+	product.SetWeight(1200)
+	line, _ := domain.NewOrderLineFromProduct(product, 2)
+	order.AddLine(line)
+
+	policy, _ := domain.NewWeightBasedShipping(rub(200), rub(50))
+	if err := order.QuoteShipping(policy); !errors.Is(err, domain.ErrDeliveryNotSet) {
+		t.Fatalf("expected ErrDeliveryNotSet, got: %v", err)
+	}
+
+	order.SetDelivery(shipment(t, "RU", "", domain.DeliveryMethodCourier, 0, 0).Delivery)
+	if err := order.Pay(); !errors.Is(err, domain.ErrShippingNotQuoted) {
+		t.Fatalf("expected ErrShippingNotQuoted, got: %v", err)
+	}
+	if err := order.QuoteShipping(policy); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// 2.4 кг - три начатых килограмма
+	subtotal, _ := order.Subtotal()
+	total, _ := order.Total()
+	if order.WeightGrams() != 2400 || !order.Shipping().Equals(rub(350)) {
+		t.Errorf("expected 2400 g and 3.50 RUB shipping, got %d g and %s", order.WeightGrams(), order.Shipping())
+	}
+	if !subtotal.Equals(rub(4000)) || !total.Equals(rub(4350)) {
+		t.Errorf("expected subtotal 40.00 and total 43.50, got %s and %s", subtotal, total)
+	}
+
+	// Новая строка меняет вес, поэтому расчёт сбрасывается
+	order.AddLine(line)
+	if !order.Shipping().Equals(domain.Money{}) {
+		t.Errorf("expected shipping quote to be reset, got %s", order.Shipping())
+	}
+
+	// Самовывоз бесплатен при любой политике
+	pickup := shipment(t, "RU", "", domain.DeliveryMethodPickup, 0, 0).Delivery
+	order.SetDelivery(pickup)
+	if err := order.QuoteShipping(policy); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !order.Shipping().Equals(rub(0)) {
+		t.Errorf("expected free pickup, got %s", order.Shipping())
+	}
+}
+
+// TestOrder_ShippingCurrencyMismatch проверяет отказ при доставке в другой валюте
+func TestOrder_ShippingCurrencyMismatch(t *testing.T) {
+	order := domain.NewOrder("order-1")
+	line, _ := domain.NewOrderLine("kettle", rub(2000), 1)
+	order.AddLine(line)
+	order.SetDelivery(shipment(t, "RU", "", domain.DeliveryMethodCourier, 0, 0).Delivery)
+
+	usd, _ := domain.NewMoney(500, "USD")
+	if err := order.QuoteShipping(domain.NewFlatRateShipping(usd)); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got: %v", err)
+	}
+	if _, err := domain.NewWeightBasedShipping(rub(100), usd); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch for mixed policy, got: %v", err)
+	}
+}
+
+// TestPayOrder_ChargesShipping проверяет, что шлюз списывает товары вместе с доставкой
+func TestPayOrder_ChargesShipping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	repo, _ := infrastructure.NewFileOrderRepository(path)
+	gateway := infrastructure.NewFakePaymentGateway()
+	policy := domain.NewFreeOverThresholdShipping(rub(10000), domain.NewFlatRateShipping(rub(500)))
+
+	repo.Save(t.Context(), domain.NewOrder("order-1"))
+	addLine := application.NewAddOrderLineUseCase(repo)
+	addLine.Execute(t.Context(), application.AddOrderLineCommand{
+		OrderID: "order-1", ProductID: "kettle", UnitPrice: 2000, Currency: "RUB", Quantity: 1,
+	})
+	view, err := application.NewSetDeliveryUseCase(repo, application.QuoteWith(policy)).Execute(t.Context(), application.SetDeliveryCommand{
+		OrderID: "order-1", Method: "COURIER", Country: "RU", City: "Moscow", Street: "Tverskaya 1",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if view.Subtotal != 2000 || view.Shipping != 500 || view.Total != 2500 || view.Delivery.City != "Moscow" {
+		t.Errorf("unexpected view: %+v", view)
+	}
+
+	// Строка добавлена после расчёта: доставка пересчитывается при оплате
+	addLine.Execute(t.Context(), application.AddOrderLineCommand{
+		OrderID: "order-1", ProductID: "kettle", UnitPrice: 8000, Currency: "RUB", Quantity: 1,
+	})
+	result, err := application.NewPayOrderUseCase(repo, gateway, application.WithShipping(policy)).Execute(t.Context(), "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	payments := gateway.GetPayments()
+	if result.Amount != 10000 || len(payments) != 1 || !payments[0].Amount.Equals(rub(10000)) {
+		t.Errorf("expected 100.00 RUB with free shipping, got %d (payments %+v)", result.Amount, payments)
+	}
+
+	reopened, _ := infrastructure.NewFileOrderRepository(path)
+	order, _ := reopened.GetByID(t.Context(), "order-1")
+	if order.Delivery().Address().City() != "Moscow" || !order.Shipping().Equals(rub(0)) {
+		t.Errorf("expected delivery and shipping to survive reload, got %+v %s", order.Delivery(), order.Shipping())
+	}
+}
+
+// TestSetDelivery_Validation проверяет разбор адреса и способа доставки
+func TestSetDelivery_Validation(t *testing.T) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	repo.Save(t.Context(), domain.NewOrder("order-1"))
+	useCase := application.NewSetDeliveryUseCase(repo)
+
+	tests := []struct {
+		cmd  application.SetDeliveryCommand
+		want error
+	}{
+		{application.SetDeliveryCommand{OrderID: "order-1", Method: "TELEPORT", Country: "RU", City: "Moscow", Street: "1"}, domain.ErrUnknownDeliveryMethod},
+		{application.SetDeliveryCommand{OrderID: "order-1", Method: "POST", Country: "Russia", City: "Moscow", Street: "1"}, domain.ErrInvalidAddress},
+		{application.SetDeliveryCommand{OrderID: "order-1", Method: "POST", Country: "RU", Street: "1"}, domain.ErrInvalidAddress},
+		{application.SetDeliveryCommand{OrderID: "order-2", Method: "POST", Country: "RU", City: "Moscow", Street: "1"}, application.ErrOrderNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := useCase.Execute(t.Context(), tt.cmd); !errors.Is(err, tt.want) {
+			t.Errorf("%+v: expected %v, got: %v", tt.cmd, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+)
+
+// setupSplitEnvironment создаёт заказ на 100.00 RUB и use-case оплаты
+func setupSplitEnvironment(t *testing.T) (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, *application.PayOrderUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	order := domain.NewOrder("order-1")
+	line, _ := domain.NewOrderLine("product-1", rub(10000), 1)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+	return repo, gateway, application.NewPayOrderUseCase(repo, gateway)
+}
+
+// splitCommand - оплата заказа order-1 картой и кошельком
+func splitCommand(card, wallet int64) application.PayOrderCommand {
+	return application.PayOrderCommand{
+		OrderID: "order-1",
+		Legs: []application.PaymentLegCommand{
+			{Method: "CARD", Reference: "tok_visa_4242", Amount: card},
+			{Method: "WALLET", Reference: "wallet-7", Amount: wallet},
+		},
+	}
+}
+
+// TestPaymentMethod_Validation проверяет создание способов оплаты
+func TestPaymentMethod_Validation(t *testing.T) {
+	tests := []struct {
+		kind, reference string
+		want            error
+	}{
+		{"CARD", "tok_visa_4242", nil},
+		{"CARD", "", domain.ErrInvalidPaymentMethod},
+		{"CARD", "4111 1111 1111 1111", domain.ErrInvalidPaymentMethod},
+		{"WALLET", " ", domain.ErrInvalidPaymentMethod},
+		{"BANK_TRANSFER", "40817810099910004312", nil},
+		{"CASH_ON_DELIVERY", "", nil},
+		{"BARTER", "goats", domain.ErrUnknownPaymentMethod},
+	}
+	for _, tt := range tests {
+		_, err := domain.ParsePaymentMethod(tt.kind, tt.reference)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s %q: expected %v, got: %v", tt.kind, tt.reference, tt.want, err)
+		}
+	}
+
+	card, _ := domain.NewCardToken("tok_visa_4242")
+	if card.String() != "CARD *4242" {
+		t.Errorf("expected masked reference, got %q", card.String())
+	}
+}
+
+// TestPayOrder_SplitTender проверяет оплату заказа несколькими способами
+func TestPayOrder_SplitTender(t *testing.T) {
+	repo, gateway, useCase := setupSplitEnvironment(t)
+
+	result, err := useCase.ExecuteCommand(t.Context(), splitCommand(7000, 3000))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Amount != 10000 {
+		t.Errorf("expected 100.00 RUB in total, got %d", result.Amount)
+	}
+
+	payments := gateway.GetPayments()
+	if len(payments) != 2 ||
+		payments[0].Method.Kind() != domain.PaymentMethodCard || !payments[0].Amount.Equals(rub(7000)) ||
+		payments[1].Method.Kind() != domain.PaymentMethodWallet || !payments[1].Amount.Equals(rub(3000)) {
+		t.Errorf("expected card 70.00 and wallet 30.00, got %+v", payments)
+	}
+
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if order.Status() != domain.OrderStatusPaid || len(order.Payments()) != 2 {
+		t.Errorf("expected PAID order with 2 payments, got %s with %v", order.Status(), order.Payments())
+	}
+
+	// Возврат выполняется теми же способами в обратном порядке
+	if _, err := application.NewRefundOrderUseCase(repo, gateway).Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	refunds := gateway.GetRefunds()
+	if len(refunds) != 2 || refunds[0].Method.Kind() != domain.PaymentMethodWallet || refunds[1].Method.Kind() != domain.PaymentMethodCard {
+		t.Errorf("expected wallet and card refunds, got %+v", refunds)
+	}
+}
+
+// TestPayOrder_SplitMismatch проверяет, что части должны точно покрывать итог
+func TestPayOrder_SplitMismatch(t *testing.T) {
+	_, gateway, useCase := setupSplitEnvironment(t)
+
+	for _, cmd := range []application.PayOrderCommand{
+		splitCommand(7000, 2999),
+		splitCommand(7000, 3001),
+		splitCommand(10000, 0),
+	} {
+		_, err := useCase.ExecuteCommand(t.Context(), cmd)
+		if !errors.Is(err, domain.ErrSplitMismatch) {
+			t.Errorf("%+v: expected ErrSplitMismatch, got: %v", cmd.Legs, err)
+		}
+		if application.ErrorCode(err) != application.ErrorCodeSplitMismatch {
+			t.Errorf("expected error code %s, got %s", application.ErrorCodeSplitMismatch, application.ErrorCode(err))
+		}
+	}
+	if len(gateway.GetCalls()) != 0 {
+		t.Errorf("expected gateway not to be called, got %d calls", len(gateway.GetCalls()))
+	}
+}
+
+// TestPayOrder_SplitRollback проверяет возврат списанных частей при отказе следующей
+func TestPayOrder_SplitRollback(t *testing.T) {
+	repo, gateway, useCase := setupSplitEnvironment(t)
+	gateway.SetScenarios(infrastructure.FaultScenario{
+		Name:      "wallet-declined",
+		Operation: infrastructure.GatewayOperationCharge,
+		Methods:   []domain.PaymentMethodKind{domain.PaymentMethodWallet},
+		Fault:     infrastructure.FaultPermanent,
+		Reason:    "wallet is empty",
+	})
+
+	_, err := useCase.ExecuteCommand(t.Context(), splitCommand(7000, 3000))
+	if !errors.Is(err, application.ErrPaymentFailed) || !errors.Is(err, application.ErrPaymentDeclined) {
+		t.Fatalf("expected declined payment, got: %v", err)
+	}
+
+	refunds := gateway.GetRefunds()
+	if len(refunds) != 1 || refunds[0].Method.Kind() != domain.PaymentMethodCard || !refunds[0].Amount.Equals(rub(7000)) {
+		t.Errorf("expected card leg to be refunded, got %+v", refunds)
+	}
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if order.Status() != domain.OrderStatusPending {
+		t.Errorf("expected order to stay PENDING, got %s", order.Status())
+	}
+
+	// Если откат тоже не прошёл, ошибка сообщает об этом
+	gateway.Reset()
+	gateway.SetScenarios(
+		infrastructure.FaultScenario{Operation: infrastructure.GatewayOperationCharge, OnCall: 2, Fault: infrastructure.FaultPermanent},
+		infrastructure.FaultScenario{Operation: infrastructure.GatewayOperationRefund, Fault: infrastructure.FaultTransient},
+	)
+	_, err = useCase.ExecuteCommand(t.Context(), splitCommand(7000, 3000))
+	if !errors.Is(err, application.ErrPaymentFailed) || !errors.Is(err, application.ErrGatewayUnavailable) {
+		t.Errorf("expected payment and rollback errors, got: %v", err)
+	}
+}
+
+// TestRefundOrder_SplitPartialFailure проверяет, что прерванный возврат
+// частей запоминает уже возвращённые части и повтор их не возвращает
+func TestRefundOrder_SplitPartialFailure(t *testing.T) {
+	repo, gateway, useCase := setupSplitEnvironment(t)
+	if _, err := useCase.ExecuteCommand(t.Context(), splitCommand(7000, 3000)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Части возвращаются в обратном порядке: кошелёк, затем карта
+	gateway.SetScenarios(infrastructure.FaultScenario{
+		Operation: infrastructure.GatewayOperationRefund,
+		OnCall:    2,
+		Fault:     infrastructure.FaultTransient,
+	})
+	refund := application.NewRefundOrderUseCase(repo, gateway)
+
+	_, err := refund.Execute(t.Context(), "order-1")
+	if !errors.Is(err, application.ErrRefundFailed) || !errors.Is(err, application.ErrGatewayUnavailable) {
+		t.Fatalf("expected refund failure, got: %v", err)
+	}
+	order, _ := repo.GetByID(t.Context(), "order-1")
+	if order.Status() != domain.OrderStatusPaid {
+		t.Errorf("expected order to stay PAID, got %s", order.Status())
+	}
+	legs := order.Payments()
+	if legs[0].Refunded || !legs[1].Refunded {
+		t.Errorf("expected only the wallet leg to be marked refunded, got %+v", legs)
+	}
+
+	if _, err := refund.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected retry to succeed, got: %v", err)
+	}
+	refunds := gateway.GetRefunds()
+	if len(refunds) != 2 || refunds[0].Method.Kind() != domain.PaymentMethodWallet || refunds[1].Method.Kind() != domain.PaymentMethodCard {
+		t.Errorf("expected each leg to be refunded once, got %+v", refunds)
+	}
+	order, _ = repo.GetByID(t.Context(), "order-1")
+	if order.Status() != domain.OrderStatusRefunded {
+		t.Errorf("expected order to be REFUNDED, got %s", order.Status())
+	}
+}
@@ -0,0 +1,291 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"testing"
+	"time"
+)
+
+// subscriptionStart - время первого списания по подписке в тестах
+var subscriptionStart = time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+// testRetrySchedule - короткое расписание повторов для тестов
+var testRetrySchedule = domain.RetrySchedule{time.Hour, 2 * time.Hour}
+
+// subscriptionEnvironment - окружение тестов списаний по подпискам
+type subscriptionEnvironment struct {
+	now           time.Time
+	orders        *infrastructure.InMemoryOrderRepository
+	subscriptions *infrastructure.InMemorySubscriptionRepository
+	gateway       *infrastructure.FakePaymentGateway
+	useCase       *application.BillSubscriptionsUseCase
+}
+
+// newSubscription создаёт месячную подписку на план за 299.00 RUB
+func newSubscription(t *testing.T, id string) *domain.Subscription {
+	t.Helper()
+	plan, err := domain.NewPlan("plan-pro", "Pro", rub(29900), domain.VAT20)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	subscription, err := domain.NewSubscription(id, "customer-1", plan, domain.BillingMonthly, subscriptionStart)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return subscription
+}
+
+// setupSubscriptionEnvironment создаёт подписку sub-1 и use-case списаний
+// с управляемыми часами
+func setupSubscriptionEnvironment(t *testing.T) *subscriptionEnvironment {
+	t.Helper()
+	env := &subscriptionEnvironment{
+		now:           subscriptionStart,
+		orders:        infrastructure.NewInMemoryOrderRepository(),
+		subscriptions: infrastructure.NewInMemorySubscriptionRepository(),
+		gateway:       infrastructure.NewFakePaymentGateway(),
+	}
+	env.subscriptions.Save(t.Context(), newSubscription(t, "sub-1"))
+	env.useCase = application.NewBillSubscriptionsUseCase(env.subscriptions, env.orders,
+		application.NewPayOrderUseCase(env.orders, env.gateway),
+		application.WithRetrySchedule(testRetrySchedule),
+		application.BillingClock(func() time.Time { return env.now }))
+	return env
+}
+
+// bill выполняет проход и возвращает исход по единственной подписке
+func (env *subscriptionEnvironment) bill(t *testing.T) application.SubscriptionBilling {
+	t.Helper()
+	result, err := env.useCase.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(result.Billings) != 1 {
+		t.Fatalf("expected one billing, got %+v", result.Billings)
+	}
+	return result.Billings[0]
+}
+
+// subscription загружает подписку sub-1
+func (env *subscriptionEnvironment) subscription(t *testing.T) *domain.Subscription {
+	t.Helper()
+	subscription, err := env.subscriptions.GetByID(t.Context(), "sub-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return subscription
+}
+
+// TestSubscription_Lifecycle проверяет повторы, приостановку и отмену подписки
+func TestSubscription_Lifecycle(t *testing.T) {
+	subscription := newSubscription(t, "sub-1")
+	if !subscription.IsDue(subscriptionStart) || subscription.IsDue(subscriptionStart.Add(-time.Second)) {
+		t.Error("expected subscription to be due exactly at start")
+	}
+
+	subscription.RecordFailure(subscriptionStart, testRetrySchedule)
+	if subscription.Status() != domain.SubscriptionStatusPastDue || !subscription.DueAt().Equal(subscriptionStart.Add(time.Hour)) {
+		t.Errorf("expected PAST_DUE with retry in 1h, got %s at %v", subscription.Status(), subscription.DueAt())
+	}
+	subscription.RecordFailure(subscriptionStart, testRetrySchedule)
+	subscription.RecordFailure(subscriptionStart, testRetrySchedule)
+	if subscription.Status() != domain.SubscriptionStatusSuspended || subscription.IsDue(subscriptionStart.AddDate(1, 0, 0)) {
+		t.Errorf("expected SUSPENDED subscription that is never due, got %s", subscription.Status())
+	}
+	if err := subscription.RecordPayment(); !errors.Is(err, domain.ErrSubscriptionSuspended) {
+		t.Errorf("expected ErrSubscriptionSuspended, got: %v", err)
+	}
+
+	resumedAt := subscriptionStart.AddDate(0, 0, 3)
+	if err := subscription.Resume(resumedAt); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !subscription.IsDue(resumedAt) || subscription.Failures() != 0 {
+		t.Errorf("expected resumed subscription to be due at once, got %+v", subscription.Snapshot())
+	}
+	// Подписка от 31 января списывается в последний день короткого месяца
+	for _, want := range []time.Time{
+		time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC),
+	} {
+		subscription.RecordPayment()
+		if !subscription.NextBillingAt().Equal(want) {
+			t.Errorf("cycle %d: expected billing at %v, got %v", subscription.Cycle(), want, subscription.NextBillingAt())
+		}
+	}
+	if subscription.Cycle() != 4 {
+		t.Errorf("expected cycle 4, got %d", subscription.Cycle())
+	}
+
+	if err := subscription.Cancel(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := subscription.RecordPayment(); !errors.Is(err, domain.ErrSubscriptionCancelled) {
+		t.Errorf("expected ErrSubscriptionCancelled, got: %v", err)
+	}
+	if _, err := domain.NewSubscription("sub-2", "customer-1", subscription.Plan(), "DAILY", subscriptionStart); !errors.Is(err, domain.ErrUnknownBillingInterval) {
+		t.Errorf("expected ErrUnknownBillingInterval, got: %v", err)
+	}
+}
+
+// TestBillSubscriptions_OrderPerCycle проверяет, что за каждый период
+// выставляется и оплачивается отдельный заказ
+func TestBillSubscriptions_OrderPerCycle(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+
+	if billing := env.bill(t); billing.Outcome != application.BillingPaid || billing.OrderID != "sub-1-1" {
+		t.Fatalf("expected first cycle to be paid, got %+v", billing)
+	}
+	order, err := env.orders.GetByID(t.Context(), "sub-1-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !order.IsPaid() || order.CustomerID() != "customer-1" || order.Lines()[0].VATRate() != domain.VAT20 {
+		t.Errorf("expected paid plan order for customer, got %+v", order.Snapshot())
+	}
+
+	// До следующего периода списаний нет
+	env.now = env.now.Add(24 * time.Hour)
+	if result, _ := env.useCase.Execute(t.Context()); len(result.Billings) != 0 {
+		t.Errorf("expected nothing to bill, got %+v", result.Billings)
+	}
+
+	env.now = env.subscription(t).NextBillingAt()
+	if billing := env.bill(t); billing.Outcome != application.BillingPaid || billing.OrderID != "sub-1-2" {
+		t.Errorf("expected second cycle to be paid, got %+v", billing)
+	}
+	if payments := env.gateway.GetPayments(); len(payments) != 2 || !payments[1].Amount.Equals(rub(29900)) {
+		t.Errorf("expected two charges of 299.00 RUB, got %+v", payments)
+	}
+}
+
+// TestBillSubscriptions_SuspendAfterRetries проверяет расписание повторов и
+// приостановку подписки после исчерпания повторов
+func TestBillSubscriptions_SuspendAfterRetries(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+	env.gateway.SetShouldFail(true, "card declined")
+
+	want := []application.BillingOutcome{application.BillingRetryScheduled, application.BillingRetryScheduled, application.BillingSuspended}
+	for i, outcome := range want {
+		billing := env.bill(t)
+		if billing.Outcome != outcome || billing.Err == nil {
+			t.Fatalf("attempt %d: expected %s with error, got %+v", i+1, outcome, billing)
+		}
+		if billing.OrderID != "sub-1-1" {
+			t.Errorf("attempt %d: expected retries to reuse order sub-1-1, got %s", i+1, billing.OrderID)
+		}
+		// Повтор до срока не выполняется
+		if result, _ := env.useCase.Execute(t.Context()); len(result.Billings) != 0 {
+			t.Fatalf("attempt %d: expected no billing before retry time, got %+v", i+1, result.Billings)
+		}
+		env.now = env.subscription(t).DueAt()
+	}
+	if status := env.subscription(t).Status(); status != domain.SubscriptionStatusSuspended {
+		t.Errorf("expected SUSPENDED, got %s", status)
+	}
+}
+
+// TestBillSubscriptions_TransientFailure проверяет, что временная
+// недоступность шлюза не засчитывается как неудачная попытка
+func TestBillSubscriptions_TransientFailure(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+	env.gateway.SetScenarios(infrastructure.FaultScenario{Name: "outage", OnCall: 1, Fault: infrastructure.FaultTransient})
+
+	billing := env.bill(t)
+	if billing.Outcome != application.BillingDeferred || !errors.Is(billing.Err, application.ErrGatewayUnavailable) {
+		t.Fatalf("expected deferred billing, got %+v", billing)
+	}
+	if subscription := env.subscription(t); subscription.Status() != domain.SubscriptionStatusActive || subscription.Failures() != 0 {
+		t.Errorf("expected ACTIVE subscription without failures, got %+v", subscription.Snapshot())
+	}
+	if billing := env.bill(t); billing.Outcome != application.BillingPaid {
+		t.Errorf("expected paid on next pass, got %+v", billing)
+	}
+}
+
+// TestBillSubscriptions_DeferralLimit проверяет, что после
+// DefaultMaxDeferrals отложенных попыток сбой шлюза засчитывается как
+// неудачная попытка
+func TestBillSubscriptions_DeferralLimit(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+	env.gateway.SetScenarios(infrastructure.FaultScenario{Name: "outage", Fault: infrastructure.FaultTransient})
+
+	for i := range application.DefaultMaxDeferrals {
+		if billing := env.bill(t); billing.Outcome != application.BillingDeferred {
+			t.Fatalf("pass %d: expected deferred billing, got %+v", i+1, billing)
+		}
+	}
+	if deferrals := env.subscription(t).Deferrals(); deferrals != application.DefaultMaxDeferrals {
+		t.Errorf("expected %d deferrals, got %d", application.DefaultMaxDeferrals, deferrals)
+	}
+
+	billing := env.bill(t)
+	if billing.Outcome != application.BillingRetryScheduled || !errors.Is(billing.Err, application.ErrGatewayUnavailable) {
+		t.Fatalf("expected retry scheduled after deferral limit, got %+v", billing)
+	}
+	if subscription := env.subscription(t); subscription.Failures() != 1 || subscription.Deferrals() != 0 {
+		t.Errorf("expected one failure and reset deferrals, got %+v", subscription.Snapshot())
+	}
+}
+
+// TestBillSubscriptions_UnsettledCharge проверяет, что заказ периода, по
+// которому шлюз уже списал деньги, повторно не оплачивается
+func TestBillSubscriptions_UnsettledCharge(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+	env.useCase = application.NewBillSubscriptionsUseCase(env.subscriptions, env.orders,
+		application.NewPayOrderUseCase(env.orders, env.gateway),
+		application.WithRetrySchedule(testRetrySchedule),
+		application.BillingTransactions(env.gateway),
+		application.BillingClock(func() time.Time { return env.now }))
+	orderID := env.subscription(t).CycleOrderID()
+	if err := env.gateway.Charge(t.Context(), orderID, domain.PaymentMethod{}, rub(1)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	billing := env.bill(t)
+	if billing.Outcome != application.BillingUnsettled || !errors.Is(billing.Err, application.ErrUnsettledCharge) {
+		t.Fatalf("expected unsettled billing, got %+v", billing)
+	}
+	if code := application.ErrorCode(billing.Err); code != application.ErrorCodeUnsettled {
+		t.Errorf("expected error code %s, got %s", application.ErrorCodeUnsettled, code)
+	}
+	if payments := env.gateway.GetPayments(); len(payments) != 1 {
+		t.Errorf("expected no second charge, got %d payments", len(payments))
+	}
+	if subscription := env.subscription(t); subscription.Cycle() != 1 || subscription.Failures() != 0 {
+		t.Errorf("expected subscription to stay on the same cycle, got %+v", subscription.Snapshot())
+	}
+}
+
+// TestBillingScheduler проверяет фоновые списания по сигналам
+func TestBillingScheduler(t *testing.T) {
+	env := setupSubscriptionEnvironment(t)
+	ticks := make(chan time.Time)
+	results := make(chan application.BillSubscriptionsResult, 1)
+	scheduler := infrastructure.NewBillingScheduler(env.useCase, time.Hour,
+		infrastructure.BillingTicks(ticks),
+		infrastructure.OnBilling(func(result application.BillSubscriptionsResult, err error) {
+			if err != nil {
+				t.Errorf("unexpected billing error: %v", err)
+			}
+			results <- result
+		}))
+	if err := scheduler.Start(t.Context()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer scheduler.Stop()
+
+	ticks <- env.now
+	select {
+	case result := <-results:
+		if result.Count(application.BillingPaid) != 1 {
+			t.Errorf("expected one paid subscription, got %+v", result.Billings)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("billing did not run")
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"path/filepath"
+	"testing"
+)
+
+// setupTenants создаёт репозиторий и шлюз для магазинов shop-a и shop-b
+// с отдельными фейковыми шлюзами
+func setupTenants(t *testing.T) (*infrastructure.TenantOrderRepository, map[string]*infrastructure.FakePaymentGateway, *application.PayOrderUseCase) {
+	t.Helper()
+	repo := infrastructure.NewInMemoryTenantOrderRepository()
+	gateway := infrastructure.NewTenantPaymentGateway()
+	fakes := map[string]*infrastructure.FakePaymentGateway{}
+	for _, tenantID := range []string{"shop-a", "shop-b"} {
+		fakes[tenantID] = infrastructure.NewFakePaymentGateway()
+		gateway.Register(tenantID, fakes[tenantID])
+	}
+	return repo, fakes, application.NewPayOrderUseCase(repo, gateway)
+}
+
+// saveTenantOrder сохраняет в магазине заказ с одной строкой на amount RUB
+func saveTenantOrder(t *testing.T, repo application.OrderRepository, tenantID, orderID string, amount int64) {
+	t.Helper()
+	order := domain.NewOrder(orderID)
+	line, _ := domain.NewOrderLine("product-1", rub(amount), 1)
+	order.AddLine(line)
+	if err := repo.Save(application.WithTenant(t.Context(), tenantID), order); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+// TestTenant_OrdersAreIsolated проверяет, что магазины не видят заказы друг друга
+func TestTenant_OrdersAreIsolated(t *testing.T) {
+	repo, _, _ := setupTenants(t)
+	shopA := application.WithTenant(t.Context(), "shop-a")
+	shopB := application.WithTenant(t.Context(), "shop-b")
+
+	// Одинаковые идентификаторы в разных магазинах - разные заказы
+	saveTenantOrder(t, repo, "shop-a", "order-1", 100)
+	saveTenantOrder(t, repo, "shop-b", "order-1", 700)
+	saveTenantOrder(t, repo, "shop-a", "order-2", 200)
+
+	orderA, err := repo.GetByID(shopA, "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	orderB, err := repo.GetByID(shopB, "order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	totalA, _ := orderA.Total()
+	totalB, _ := orderB.Total()
+	if totalA.Amount() != 100 || totalB.Amount() != 700 {
+		t.Errorf("expected 100 and 700, got %d and %d", totalA.Amount(), totalB.Amount())
+	}
+
+	if _, err := repo.GetByID(shopB, "order-2"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound for another tenant's order, got: %v", err)
+	}
+	page, err := repo.Find(shopB, application.OrderQuery{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(page.Orders) != 1 || page.Orders[0].ID() != "order-1" {
+		t.Errorf("expected only shop-b orders, got %d orders", len(page.Orders))
+	}
+
+	// Без магазина в контексте обращения отклоняются
+	if _, err := repo.GetByID(t.Context(), "order-1"); !errors.Is(err, application.ErrTenantRequired) {
+		t.Errorf("expected ErrTenantRequired, got: %v", err)
+	}
+	if err := repo.Save(t.Context(), orderA); !errors.Is(err, application.ErrTenantRequired) {
+		t.Errorf("expected ErrTenantRequired on save, got: %v", err)
+	}
+	if application.ErrorCode(application.ErrTenantRequired) != application.ErrorCodeNoTenant {
+		t.Errorf("expected %s error code", application.ErrorCodeNoTenant)
+	}
+}
+
+// TestTenant_PaymentsUseTenantGateway проверяет, что оплата идёт через
+// шлюз своего магазина, а чужой заказ оплатить нельзя
+func TestTenant_PaymentsUseTenantGateway(t *testing.T) {
+	repo, fakes, payOrder := setupTenants(t)
+	saveTenantOrder(t, repo, "shop-a", "order-1", 100)
+	saveTenantOrder(t, repo, "shop-b", "order-2", 200)
+
+	if _, err := payOrder.Execute(application.WithTenant(t.Context(), "shop-a"), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(fakes["shop-a"].GetPayments()) != 1 || len(fakes["shop-b"].GetPayments()) != 0 {
+		t.Errorf("expected charge through shop-a gateway only")
+	}
+
+	// Заказ shop-b из контекста shop-a не находится и не оплачивается
+	if _, err := payOrder.Execute(application.WithTenant(t.Context(), "shop-a"), "order-2"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound for cross-tenant payment, got: %v", err)
+	}
+	if len(fakes["shop-b"].GetPayments()) != 0 {
+		t.Errorf("expected no charge through shop-b gateway")
+	}
+
+	// Магазин без настроенного шлюза не может принимать оплату
+	saveTenantOrder(t, repo, "shop-c", "order-3", 300)
+	_, err := payOrder.Execute(application.WithTenant(t.Context(), "shop-c"), "order-3")
+	if !errors.Is(err, application.ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got: %v", err)
+	}
+	order, _ := repo.GetByID(application.WithTenant(t.Context(), "shop-c"), "order-3")
+	if order.IsPaid() {
+		t.Error("expected order to stay unpaid")
+	}
+}
+
+// TestTenant_FileRepositoryPerTenant проверяет хранение заказов магазинов
+// в отдельных файлах
+func TestTenant_FileRepositoryPerTenant(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *infrastructure.TenantOrderRepository {
+		return infrastructure.NewTenantOrderRepository(func(tenantID string) (application.OrderRepository, error) {
+			return infrastructure.NewFileOrderRepository(filepath.Join(dir, tenantID+".json"))
+		})
+	}
+	saveTenantOrder(t, open(), "shop-a", "order-1", 100)
+
+	reopened := open()
+	if _, err := reopened.GetByID(application.WithTenant(t.Context(), "shop-a"), "order-1"); err != nil {
+		t.Errorf("expected order in shop-a file, got: %v", err)
+	}
+	if _, err := reopened.GetByID(application.WithTenant(t.Context(), "shop-b"), "order-1"); !errors.Is(err, application.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound in shop-b, got: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"lab7/application"
+	"lab7/domain"
+	"lab7/infrastructure"
+	"lab7/infrastructure/tracing"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupTracedEnvironment собирает use-case оплаты с трассирующими декораторами
+func setupTracedEnvironment(tracer *tracing.Tracer) (*infrastructure.InMemoryOrderRepository, *infrastructure.FakePaymentGateway, application.PayOrderExecutor) {
+	repo := infrastructure.NewInMemoryOrderRepository()
+	gateway := infrastructure.NewFakePaymentGateway()
+	useCase := application.NewPayOrderUseCase(
+		tracing.NewOrderRepository(repo, tracer),
+		tracing.NewPaymentGateway(gateway, tracer))
+	return repo, gateway, tracing.NewPayOrderUseCase(useCase, tracer)
+}
+
+// saveTracedOrder сохраняет заказ на 100.00 RUB в обход декораторов
+func saveTracedOrder(t *testing.T, repo *infrastructure.InMemoryOrderRepository, id string) {
+	t.Helper()
+	order := domain.NewOrder(id)
+	money, _ := domain.NewMoney(10000, "RUB")
+	line, _ := domain.NewOrderLine("product-1", money, 1)
+	order.AddLine(line)
+	repo.Save(t.Context(), order)
+}
+
+// spansByName индексирует спаны по имени
+func spansByName(spans []tracing.SpanData) map[string]tracing.SpanData {
+	byName := make(map[string]tracing.SpanData, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	return byName
+}
+
+// TestTracing_PaymentSpanTree проверяет дерево спанов оплаты
+func TestTracing_PaymentSpanTree(t *testing.T) {
+	recorder := tracing.NewInMemoryRecorder()
+	repo, _, useCase := setupTracedEnvironment(tracing.NewTracer(recorder))
+	saveTracedOrder(t, repo, "order-1")
+
+	if _, err := useCase.Execute(t.Context(), "order-1"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	spans := spansByName(recorder.Spans())
+	root, ok := spans["PayOrderUseCase.Execute"]
+	if !ok {
+		t.Fatalf("expected Execute span, got %v", spans)
+	}
+	if root.ParentSpanID != "" {
+		t.Errorf("expected Execute to be the root span, got parent %s", root.ParentSpanID)
+	}
+	if root.Attributes["payment.amount"] != int64(10000) || root.Attributes["payment.currency"] != "RUB" {
+		t.Errorf("unexpected Execute attributes: %v", root.Attributes)
+	}
+
+	for _, name := range []string{"OrderRepository.GetByID", "PaymentGateway.Charge", "OrderRepository.Save"} {
+		child, ok := spans[name]
+		if !ok {
+			t.Fatalf("expected %s span", name)
+		}
+		if child.TraceID != root.TraceID || child.ParentSpanID != root.SpanID {
+			t.Errorf("%s: expected child of %s/%s, got %s/%s", name, root.TraceID, root.SpanID, child.TraceID, child.ParentSpanID)
+		}
+		if child.Status != tracing.StatusOK {
+			t.Errorf("%s: expected ok status, got %s", name, child.Status)
+		}
+	}
+}
+
+// TestTracing_RecordsErrors проверяет запись ошибки в спаны
+func TestTracing_RecordsErrors(t *testing.T) {
+	recorder := tracing.NewInMemoryRecorder()
+	repo, gateway, useCase := setupTracedEnvironment(tracing.NewTracer(recorder))
+	saveTracedOrder(t, repo, "order-1")
+	gateway.SetShouldFail(true, "insufficient funds")
+
+	useCase.Execute(t.Context(), "order-1")
+
+	spans := spansByName(recorder.Spans())
+	charge := spans["PaymentGateway.Charge"]
+	if charge.Status != tracing.StatusError || charge.Error != "insufficient funds" {
+		t.Errorf("expected failed Charge span, got %+v", charge)
+	}
+	root := spans["PayOrderUseCase.Execute"]
+	if root.Status != tracing.StatusError || root.Attributes["error.code"] != application.ErrorCodePaymentFailed {
+		t.Errorf("expected failed Execute span with payment_failed code, got %+v", root)
+	}
+	if _, saved := spans["OrderRepository.Save"]; saved {
+		t.Error("expected no Save span after failed charge")
+	}
+}
+
+// TestTracing_Traceparent проверяет разбор и форматирование заголовка traceparent
+func TestTracing_Traceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := tracing.ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("unexpected span context: %+v", sc)
+	}
+	if got := tracing.FormatTraceparent(sc); got != header {
+		t.Errorf("expected %s, got %s", header, got)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, value := range invalid {
+		if _, err := tracing.ParseTraceparent(value); !errors.Is(err, tracing.ErrInvalidTraceparent) {
+			t.Errorf("expected ErrInvalidTraceparent for %q, got %v", value, err)
+		}
+	}
+}
+
+// TestTracing_HTTPPropagation проверяет передачу трассы от клиента к серверу
+func TestTracing_HTTPPropagation(t *testing.T) {
+	recorder := tracing.NewInMemoryRecorder()
+	tracer := tracing.NewTracer(recorder)
+	repo, _, useCase := setupTracedEnvironment(tracer)
+	saveTracedOrder(t, repo, "order-1")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := useCase.Execute(r.Context(), r.URL.Query().Get("order")); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	server := httptest.NewServer(tracing.Middleware(tracer, handler))
+	defer server.Close()
+
+	client := &http.Client{Transport: &tracing.Transport{Tracer: tracer}}
+	resp, err := client.Get(server.URL + "/pay?order=order-1")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := spansByName(recorder.Spans())
+	clientSpan := spans["http.client GET"]
+	serverSpan := spans["http.server GET"]
+	execute := spans["PayOrderUseCase.Execute"]
+
+	if serverSpan.TraceID != clientSpan.TraceID || serverSpan.ParentSpanID != clientSpan.SpanID {
+		t.Errorf("server span is not a child of client span: %+v / %+v", serverSpan, clientSpan)
+	}
+	if execute.TraceID != clientSpan.TraceID || execute.ParentSpanID != serverSpan.SpanID {
+		t.Errorf("Execute span is not a child of server span: %+v", execute)
+	}
+	if serverSpan.Attributes["http.status_code"] != http.StatusOK {
+		t.Errorf("expected status 200, got %v", serverSpan.Attributes["http.status_code"])
+	}
+}
+
+// TestTracing_FileExporter проверяет запись спанов в файл JSON Lines
+func TestTracing_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	exporter, file, err := tracing.NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	repo, _, useCase := setupTracedEnvironment(tracing.NewTracer(exporter))
+	saveTracedOrder(t, repo, "order-1")
+	useCase.Execute(t.Context(), "order-1")
+	file.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span tracing.SpanData
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("invalid span line %q: %v", scanner.Text(), err)
+		}
+		names = append(names, span.Name)
+	}
+	expected := []string{"OrderRepository.GetByID", "PaymentGateway.Charge", "OrderRepository.Save", "PayOrderUseCase.Execute"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected spans %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected spans %v, got %v", expected, names)
+			break
+		}
+	}
+}